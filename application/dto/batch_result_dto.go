@@ -0,0 +1,12 @@
+package dto
+
+import "github.com/Twsouza/job-rule-engine/domain"
+
+// BatchResultDto is the outcome of the job request at Index in a batch.
+type BatchResultDto struct {
+	Index int `json:"index"`
+	// ID identifies the stored request, empty when the request is invalid.
+	ID      string             `json:"id,omitempty"`
+	Results []domain.JobResult `json:"results"`
+	Errors  []string           `json:"errors,omitempty"`
+}
@@ -4,4 +4,5 @@ type JobRequestDto struct {
 	DepartmentID int64   `json:"departmentId"`
 	JobItemID    int64   `json:"jobItemId"`
 	LocationsID  []int64 `json:"locationsId"`
+	Strategy     string  `json:"strategy,omitempty"`
 }
@@ -0,0 +1,57 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/Twsouza/job-rule-engine/domain/services"
+	"github.com/gin-gonic/gin"
+)
+
+// CacheStats returns the stats of each kind of entity in the cache of the Optii API.
+func (jh *JobRuleEngineHandler) CacheStats(c *gin.Context) {
+	if jh.Cache == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "cache is disabled"})
+		return
+	}
+
+	c.JSON(http.StatusOK, jh.Cache.Stats())
+}
+
+// InvalidateCache removes entries from the cache of the Optii API.
+// Without query params every entry is removed, the kind query param limits it to one kind of entity,
+// and the id query param, which can be repeated, to some entities of that kind.
+func (jh *JobRuleEngineHandler) InvalidateCache(c *gin.Context) {
+	if jh.Cache == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "cache is disabled"})
+		return
+	}
+
+	kind := c.Query("kind")
+	ids := []int64{}
+	for _, value := range c.QueryArray("id") {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "id must be a number"})
+			return
+		}
+		ids = append(ids, id)
+	}
+	if kind == "" && len(ids) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "kind is required with id"})
+		return
+	}
+
+	err := jh.Cache.Invalidate(kind, ids...)
+	if errors.Is(err, services.ErrUnknownCacheKind) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
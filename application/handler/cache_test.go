@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/services"
+	"github.com/Twsouza/job-rule-engine/domain/services/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCacheAdmin(t *testing.T) {
+	t.Run("should return the cache stats", func(t *testing.T) {
+		router := gin.Default()
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		handler.Cache = &mock.CacheMock{
+			StatsFunc: func() []domain.CacheStats {
+				return []domain.CacheStats{{Kind: "departments", TTL: "1m0s", Entries: 2, Hits: 3, Misses: 2}}
+			},
+		}
+		router.GET("/v1/admin/cache", handler.CacheStats)
+
+		req, err := http.NewRequest("GET", "/v1/admin/cache", nil)
+		assert.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, `[{"kind":"departments","ttl":"1m0s","entries":2,"hits":3,"misses":2,"evictions":0}]`, res.Body.String())
+	})
+
+	t.Run("should invalidate the entries of the kind", func(t *testing.T) {
+		router := gin.Default()
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		handler.Cache = &mock.CacheMock{
+			InvalidateFunc: func(kind string, ids ...int64) error {
+				assert.Equal(t, "locations", kind)
+				assert.Equal(t, []int64{1, 2}, ids)
+				return nil
+			},
+		}
+		router.DELETE("/v1/admin/cache", handler.InvalidateCache)
+
+		req, err := http.NewRequest("DELETE", "/v1/admin/cache?kind=locations&id=1&id=2", nil)
+		assert.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusNoContent, res.Code)
+	})
+
+	t.Run("should return status bad request for an unknown kind", func(t *testing.T) {
+		router := gin.Default()
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		handler.Cache = &mock.CacheMock{
+			InvalidateFunc: func(kind string, ids ...int64) error {
+				return fmt.Errorf("%w %q", services.ErrUnknownCacheKind, kind)
+			},
+		}
+		router.DELETE("/v1/admin/cache", handler.InvalidateCache)
+
+		req, err := http.NewRequest("DELETE", "/v1/admin/cache?kind=rooms", nil)
+		assert.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, `{"error":"unknown cache kind \"rooms\""}`, res.Body.String())
+	})
+
+	t.Run("should return status not implemented when the cache is disabled", func(t *testing.T) {
+		router := gin.Default()
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		router.GET("/v1/admin/cache", handler.CacheStats)
+
+		req, err := http.NewRequest("GET", "/v1/admin/cache", nil)
+		assert.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusNotImplemented, res.Code)
+	})
+}
@@ -0,0 +1,31 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// Healthz reports the process is alive, without checking its dependencies.
+func (jh *JobRuleEngineHandler) Healthz(c *gin.Context) {
+	c.JSON(http.StatusOK, gin.H{"status": "ok"})
+}
+
+// Readyz runs the readiness checks, e.g. whether Optii can be reached.
+// It returns 200 when every check is ready and 503 otherwise, with the result of each check.
+// Without Readiness the server is always ready.
+func (jh *JobRuleEngineHandler) Readyz(c *gin.Context) {
+	if jh.Readiness == nil {
+		c.JSON(http.StatusOK, &domain.Readiness{Ready: true, Checks: []domain.CheckResult{}})
+		return
+	}
+
+	readiness := jh.Readiness.Ready(c.Request.Context())
+	if !readiness.Ready {
+		c.JSON(http.StatusServiceUnavailable, readiness)
+		return
+	}
+
+	c.JSON(http.StatusOK, readiness)
+}
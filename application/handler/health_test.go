@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/services/mock"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHealth(t *testing.T) {
+	t.Run("should report the process is alive", func(t *testing.T) {
+		router := gin.Default()
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		router.GET("/healthz", handler.Healthz)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, `{"status":"ok"}`, res.Body.String())
+	})
+
+	t.Run("should return status service unavailable when a check is not ready", func(t *testing.T) {
+		router := gin.Default()
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		handler.Readiness = &mock.ReadinessMock{
+			ReadyFunc: func(ctx context.Context) *domain.Readiness {
+				return &domain.Readiness{Checks: []domain.CheckResult{{Name: "optii", Error: "connection refused", Duration: "1ms"}}}
+			},
+		}
+		router.GET("/readyz", handler.Readyz)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+		assert.Equal(t, `{"ready":false,"checks":[{"name":"optii","ready":false,"error":"connection refused","duration":"1ms"}]}`, res.Body.String())
+	})
+
+	t.Run("should return status ok when every check is ready", func(t *testing.T) {
+		router := gin.Default()
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		handler.Readiness = &mock.ReadinessMock{
+			ReadyFunc: func(ctx context.Context) *domain.Readiness {
+				return &domain.Readiness{Ready: true, Checks: []domain.CheckResult{{Name: "optii", Ready: true, Duration: "1ms"}}}
+			},
+		}
+		router.GET("/readyz", handler.Readyz)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+		assert.Equal(t, http.StatusOK, res.Code)
+	})
+}
@@ -1,56 +1,96 @@
 package handler
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"time"
 
 	"github.com/Twsouza/job-rule-engine/application/dto"
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/repository"
 	"github.com/Twsouza/job-rule-engine/domain/services"
+	"github.com/Twsouza/job-rule-engine/domain/tasks"
 	"github.com/gin-gonic/gin"
 )
 
 type JobRuleEngineHandler struct {
 	JobService services.JobServiceInterface
+	// Timeout is the deadline to load and execute a job request, zero means no deadline.
+	// The request is also cancelled when the client disconnects.
+	Timeout time.Duration
+	// Queue executes the job requests submitted with async=true, when nil async requests are disabled.
+	Queue services.JobQueueInterface
+	// Repository stores the executed job requests, when nil they are not stored.
+	Repository repository.RequestRepositoryInterface
+	// BatchConcurrency is the maximum number of job requests of a batch executed at once, at least one.
+	BatchConcurrency int
+	// Cache is the cache of the Optii API managed by the admin endpoints, when nil they are disabled.
+	Cache services.CacheInterface
+	// Logger logs the job requests which can't be executed, the default logger is used when nil.
+	Logger *slog.Logger
+	// Readiness runs the checks of GET /readyz, when nil the server is always ready.
+	Readiness services.ReadinessInterface
+	// ResultVersion is the version of the JSON of the job results, ResultVersion1 unless it's ResultVersion2.
+	ResultVersion int
 }
 
+// maxBatchSize is the maximum number of job requests in a batch.
+const maxBatchSize = 100
+
 func NewJobRuleEngineHandler(js services.JobServiceInterface) *JobRuleEngineHandler {
 	return &JobRuleEngineHandler{
-		JobService: js,
+		JobService:       js,
+		BatchConcurrency: 4,
 	}
 }
 
+// CreateJob executes the rules matching the job request and returns their results.
+// With async=true the job request is queued instead, and the response is 202 with the request ID
+// to poll with GetRequest. With dryRun=true the rules plan their jobs without creating them.
 func (jh *JobRuleEngineHandler) CreateJob(c *gin.Context) {
-	req := &dto.JobRequestDto{}
-	if err := c.ShouldBindJSON(req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	dryRun := c.Query("dryRun") == "true"
+	if c.Query("async") == "true" {
+		if dryRun {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "dryRun is not supported with async"})
+			return
+		}
+		jh.submitJob(c)
 		return
 	}
 
-	if req.DepartmentID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "department_id is required"})
+	req, ok := jh.bindJobRequest(c)
+	if !ok {
 		return
 	}
-	if req.JobItemID == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "job_item_id is required"})
+
+	id, err := services.NewRequestID()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
-	if len(req.LocationsID) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "locations_id is required"})
-		return
+
+	ctx, cancel := jh.requestContext(c)
+	defer cancel()
+	if dryRun {
+		ctx = tasks.WithDryRun(ctx)
 	}
 
-	jobReq, errs := jh.JobService.LoadJob(req)
+	record := repository.NewRequestRecord(id, req)
+	errs := services.ExecuteRequest(ctx, jh.JobService, record)
+	services.SaveRecord(jh.Repository, record)
+
 	if len(errs) > 0 {
-		errsStr := []string{}
-		for _, err := range errs {
-			errsStr = append(errsStr, err.Error())
-		}
-		c.JSON(http.StatusBadRequest, gin.H{"error": errsStr})
+		jh.writeLoadErrors(c, errs)
 		return
 	}
 
-	results := jh.JobService.CreateJob(jobReq)
+	results := record.Results
 	if len(results) == 0 {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "no rules matched for this job"})
+		jh.logger().InfoContext(ctx, services.ErrNoRulesMatched.Error(), "id", record.ID)
+		c.JSON(http.StatusBadRequest, gin.H{"error": services.ErrNoRulesMatched.Error()})
 		return
 	}
 
@@ -58,5 +98,260 @@ func (jh *JobRuleEngineHandler) CreateJob(c *gin.Context) {
 	// Each job result contains the job request, the result of the rule, and any errors that occurred.
 	// That's why we always return a 200 status code. To indicate that all rules were executed.
 	// The consumer of this API can then decide what to do with the results.
-	c.JSON(http.StatusOK, results)
+	c.JSON(http.StatusOK, jh.resultsView(results))
+}
+
+// submitJob validates the job request and queues it to be executed in the background.
+func (jh *JobRuleEngineHandler) submitJob(c *gin.Context) {
+	if jh.Queue == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "async requests are disabled"})
+		return
+	}
+
+	req, ok := jh.bindJobRequest(c)
+	if !ok {
+		return
+	}
+
+	ar, err := jh.Queue.Submit(c.Request.Context(), req)
+	if errors.Is(err, services.ErrQueueFull) || errors.Is(err, services.ErrQueueClosed) {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.Header("Location", "/v1/requests/"+ar.ID)
+	c.JSON(http.StatusAccepted, jh.asyncRequestView(ar))
+}
+
+// GetRequest returns the status of a job request submitted with async=true,
+// and its results once it's done.
+func (jh *JobRuleEngineHandler) GetRequest(c *gin.Context) {
+	if jh.Queue == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "async requests are disabled"})
+		return
+	}
+
+	ar, ok := jh.Queue.Get(c.Param("id"))
+	if !ok {
+		c.JSON(http.StatusNotFound, gin.H{"error": "request not found"})
+		return
+	}
+
+	c.JSON(http.StatusOK, jh.asyncRequestView(ar))
+}
+
+// ListRequests returns the stored job requests, the most recent first.
+// They can be filtered with the departmentId, jobItemId, from and to (RFC 3339 dates),
+// failed (true or false) and limit query params.
+func (jh *JobRuleEngineHandler) ListRequests(c *gin.Context) {
+	if jh.Repository == nil {
+		c.JSON(http.StatusNotImplemented, gin.H{"error": "requests storage is disabled"})
+		return
+	}
+
+	filter, err := parseRequestFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	records, err := jh.Repository.List(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, jh.recordsView(records))
+}
+
+// ExplainJob evaluates every rule for the job request without creating any job in Optii.
+// It returns the outcome of each rule and its conditions, and the jobs that would be created.
+func (jh *JobRuleEngineHandler) ExplainJob(c *gin.Context) {
+	ctx, cancel := jh.requestContext(c)
+	defer cancel()
+
+	jobReq, ok := jh.loadJobRequest(ctx, c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, jh.JobService.Explain(ctx, jobReq))
+}
+
+// BatchJobs executes an array of job requests, loading the departments, job items and locations
+// shared by several requests only once. Each result has the index of its job request in the array,
+// invalid requests and requests that fail are reported in their result without failing the others.
+func (jh *JobRuleEngineHandler) BatchJobs(c *gin.Context) {
+	reqs := []*dto.JobRequestDto{}
+	if err := c.ShouldBindJSON(&reqs); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if len(reqs) == 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "at least one job request is required"})
+		return
+	}
+	if len(reqs) > maxBatchSize {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("at most %d job requests are allowed", maxBatchSize)})
+		return
+	}
+
+	ctx, cancel := jh.requestContext(c)
+	defer cancel()
+
+	results := make([]dto.BatchResultDto, len(reqs))
+	records := []*repository.RequestRecord{}
+	indexes := []int{}
+	for i, req := range reqs {
+		results[i].Index = i
+		if req == nil {
+			results[i].Errors = []string{"job request is required"}
+			continue
+		}
+		if err := validateJobRequest(req); err != nil {
+			results[i].Errors = []string{err.Error()}
+			continue
+		}
+
+		id, err := services.NewRequestID()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+			return
+		}
+		records = append(records, repository.NewRequestRecord(id, req))
+		indexes = append(indexes, i)
+	}
+
+	if len(records) > 0 {
+		services.ExecuteBatch(ctx, jh.JobService, records, jh.BatchConcurrency)
+	}
+
+	for j, record := range records {
+		services.SaveRecord(jh.Repository, record)
+
+		result := &results[indexes[j]]
+		result.ID = record.ID
+		result.Results = record.Results
+		result.Errors = record.Errors
+	}
+
+	c.JSON(http.StatusOK, jh.batchView(results))
+}
+
+// JobAction handles the custom methods of the jobs collection, e.g. POST /jobs:explain.
+// The action param includes the leading colon.
+func (jh *JobRuleEngineHandler) JobAction(c *gin.Context) {
+	switch c.Param("action") {
+	case ":explain":
+		jh.ExplainJob(c)
+	case ":batch":
+		jh.BatchJobs(c)
+	default:
+		c.JSON(http.StatusNotFound, gin.H{"error": "unknown action"})
+	}
+}
+
+// logger returns the Logger of the handler, or the default logger.
+func (jh *JobRuleEngineHandler) logger() *slog.Logger {
+	if jh.Logger == nil {
+		return slog.Default()
+	}
+
+	return jh.Logger
+}
+
+// requestContext returns the context of the HTTP request with the handler Timeout, when set.
+func (jh *JobRuleEngineHandler) requestContext(c *gin.Context) (context.Context, context.CancelFunc) {
+	if jh.Timeout <= 0 {
+		return context.WithCancel(c.Request.Context())
+	}
+
+	return context.WithTimeout(c.Request.Context(), jh.Timeout)
+}
+
+// loadJobRequest validates the request body and loads the job request.
+// It writes the error response and returns false if the job request can't be loaded.
+func (jh *JobRuleEngineHandler) loadJobRequest(ctx context.Context, c *gin.Context) (*domain.JobRequest, bool) {
+	req, ok := jh.bindJobRequest(c)
+	if !ok {
+		return nil, false
+	}
+
+	jobReq, errs := jh.JobService.LoadJob(ctx, req)
+	if len(errs) > 0 {
+		jh.writeLoadErrors(c, errs)
+		return nil, false
+	}
+
+	return jobReq, true
+}
+
+// writeLoadErrors logs and writes the errors loading a job request, with the status of loadErrorStatus.
+func (jh *JobRuleEngineHandler) writeLoadErrors(c *gin.Context, errs []error) {
+	errsStr := []string{}
+	for _, err := range errs {
+		errsStr = append(errsStr, err.Error())
+	}
+	jh.logger().WarnContext(c.Request.Context(), "job request failed to load", "errors", errsStr)
+	c.JSON(loadErrorStatus(errs), gin.H{"error": errsStr})
+}
+
+// loadErrorStatus returns the status of the errors loading a job request, the failures of Optii first:
+//   - 502 when Optii failed or couldn't be reached.
+//   - 504 when the request timed out.
+//   - 404 when a department, job item or location doesn't exist.
+//   - 400 otherwise.
+func loadErrorStatus(errs []error) int {
+	status := http.StatusBadRequest
+	for _, err := range errs {
+		switch {
+		case errors.Is(err, services.ErrUpstream):
+			return http.StatusBadGateway
+		case errors.Is(err, context.DeadlineExceeded):
+			status = http.StatusGatewayTimeout
+		case errors.Is(err, services.ErrNotFound) && status == http.StatusBadRequest:
+			status = http.StatusNotFound
+		}
+	}
+
+	return status
+}
+
+// bindJobRequest binds and validates the request body.
+// It writes the error response and returns false if the request body is invalid.
+func (jh *JobRuleEngineHandler) bindJobRequest(c *gin.Context) (*dto.JobRequestDto, bool) {
+	req := &dto.JobRequestDto{}
+	if err := c.ShouldBindJSON(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	if err := validateJobRequest(req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return nil, false
+	}
+
+	return req, true
+}
+
+// validateJobRequest checks the required fields and the strategy of the job request.
+func validateJobRequest(req *dto.JobRequestDto) error {
+	if req.DepartmentID == 0 {
+		return errors.New("department_id is required")
+	}
+	if req.JobItemID == 0 {
+		return errors.New("job_item_id is required")
+	}
+	if len(req.LocationsID) == 0 {
+		return errors.New("locations_id is required")
+	}
+	if !domain.ExecutionStrategy(req.Strategy).IsValid() {
+		return errors.New("strategy must be one of allMatching, firstMatch or highestPriority")
+	}
+
+	return nil
 }
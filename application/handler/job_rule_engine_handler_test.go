@@ -1,14 +1,23 @@
 package handler
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/Twsouza/job-rule-engine/application/dto"
 	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/repository"
+	repositoryMock "github.com/Twsouza/job-rule-engine/domain/repository/mock"
+	"github.com/Twsouza/job-rule-engine/domain/services"
 	"github.com/Twsouza/job-rule-engine/domain/services/mock"
+	"github.com/Twsouza/job-rule-engine/domain/tasks"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 )
@@ -19,16 +28,17 @@ func TestCreateJob(t *testing.T) {
 		router := gin.Default()
 
 		mockJobService := &mock.JobServiceMock{}
-		mockJobService.CreateJobFunc = func(jobRequest *domain.JobRequest) []domain.JobResult {
+		mockJobService.CreateJobFunc = func(ctx context.Context, jobRequest *domain.JobRequest) []domain.JobResult {
 			return []domain.JobResult{
 				{
 					Request: jobRequest,
-					Result:  "success",
-					Err:     "",
+					Rule:    "RepairJobItemLocation",
+					Outcome: domain.OutcomeFailed,
+					Error:   &domain.JobError{Code: domain.ErrorRejected, Message: "rejected"},
 				},
 			}
 		}
-		mockJobService.LoadJobFunc = func(dto *dto.JobRequestDto) (*domain.JobRequest, []error) {
+		mockJobService.LoadJobFunc = func(ctx context.Context, dto *dto.JobRequestDto) (*domain.JobRequest, []error) {
 			jr := &domain.JobRequest{
 				Department: &domain.Department{
 					ID:   1,
@@ -75,7 +85,7 @@ func TestCreateJob(t *testing.T) {
 		assert.Equal(t, http.StatusOK, res.Code)
 
 		// Assert the response body
-		expectedBody := `[{"request":{"department":{"id":1,"name":"Engineering"},"jobItem":{"id":1,"displayName":"Item"},"locations":[{"id":1,"name":"Location","displayName":"Location 1"}]},"result":"success","error":""}]`
+		expectedBody := `[{"request":{"department":{"id":1,"name":"Engineering"},"jobItem":{"id":1,"displayName":"Item"},"locations":[{"id":1,"name":"Location","displayName":"Location 1"}]},"result":null,"error":"rejected"}]`
 		assert.Equal(t, expectedBody, res.Body.String())
 	})
 
@@ -84,10 +94,10 @@ func TestCreateJob(t *testing.T) {
 		router := gin.Default()
 
 		mockJobService := &mock.JobServiceMock{}
-		mockJobService.CreateJobFunc = func(jobRequest *domain.JobRequest) []domain.JobResult {
+		mockJobService.CreateJobFunc = func(ctx context.Context, jobRequest *domain.JobRequest) []domain.JobResult {
 			return []domain.JobResult{}
 		}
-		mockJobService.LoadJobFunc = func(dto *dto.JobRequestDto) (*domain.JobRequest, []error) {
+		mockJobService.LoadJobFunc = func(ctx context.Context, dto *dto.JobRequestDto) (*domain.JobRequest, []error) {
 			jr := &domain.JobRequest{
 				Department: &domain.Department{
 					ID:   1,
@@ -137,4 +147,684 @@ func TestCreateJob(t *testing.T) {
 		expectedBody := `{"error":"no rules matched for this job"}`
 		assert.Equal(t, expectedBody, res.Body.String())
 	})
+
+	t.Run("should return status bad request for an unknown strategy", func(t *testing.T) {
+		// Create a new Gin router
+		router := gin.Default()
+
+		mockJobService := &mock.JobServiceMock{}
+		mockJobService.LoadJobFunc = func(ctx context.Context, dto *dto.JobRequestDto) (*domain.JobRequest, []error) {
+			t.Error("LoadJob should not be called")
+			return nil, nil
+		}
+
+		// Create a new instance of JobRuleEngineHandler
+		handler := &JobRuleEngineHandler{
+			JobService: mockJobService,
+		}
+
+		// Define a test request body
+		reqBody := `{"departmentId": 1, "jobItemId": 1, "locationsId": [1], "strategy": "random"}`
+
+		// Create a new HTTP request with the test request body
+		req, err := http.NewRequest("POST", "/jobs", strings.NewReader(reqBody))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		// Create a new HTTP response recorder
+		res := httptest.NewRecorder()
+
+		// Set up the Gin router to handle the request
+		router.POST("/jobs", handler.CreateJob)
+
+		// Perform the request
+		router.ServeHTTP(res, req)
+
+		// Assert the response
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, `{"error":"strategy must be one of allMatching, firstMatch or highestPriority"}`, res.Body.String())
+	})
+}
+
+func TestJobResults(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	newService := func(dryRun *bool) *mock.JobServiceMock {
+		return &mock.JobServiceMock{
+			LoadJobFunc: func(ctx context.Context, dto *dto.JobRequestDto) (*domain.JobRequest, []error) {
+				return &domain.JobRequest{}, nil
+			},
+			MatchedRulesFunc: func(ctx context.Context, jobRequest *domain.JobRequest) []string {
+				return []string{"CleanBedsRoom"}
+			},
+			CreateJobFunc: func(ctx context.Context, jobRequest *domain.JobRequest) []domain.JobResult {
+				*dryRun = tasks.IsDryRun(ctx)
+				return []domain.JobResult{{
+					Rule:       "CleanBedsRoom",
+					Outcome:    domain.OutcomeFailed,
+					Action:     "clean",
+					Job:        &domain.Job{Action: "clean"},
+					Error:      &domain.JobError{Code: domain.ErrorUpstream, Message: "optii failure", Retryable: true},
+					StartedAt:  start,
+					FinishedAt: start.Add(time.Second),
+				}}
+			},
+		}
+	}
+	reqBody := `{"departmentId": 1, "jobItemId": 1, "locationsId": [1]}`
+
+	t.Run("should return the results of version 2", func(t *testing.T) {
+		router := gin.Default()
+
+		var dryRun bool
+		handler := NewJobRuleEngineHandler(newService(&dryRun))
+		handler.ResultVersion = ResultVersion2
+		router.POST("/v1/jobs", handler.CreateJob)
+
+		req, err := http.NewRequest("POST", "/v1/jobs", strings.NewReader(reqBody))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.False(t, dryRun)
+		assert.JSONEq(t, `[{
+			"request": null,
+			"rule": "CleanBedsRoom",
+			"outcome": "failed",
+			"action": "clean",
+			"job": {"item": {"name": ""}, "department": {"id": 0}, "location": null, "action": "clean"},
+			"error": {"code": "upstream", "message": "optii failure", "retryable": true},
+			"startedAt": "2024-05-01T10:00:00Z",
+			"finishedAt": "2024-05-01T10:00:01Z"
+		}]`, res.Body.String())
+	})
+
+	t.Run("should plan the jobs without creating them on a dry run", func(t *testing.T) {
+		router := gin.Default()
+
+		var dryRun bool
+		handler := NewJobRuleEngineHandler(newService(&dryRun))
+		router.POST("/v1/jobs", handler.CreateJob)
+
+		req, err := http.NewRequest("POST", "/v1/jobs?dryRun=true", strings.NewReader(reqBody))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.True(t, dryRun)
+	})
+
+	t.Run("should reject a dry run of an async request", func(t *testing.T) {
+		router := gin.Default()
+
+		var dryRun bool
+		handler := NewJobRuleEngineHandler(newService(&dryRun))
+		handler.Queue = &mock.JobQueueMock{}
+		router.POST("/v1/jobs", handler.CreateJob)
+
+		req, err := http.NewRequest("POST", "/v1/jobs?async=true&dryRun=true", strings.NewReader(reqBody))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, `{"error":"dryRun is not supported with async"}`, res.Body.String())
+	})
+
+	t.Run("should list the stored requests with the results of the version", func(t *testing.T) {
+		router := gin.Default()
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		handler.Repository = &repositoryMock.RequestRepositoryMock{
+			ListFunc: func(ctx context.Context, filter repository.RequestFilter) ([]repository.RequestRecord, error) {
+				return []repository.RequestRecord{{
+					ID:      "abc",
+					Results: []domain.JobResult{{Outcome: domain.OutcomeCreated, Created: &domain.JobCreated{ID: 7}}},
+				}}, nil
+			},
+		}
+		router.GET("/v1/requests", handler.ListRequests)
+
+		list := func() []map[string]any {
+			req, err := http.NewRequest("GET", "/v1/requests", nil)
+			assert.NoError(t, err)
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+			assert.Equal(t, http.StatusOK, res.Code)
+
+			records := []map[string]any{}
+			assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &records))
+			return records
+		}
+
+		result := list()[0]["results"].([]any)[0].(map[string]any)
+		assert.Equal(t, float64(7), result["result"].(map[string]any)["id"])
+		assert.Equal(t, "", result["error"])
+
+		handler.ResultVersion = ResultVersion2
+		result = list()[0]["results"].([]any)[0].(map[string]any)
+		assert.Equal(t, float64(7), result["created"].(map[string]any)["id"])
+		assert.Equal(t, "created", result["outcome"])
+	})
+}
+
+func TestLoadErrors(t *testing.T) {
+	for name, tc := range map[string]struct {
+		errs   []error
+		status int
+	}{
+		"should return status not found for an unknown ID": {
+			errs:   []error{fmt.Errorf("department %w", services.ErrNotFound)},
+			status: http.StatusNotFound,
+		},
+		"should return status bad gateway when Optii fails": {
+			errs:   []error{fmt.Errorf("department %w", services.ErrNotFound), fmt.Errorf("location %w", services.ErrUpstream)},
+			status: http.StatusBadGateway,
+		},
+		"should return status gateway timeout when the request times out": {
+			errs:   []error{fmt.Errorf("jobItem %w", context.DeadlineExceeded)},
+			status: http.StatusGatewayTimeout,
+		},
+		"should return status bad request for the other errors": {
+			errs:   []error{errors.New("department invalid: id must be positive")},
+			status: http.StatusBadRequest,
+		},
+	} {
+		tc := tc
+		t.Run(name, func(t *testing.T) {
+			router := gin.Default()
+
+			mockJobService := &mock.JobServiceMock{}
+			mockJobService.LoadJobFunc = func(ctx context.Context, dto *dto.JobRequestDto) (*domain.JobRequest, []error) {
+				return &domain.JobRequest{}, tc.errs
+			}
+
+			handler := NewJobRuleEngineHandler(mockJobService)
+			router.POST("/v1/jobs", handler.CreateJob)
+
+			req, err := http.NewRequest("POST", "/v1/jobs", strings.NewReader(`{"departmentId": 1, "jobItemId": 1, "locationsId": [1]}`))
+			assert.NoError(t, err)
+			req.Header.Set("Content-Type", "application/json")
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			assert.Equal(t, tc.status, res.Code)
+		})
+	}
+}
+
+func TestJobAction(t *testing.T) {
+	loadJob := func(ctx context.Context, dto *dto.JobRequestDto) (*domain.JobRequest, []error) {
+		return &domain.JobRequest{
+			Department: &domain.Department{ID: 1, Name: "Engineering"},
+		}, nil
+	}
+
+	t.Run("should return the explanation of the job request", func(t *testing.T) {
+		// Create a new Gin router
+		router := gin.Default()
+
+		mockJobService := &mock.JobServiceMock{}
+		mockJobService.LoadJobFunc = loadJob
+		mockJobService.CreateJobFunc = func(ctx context.Context, jobRequest *domain.JobRequest) []domain.JobResult {
+			t.Error("CreateJob should not be called")
+			return nil
+		}
+		mockJobService.ExplainFunc = func(ctx context.Context, jobRequest *domain.JobRequest) *domain.Explanation {
+			return &domain.Explanation{
+				Request:  jobRequest,
+				Strategy: domain.StrategyAllMatching,
+				Rules: []domain.RuleTrace{
+					{
+						Rule:       "RepairJobItemFloor",
+						Priority:   1,
+						Conditions: []domain.ConditionTrace{{Condition: `department is "Engineering"`, Passed: true}},
+					},
+				},
+			}
+		}
+
+		handler := NewJobRuleEngineHandler(mockJobService)
+		router.POST("/v1/jobs:action", handler.JobAction)
+
+		reqBody := `{"departmentId": 1, "jobItemId": 1, "locationsId": [1]}`
+		req, err := http.NewRequest("POST", "/v1/jobs:explain", strings.NewReader(reqBody))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		expectedBody := `{"request":{"department":{"id":1,"name":"Engineering"},"jobItem":null,"locations":null},"strategy":"allMatching","rules":[{"rule":"RepairJobItemFloor","priority":1,"exclusive":false,"matched":false,"selected":false,"conditions":[{"condition":"department is \"Engineering\"","passed":true}]}]}`
+		assert.Equal(t, expectedBody, res.Body.String())
+	})
+
+	t.Run("should return status not found for an unknown action", func(t *testing.T) {
+		router := gin.Default()
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		router.POST("/v1/jobs:action", handler.JobAction)
+
+		req, err := http.NewRequest("POST", "/v1/jobs:unknown", strings.NewReader(`{}`))
+		assert.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusNotFound, res.Code)
+		assert.Equal(t, `{"error":"unknown action"}`, res.Body.String())
+	})
+}
+
+func TestRequestContext(t *testing.T) {
+	loadJob := func(ctx context.Context, dto *dto.JobRequestDto) (*domain.JobRequest, []error) {
+		return &domain.JobRequest{
+			Department: &domain.Department{ID: 1, Name: "Engineering"},
+		}, nil
+	}
+	reqBody := `{"departmentId": 1, "jobItemId": 1, "locationsId": [1]}`
+
+	t.Run("should pass a context with the timeout to the service", func(t *testing.T) {
+		router := gin.Default()
+
+		var loadCtx, createCtx context.Context
+		mockJobService := &mock.JobServiceMock{}
+		mockJobService.LoadJobFunc = func(ctx context.Context, dto *dto.JobRequestDto) (*domain.JobRequest, []error) {
+			loadCtx = ctx
+			return loadJob(ctx, dto)
+		}
+		mockJobService.CreateJobFunc = func(ctx context.Context, jobRequest *domain.JobRequest) []domain.JobResult {
+			createCtx = ctx
+
+			deadline, ok := ctx.Deadline()
+			assert.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, 5*time.Second)
+
+			return []domain.JobResult{{Request: jobRequest}}
+		}
+
+		handler := NewJobRuleEngineHandler(mockJobService)
+		handler.Timeout = time.Minute
+		router.POST("/jobs", handler.CreateJob)
+
+		req, err := http.NewRequest("POST", "/jobs", strings.NewReader(reqBody))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Same(t, loadCtx, createCtx)
+		// The context is released once the response is written
+		assert.ErrorIs(t, createCtx.Err(), context.Canceled)
+	})
+
+	t.Run("should cancel the context when the timeout expires", func(t *testing.T) {
+		router := gin.Default()
+
+		mockJobService := &mock.JobServiceMock{}
+		mockJobService.LoadJobFunc = loadJob
+		mockJobService.ExplainFunc = func(ctx context.Context, jobRequest *domain.JobRequest) *domain.Explanation {
+			select {
+			case <-ctx.Done():
+			case <-time.After(5 * time.Second):
+				t.Error("the context should be done")
+			}
+			assert.ErrorIs(t, ctx.Err(), context.DeadlineExceeded)
+
+			return &domain.Explanation{Request: jobRequest}
+		}
+
+		handler := NewJobRuleEngineHandler(mockJobService)
+		handler.Timeout = 50 * time.Millisecond
+		router.POST("/v1/jobs:action", handler.JobAction)
+
+		req, err := http.NewRequest("POST", "/v1/jobs:explain", strings.NewReader(reqBody))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+	})
+
+	t.Run("should cancel the context when the client disconnects", func(t *testing.T) {
+		router := gin.Default()
+
+		cancelled := make(chan error, 1)
+		mockJobService := &mock.JobServiceMock{}
+		mockJobService.LoadJobFunc = loadJob
+		mockJobService.CreateJobFunc = func(ctx context.Context, jobRequest *domain.JobRequest) []domain.JobResult {
+			_, ok := ctx.Deadline()
+			assert.False(t, ok)
+
+			select {
+			case <-ctx.Done():
+				cancelled <- ctx.Err()
+			case <-time.After(5 * time.Second):
+				cancelled <- nil
+			}
+
+			return nil
+		}
+
+		handler := NewJobRuleEngineHandler(mockJobService)
+		router.POST("/jobs", handler.CreateJob)
+
+		server := httptest.NewServer(router)
+		defer server.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		req, err := http.NewRequestWithContext(ctx, "POST", server.URL+"/jobs", strings.NewReader(reqBody))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		time.AfterFunc(50*time.Millisecond, cancel)
+		_, err = http.DefaultClient.Do(req)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		assert.ErrorIs(t, <-cancelled, context.Canceled)
+	})
+}
+
+func TestAsyncRequests(t *testing.T) {
+	reqBody := `{"departmentId": 1, "jobItemId": 1, "locationsId": [1]}`
+
+	t.Run("should queue the job request when async is true", func(t *testing.T) {
+		router := gin.Default()
+
+		mockJobQueue := &mock.JobQueueMock{}
+		mockJobQueue.SubmitFunc = func(ctx context.Context, reqDto *dto.JobRequestDto) (*domain.AsyncRequest, error) {
+			assert.Equal(t, &dto.JobRequestDto{DepartmentID: 1, JobItemID: 1, LocationsID: []int64{1}}, reqDto)
+			return &domain.AsyncRequest{ID: "abc", Status: domain.RequestPending}, nil
+		}
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		handler.Queue = mockJobQueue
+		router.POST("/v1/jobs", handler.CreateJob)
+
+		req, err := http.NewRequest("POST", "/v1/jobs?async=true", strings.NewReader(reqBody))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusAccepted, res.Code)
+		assert.Equal(t, "/v1/requests/abc", res.Header().Get("Location"))
+		assert.Equal(t, `{"id":"abc","status":"pending","createdAt":"0001-01-01T00:00:00Z"}`, res.Body.String())
+	})
+
+	t.Run("should validate the job request before queueing it", func(t *testing.T) {
+		router := gin.Default()
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		handler.Queue = &mock.JobQueueMock{}
+		router.POST("/v1/jobs", handler.CreateJob)
+
+		req, err := http.NewRequest("POST", "/v1/jobs?async=true", strings.NewReader(`{"departmentId": 1}`))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, `{"error":"job_item_id is required"}`, res.Body.String())
+	})
+
+	t.Run("should return status service unavailable when the queue is full", func(t *testing.T) {
+		router := gin.Default()
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		handler.Queue = &mock.JobQueueMock{
+			SubmitFunc: func(ctx context.Context, reqDto *dto.JobRequestDto) (*domain.AsyncRequest, error) {
+				return nil, services.ErrQueueFull
+			},
+		}
+		router.POST("/v1/jobs", handler.CreateJob)
+
+		req, err := http.NewRequest("POST", "/v1/jobs?async=true", strings.NewReader(reqBody))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusServiceUnavailable, res.Code)
+	})
+
+	t.Run("should return status not implemented when async requests are disabled", func(t *testing.T) {
+		router := gin.Default()
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		router.POST("/v1/jobs", handler.CreateJob)
+
+		req, err := http.NewRequest("POST", "/v1/jobs?async=true", strings.NewReader(reqBody))
+		assert.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusNotImplemented, res.Code)
+	})
+
+	t.Run("should return the request", func(t *testing.T) {
+		router := gin.Default()
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		handler.Queue = &mock.JobQueueMock{
+			GetFunc: func(id string) (*domain.AsyncRequest, bool) {
+				if id != "abc" {
+					return nil, false
+				}
+				return &domain.AsyncRequest{
+					ID:      "abc",
+					Status:  domain.RequestDone,
+					Results: []domain.JobResult{{Outcome: domain.OutcomeFailed, Error: &domain.JobError{Code: domain.ErrorUpstream, Message: "optii failure"}}},
+				}, true
+			},
+		}
+		router.GET("/v1/requests/:id", handler.GetRequest)
+
+		req, err := http.NewRequest("GET", "/v1/requests/abc", nil)
+		assert.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Equal(t, `{"id":"abc","status":"done","results":[{"request":null,"result":null,"error":"optii failure"}],"createdAt":"0001-01-01T00:00:00Z"}`, res.Body.String())
+
+		req, err = http.NewRequest("GET", "/v1/requests/unknown", nil)
+		assert.NoError(t, err)
+
+		res = httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusNotFound, res.Code)
+		assert.Equal(t, `{"error":"request not found"}`, res.Body.String())
+	})
+}
+
+func TestStoredRequests(t *testing.T) {
+	t.Run("should store the executed job request", func(t *testing.T) {
+		router := gin.Default()
+
+		mockJobService := &mock.JobServiceMock{
+			LoadJobFunc: func(ctx context.Context, dto *dto.JobRequestDto) (*domain.JobRequest, []error) {
+				return &domain.JobRequest{Department: &domain.Department{ID: 1, Name: "Engineering"}}, nil
+			},
+			MatchedRulesFunc: func(ctx context.Context, jobRequest *domain.JobRequest) []string {
+				return []string{"RepairJobItemLocation"}
+			},
+			CreateJobFunc: func(ctx context.Context, jobRequest *domain.JobRequest) []domain.JobResult {
+				return []domain.JobResult{{Outcome: domain.OutcomeCreated, Created: &domain.JobCreated{ID: 1}}}
+			},
+		}
+
+		var saved *repository.RequestRecord
+		handler := NewJobRuleEngineHandler(mockJobService)
+		handler.Repository = &repositoryMock.RequestRepositoryMock{
+			SaveFunc: func(ctx context.Context, record *repository.RequestRecord) error {
+				saved = record
+				return nil
+			},
+		}
+		router.POST("/v1/jobs", handler.CreateJob)
+
+		req, err := http.NewRequest("POST", "/v1/jobs", strings.NewReader(`{"departmentId": 1, "jobItemId": 2, "locationsId": [3]}`))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.NotNil(t, saved)
+		assert.NotEmpty(t, saved.ID)
+		assert.Equal(t, &dto.JobRequestDto{DepartmentID: 1, JobItemID: 2, LocationsID: []int64{3}}, saved.Input)
+		assert.Equal(t, []string{"RepairJobItemLocation"}, saved.MatchedRules)
+		assert.Equal(t, []domain.JobResult{{Outcome: domain.OutcomeCreated, Created: &domain.JobCreated{ID: 1}}}, saved.Results)
+	})
+
+	t.Run("should list the stored requests with the filters", func(t *testing.T) {
+		router := gin.Default()
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		handler.Repository = &repositoryMock.RequestRepositoryMock{
+			ListFunc: func(ctx context.Context, filter repository.RequestFilter) ([]repository.RequestRecord, error) {
+				failed := true
+				assert.Equal(t, repository.RequestFilter{
+					DepartmentID: 1,
+					JobItemID:    2,
+					From:         time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+					Failed:       &failed,
+					Limit:        10,
+				}, filter)
+				return []repository.RequestRecord{{ID: "abc"}}, nil
+			},
+		}
+		router.GET("/v1/requests", handler.ListRequests)
+
+		req, err := http.NewRequest("GET", "/v1/requests?departmentId=1&jobItemId=2&from=2023-01-01T00:00:00Z&failed=true&limit=10", nil)
+		assert.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+		assert.Contains(t, res.Body.String(), `"id":"abc"`)
+	})
+
+	t.Run("should return status bad request for an invalid filter", func(t *testing.T) {
+		router := gin.Default()
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		handler.Repository = &repositoryMock.RequestRepositoryMock{}
+		router.GET("/v1/requests", handler.ListRequests)
+
+		req, err := http.NewRequest("GET", "/v1/requests?from=yesterday", nil)
+		assert.NoError(t, err)
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, `{"error":"from must be a RFC 3339 date"}`, res.Body.String())
+	})
+}
+
+func TestBatchJobs(t *testing.T) {
+	t.Run("should return the result of each job request with its index", func(t *testing.T) {
+		router := gin.Default()
+
+		mockJobService := &mock.JobServiceMock{
+			LoadJobsFunc: func(ctx context.Context, dtos []*dto.JobRequestDto) ([]*domain.JobRequest, [][]error) {
+				// The invalid request is not loaded
+				assert.Len(t, dtos, 2)
+				return []*domain.JobRequest{
+					{Department: &domain.Department{ID: 1}},
+					{},
+				}, [][]error{
+					nil,
+					{errors.New("jobItem not found")},
+				}
+			},
+			CreateJobFunc: func(ctx context.Context, jobRequest *domain.JobRequest) []domain.JobResult {
+				return []domain.JobResult{{Outcome: domain.OutcomeCreated, Created: &domain.JobCreated{ID: 1}}}
+			},
+		}
+
+		saved := 0
+		handler := NewJobRuleEngineHandler(mockJobService)
+		handler.Repository = &repositoryMock.RequestRepositoryMock{
+			SaveFunc: func(ctx context.Context, record *repository.RequestRecord) error {
+				saved++
+				return nil
+			},
+		}
+		router.POST("/v1/jobs:action", handler.JobAction)
+
+		reqBody := `[
+			{"departmentId": 1, "jobItemId": 1, "locationsId": [1]},
+			{"departmentId": 1},
+			{"departmentId": 1, "jobItemId": 99, "locationsId": [1]}
+		]`
+		req, err := http.NewRequest("POST", "/v1/jobs:batch", strings.NewReader(reqBody))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusOK, res.Code)
+
+		results := []dto.BatchResultDto{}
+		assert.NoError(t, json.Unmarshal(res.Body.Bytes(), &results))
+		assert.Len(t, results, 3)
+
+		assert.Equal(t, 0, results[0].Index)
+		assert.NotEmpty(t, results[0].ID)
+		assert.Equal(t, []domain.JobResult{{Outcome: domain.OutcomeCreated, Created: &domain.JobCreated{ID: 1}}}, results[0].Results)
+		assert.Empty(t, results[0].Errors)
+
+		assert.Equal(t, 1, results[1].Index)
+		assert.Empty(t, results[1].ID)
+		assert.Equal(t, []string{"job_item_id is required"}, results[1].Errors)
+
+		assert.Equal(t, 2, results[2].Index)
+		assert.NotEmpty(t, results[2].ID)
+		assert.Equal(t, []string{"jobItem not found"}, results[2].Errors)
+
+		assert.Equal(t, 2, saved)
+	})
+
+	t.Run("should return status bad request for an empty batch", func(t *testing.T) {
+		router := gin.Default()
+
+		handler := NewJobRuleEngineHandler(&mock.JobServiceMock{})
+		router.POST("/v1/jobs:action", handler.JobAction)
+
+		req, err := http.NewRequest("POST", "/v1/jobs:batch", strings.NewReader(`[]`))
+		assert.NoError(t, err)
+		req.Header.Set("Content-Type", "application/json")
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, http.StatusBadRequest, res.Code)
+		assert.Equal(t, `{"error":"at least one job request is required"}`, res.Body.String())
+	})
 }
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/domain/repository"
+	"github.com/gin-gonic/gin"
+)
+
+// defaultRequestsLimit is the number of requests listed when the limit query param is not set.
+const defaultRequestsLimit = 100
+
+// parseRequestFilter parses the query params of ListRequests.
+func parseRequestFilter(c *gin.Context) (repository.RequestFilter, error) {
+	filter := repository.RequestFilter{Limit: defaultRequestsLimit}
+
+	var err error
+	if value := c.Query("departmentId"); value != "" {
+		if filter.DepartmentID, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return filter, fmt.Errorf("departmentId must be a number")
+		}
+	}
+	if value := c.Query("jobItemId"); value != "" {
+		if filter.JobItemID, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return filter, fmt.Errorf("jobItemId must be a number")
+		}
+	}
+	if value := c.Query("from"); value != "" {
+		if filter.From, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, fmt.Errorf("from must be a RFC 3339 date")
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if filter.To, err = time.Parse(time.RFC3339, value); err != nil {
+			return filter, fmt.Errorf("to must be a RFC 3339 date")
+		}
+	}
+	if value := c.Query("failed"); value != "" {
+		failed, err := strconv.ParseBool(value)
+		if err != nil {
+			return filter, fmt.Errorf("failed must be true or false")
+		}
+		filter.Failed = &failed
+	}
+	if value := c.Query("limit"); value != "" {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 1 {
+			return filter, fmt.Errorf("limit must be a positive number")
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,119 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/application/dto"
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/repository"
+)
+
+// Versions of the JSON of the job results, see JobRuleEngineHandler.ResultVersion.
+const (
+	// ResultVersion1 has the job created, or the planned job of a dry run, in result and the error message in error.
+	ResultVersion1 = 1
+	// ResultVersion2 is the JSON of domain.JobResult, with its outcome, structured error and timing.
+	ResultVersion2 = 2
+)
+
+// The views of version 1 have the fields of the responses, in the same order, with the results of version 1.
+type (
+	batchResultV1 struct {
+		Index   int                  `json:"index"`
+		ID      string               `json:"id,omitempty"`
+		Results []domain.JobResultV1 `json:"results"`
+		Errors  []string             `json:"errors,omitempty"`
+	}
+
+	asyncRequestV1 struct {
+		ID         string               `json:"id"`
+		Status     domain.RequestStatus `json:"status"`
+		Results    []domain.JobResultV1 `json:"results,omitempty"`
+		Errors     []string             `json:"errors,omitempty"`
+		CreatedAt  time.Time            `json:"createdAt"`
+		FinishedAt *time.Time           `json:"finishedAt,omitempty"`
+	}
+
+	requestRecordV1 struct {
+		ID           string               `json:"id"`
+		Input        *dto.JobRequestDto   `json:"input"`
+		Request      *domain.JobRequest   `json:"request,omitempty"`
+		MatchedRules []string             `json:"matchedRules"`
+		Results      []domain.JobResultV1 `json:"results"`
+		Errors       []string             `json:"errors,omitempty"`
+		CreatedAt    time.Time            `json:"createdAt"`
+		FinishedAt   time.Time            `json:"finishedAt"`
+	}
+)
+
+// resultsV1 returns true if the results are written in the JSON of version 1.
+func (jh *JobRuleEngineHandler) resultsV1() bool {
+	return jh.ResultVersion != ResultVersion2
+}
+
+// resultsView returns the results in the JSON of the ResultVersion of the handler.
+func (jh *JobRuleEngineHandler) resultsView(results []domain.JobResult) any {
+	if !jh.resultsV1() {
+		return results
+	}
+
+	return domain.ResultsV1(results)
+}
+
+// batchView returns the results of a batch in the JSON of the ResultVersion of the handler.
+func (jh *JobRuleEngineHandler) batchView(results []dto.BatchResultDto) any {
+	if !jh.resultsV1() {
+		return results
+	}
+
+	views := make([]batchResultV1, len(results))
+	for i, result := range results {
+		views[i] = batchResultV1{
+			Index:   result.Index,
+			ID:      result.ID,
+			Results: domain.ResultsV1(result.Results),
+			Errors:  result.Errors,
+		}
+	}
+
+	return views
+}
+
+// asyncRequestView returns the async request in the JSON of the ResultVersion of the handler.
+func (jh *JobRuleEngineHandler) asyncRequestView(ar *domain.AsyncRequest) any {
+	if !jh.resultsV1() {
+		return ar
+	}
+
+	return asyncRequestV1{
+		ID:         ar.ID,
+		Status:     ar.Status,
+		Results:    domain.ResultsV1(ar.Results),
+		Errors:     ar.Errors,
+		CreatedAt:  ar.CreatedAt,
+		FinishedAt: ar.FinishedAt,
+	}
+}
+
+// recordsView returns the stored requests in the JSON of the ResultVersion of the handler.
+func (jh *JobRuleEngineHandler) recordsView(records []repository.RequestRecord) any {
+	if !jh.resultsV1() {
+		return records
+	}
+
+	views := make([]requestRecordV1, len(records))
+	for i, record := range records {
+		views[i] = requestRecordV1{
+			ID:           record.ID,
+			Input:        record.Input,
+			Request:      record.Request,
+			MatchedRules: record.MatchedRules,
+			Results:      domain.ResultsV1(record.Results),
+			Errors:       record.Errors,
+			CreatedAt:    record.CreatedAt,
+			FinishedAt:   record.FinishedAt,
+		}
+	}
+
+	return views
+}
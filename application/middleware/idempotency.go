@@ -0,0 +1,192 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// IdempotencyKeyHeader is the header identifying the retries of a request.
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader is set to true on the responses replayed from a previous request.
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// replayedHeaders are the response headers stored and replayed with the response body.
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+// Idempotency replays the response of the first request with the same Idempotency-Key header
+// for the identical retries received within the window.
+// Requests without the header are not affected.
+type Idempotency struct {
+	// Window is how long a response is replayed after the first request finished.
+	Window time.Duration
+
+	mu        sync.Mutex
+	responses map[string]*idempotentResponse
+}
+
+// idempotentResponse is the response of the first request with a key.
+// done is closed once the response is stored, the other fields must not be read before.
+type idempotentResponse struct {
+	fingerprint [sha256.Size]byte
+	done        chan struct{}
+	expiresAt   time.Time
+
+	status int
+	header http.Header
+	body   []byte
+}
+
+// NewIdempotency returns the middleware replaying the responses within the given window.
+func NewIdempotency(window time.Duration) *Idempotency {
+	return &Idempotency{
+		Window:    window,
+		responses: map[string]*idempotentResponse{},
+	}
+}
+
+// Handler returns the gin middleware.
+// A request with a key already used by a request with another method, path or body is rejected with 422.
+// A retry received while the first request is still executing waits for its response.
+func (i *Idempotency) Handler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		fingerprint := requestFingerprint(c.Request, body)
+
+		i.mu.Lock()
+		i.prune()
+		resp, ok := i.responses[key]
+		if !ok {
+			resp = &idempotentResponse{
+				fingerprint: fingerprint,
+				done:        make(chan struct{}),
+			}
+			i.responses[key] = resp
+		}
+		i.mu.Unlock()
+
+		if resp.fingerprint != fingerprint {
+			c.AbortWithStatusJSON(http.StatusUnprocessableEntity, gin.H{"error": "Idempotency-Key was already used with a different request"})
+			return
+		}
+
+		if ok {
+			select {
+			case <-resp.done:
+				resp.replay(c)
+			case <-c.Request.Context().Done():
+				c.AbortWithStatusJSON(http.StatusConflict, gin.H{"error": "a request with the same Idempotency-Key is in progress"})
+			}
+			return
+		}
+
+		i.execute(c, key, resp)
+	}
+}
+
+// execute runs the handlers and stores their response.
+func (i *Idempotency) execute(c *gin.Context, key string, resp *idempotentResponse) {
+	writer := &recordingWriter{ResponseWriter: c.Writer}
+	c.Writer = writer
+
+	defer func() {
+		resp.status = writer.Status()
+		resp.body = writer.body.Bytes()
+		resp.header = http.Header{}
+		for _, name := range replayedHeaders {
+			if value := writer.Header().Get(name); value != "" {
+				resp.header.Set(name, value)
+			}
+		}
+
+		i.mu.Lock()
+		resp.expiresAt = time.Now().Add(i.Window)
+		// Server errors are replayed to the waiting retries only, the next retries are executed again
+		if resp.status >= http.StatusInternalServerError {
+			delete(i.responses, key)
+		}
+		i.mu.Unlock()
+
+		close(resp.done)
+	}()
+
+	c.Next()
+}
+
+// prune removes the expired responses, the caller must hold the lock.
+func (i *Idempotency) prune() {
+	now := time.Now()
+	for key, resp := range i.responses {
+		if !resp.expiresAt.IsZero() && now.After(resp.expiresAt) {
+			delete(i.responses, key)
+		}
+	}
+}
+
+// replay writes the stored response.
+func (r *idempotentResponse) replay(c *gin.Context) {
+	for name, values := range r.header {
+		for _, value := range values {
+			c.Writer.Header().Add(name, value)
+		}
+	}
+	c.Writer.Header().Set(IdempotentReplayedHeader, "true")
+	c.Writer.WriteHeader(r.status)
+	c.Writer.Write(r.body)
+	c.Abort()
+}
+
+// requestFingerprint identifies a request by its method, path, query and body.
+// JSON bodies are compared regardless of their formatting and keys order.
+func requestFingerprint(r *http.Request, body []byte) [sha256.Size]byte {
+	var decoded interface{}
+	if err := json.Unmarshal(body, &decoded); err == nil {
+		if canonical, err := json.Marshal(decoded); err == nil {
+			body = canonical
+		}
+	}
+
+	h := sha256.New()
+	h.Write([]byte(r.Method + " " + r.URL.Path + "?" + r.URL.RawQuery + "\n"))
+	h.Write(body)
+
+	var fingerprint [sha256.Size]byte
+	copy(fingerprint[:], h.Sum(nil))
+
+	return fingerprint
+}
+
+// recordingWriter copies the response body while writing it.
+type recordingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *recordingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *recordingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIdempotency(t *testing.T) {
+	// setup returns a router counting the executions of the handler
+	setup := func(window time.Duration, status int, release chan struct{}) (*gin.Engine, *int32) {
+		router := gin.Default()
+		executions := new(int32)
+		router.POST("/jobs", NewIdempotency(window).Handler(), func(c *gin.Context) {
+			n := atomic.AddInt32(executions, 1)
+			if release != nil {
+				<-release
+			}
+			c.Header("Location", "/requests/1")
+			c.JSON(status, gin.H{"execution": n})
+		})
+
+		return router, executions
+	}
+
+	send := func(router *gin.Engine, key, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/jobs", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if key != "" {
+			req.Header.Set(IdempotencyKeyHeader, key)
+		}
+
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+		return res
+	}
+
+	t.Run("should replay the response of the first request", func(t *testing.T) {
+		router, executions := setup(time.Hour, http.StatusOK, nil)
+
+		first := send(router, "key", `{"departmentId": 1, "jobItemId": 2}`)
+		assert.Equal(t, http.StatusOK, first.Code)
+		assert.Empty(t, first.Header().Get(IdempotentReplayedHeader))
+
+		// The same payload with another formatting and keys order
+		retry := send(router, "key", `{"jobItemId":2,"departmentId":1}`)
+		assert.Equal(t, http.StatusOK, retry.Code)
+		assert.Equal(t, "true", retry.Header().Get(IdempotentReplayedHeader))
+		assert.Equal(t, "/requests/1", retry.Header().Get("Location"))
+		assert.Equal(t, first.Body.String(), retry.Body.String())
+		assert.Equal(t, int32(1), atomic.LoadInt32(executions))
+	})
+
+	t.Run("should reject a different request with the same key", func(t *testing.T) {
+		router, executions := setup(time.Hour, http.StatusOK, nil)
+
+		send(router, "key", `{"departmentId": 1}`)
+		res := send(router, "key", `{"departmentId": 2}`)
+		assert.Equal(t, http.StatusUnprocessableEntity, res.Code)
+		assert.Equal(t, int32(1), atomic.LoadInt32(executions))
+	})
+
+	t.Run("should execute the requests without key or with another key", func(t *testing.T) {
+		router, executions := setup(time.Hour, http.StatusOK, nil)
+
+		send(router, "", `{}`)
+		send(router, "", `{}`)
+		send(router, "key", `{}`)
+		send(router, "other", `{}`)
+		assert.Equal(t, int32(4), atomic.LoadInt32(executions))
+	})
+
+	t.Run("should execute the request again after the window", func(t *testing.T) {
+		router, executions := setup(time.Millisecond, http.StatusOK, nil)
+
+		send(router, "key", `{}`)
+		time.Sleep(5 * time.Millisecond)
+		res := send(router, "key", `{}`)
+		assert.Empty(t, res.Header().Get(IdempotentReplayedHeader))
+		assert.Equal(t, int32(2), atomic.LoadInt32(executions))
+	})
+
+	t.Run("should execute the request again after a server error", func(t *testing.T) {
+		router, executions := setup(time.Hour, http.StatusBadGateway, nil)
+
+		send(router, "key", `{}`)
+		send(router, "key", `{}`)
+		assert.Equal(t, int32(2), atomic.LoadInt32(executions))
+	})
+
+	t.Run("should wait for the request in progress", func(t *testing.T) {
+		release := make(chan struct{})
+		router, executions := setup(time.Hour, http.StatusOK, release)
+
+		responses := make([]*httptest.ResponseRecorder, 3)
+		wg := sync.WaitGroup{}
+		for i := range responses {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				responses[i] = send(router, "key", `{}`)
+			}(i)
+		}
+
+		assert.Eventually(t, func() bool {
+			return atomic.LoadInt32(executions) == 1
+		}, time.Second, 5*time.Millisecond)
+		time.Sleep(20 * time.Millisecond)
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(executions))
+		for _, res := range responses {
+			assert.Equal(t, http.StatusOK, res.Code)
+			assert.Equal(t, `{"execution":1}`, res.Body.String())
+		}
+	})
+}
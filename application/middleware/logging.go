@@ -0,0 +1,78 @@
+package middleware
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/domain/logging"
+	"github.com/Twsouza/job-rule-engine/domain/services"
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader is the header carrying the ID of a request, echoed back in the response.
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength is the maximum length of a request ID sent by a client, longer IDs are replaced.
+const maxRequestIDLength = 128
+
+// RequestID returns the gin middleware identifying every request with the X-Request-ID header,
+// or a generated ID when the header is missing or invalid. The ID is echoed back in the response
+// and added to the context of the request, so every line logged with it carries the request_id attribute.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			var err error
+			id, err = services.NewRequestID()
+			if err != nil {
+				c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithAttrs(c.Request.Context(), slog.String(logging.RequestIDKey, id)))
+
+		c.Next()
+	}
+}
+
+// validRequestID checks the ID is not empty, not too long and only has printable ASCII characters,
+// so it can't forge log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+
+	for i := 0; i < len(id); i++ {
+		if id[i] < ' ' || id[i] > '~' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// AccessLog returns the gin middleware logging every request once it's handled,
+// with its method, route, status and duration. It must be used after RequestID.
+func AccessLog(logger *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		c.Next()
+
+		level := slog.LevelInfo
+		if c.Writer.Status() >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "request handled",
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.String("route", c.FullPath()),
+			slog.Int("status", c.Writer.Status()),
+			slog.Duration("duration", time.Since(start)),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
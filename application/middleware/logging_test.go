@@ -0,0 +1,63 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain/logging"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRequestID(t *testing.T) {
+	out := &bytes.Buffer{}
+	logger := slog.New(logging.NewHandler(slog.NewJSONHandler(out, nil)))
+
+	router := gin.New()
+	router.Use(RequestID(), AccessLog(logger))
+	router.GET("/v1/requests/:id", func(c *gin.Context) {
+		logger.InfoContext(c.Request.Context(), "handler")
+		c.Status(http.StatusOK)
+	})
+
+	// lines returns the request IDs of the logged lines
+	lines := func() []string {
+		ids := []string{}
+		for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+			record := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal([]byte(line), &record))
+			ids = append(ids, record[logging.RequestIDKey].(string))
+		}
+		return ids
+	}
+
+	t.Run("should echo the request ID and log it", func(t *testing.T) {
+		out.Reset()
+		req := httptest.NewRequest(http.MethodGet, "/v1/requests/1", nil)
+		req.Header.Set(RequestIDHeader, "abc-123")
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		assert.Equal(t, "abc-123", res.Header().Get(RequestIDHeader))
+		assert.Equal(t, []string{"abc-123", "abc-123"}, lines())
+	})
+
+	t.Run("should generate an ID when the header is missing or invalid", func(t *testing.T) {
+		for _, header := range []string{"", "abc\ninjected", strings.Repeat("a", 129)} {
+			out.Reset()
+			req := httptest.NewRequest(http.MethodGet, "/v1/requests/1", nil)
+			req.Header.Set(RequestIDHeader, header)
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			id := res.Header().Get(RequestIDHeader)
+			assert.Len(t, id, 32)
+			assert.Equal(t, []string{id, id}, lines())
+		}
+	})
+}
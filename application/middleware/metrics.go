@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+)
+
+// RequestObserver records the HTTP requests received by the server.
+type RequestObserver interface {
+	ObserveHTTPRequest(method, route string, status int)
+}
+
+// RequestMetrics returns the gin middleware recording every request with the observer.
+// Requests are recorded by route, e.g. "/v1/requests/:id", so the IDs don't create new series,
+// and requests which don't match any route are recorded as "unmatched".
+func RequestMetrics(observer RequestObserver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+
+		observer.ObserveHTTPRequest(c.Request.Method, route, c.Writer.Status())
+	}
+}
@@ -0,0 +1,42 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+type requestObserverMock struct {
+	requests []string
+}
+
+func (m *requestObserverMock) ObserveHTTPRequest(method, route string, status int) {
+	m.requests = append(m.requests, method+" "+route+" "+http.StatusText(status))
+}
+
+func TestRequestMetrics(t *testing.T) {
+	t.Run("should record the requests by route", func(t *testing.T) {
+		observer := &requestObserverMock{}
+
+		router := gin.New()
+		router.Use(RequestMetrics(observer))
+		router.GET("/v1/requests/:id", func(c *gin.Context) {
+			c.Status(http.StatusNotFound)
+		})
+
+		for _, path := range []string{"/v1/requests/abc", "/v1/requests/def", "/missing"} {
+			req, err := http.NewRequest("GET", path, nil)
+			assert.NoError(t, err)
+			router.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		assert.Equal(t, []string{
+			"GET /v1/requests/:id Not Found",
+			"GET /v1/requests/:id Not Found",
+			"GET unmatched Not Found",
+		}, observer.requests)
+	})
+}
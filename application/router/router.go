@@ -1,29 +1,69 @@
 package router
 
 import (
+	"log/slog"
 	"time"
 
 	"github.com/Twsouza/job-rule-engine/application/handler"
+	"github.com/Twsouza/job-rule-engine/application/middleware"
+	"github.com/Twsouza/job-rule-engine/infrastructure/metrics"
+	"github.com/Twsouza/job-rule-engine/infrastructure/tracing"
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func SetupRouter(js *handler.JobRuleEngineHandler) *gin.Engine {
-	r := gin.Default()
+// SetupRouter creates the routes of the API.
+// When idempotency is not nil, job requests with an Idempotency-Key header are deduplicated.
+// When m is not nil, the requests are recorded and the metrics are served at GET /metrics.
+// Every request is logged with logger, or the default logger when it's nil.
+func SetupRouter(js *handler.JobRuleEngineHandler, idempotency *middleware.Idempotency, m *metrics.Metrics, logger *slog.Logger) *gin.Engine {
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	r := gin.New()
+	r.Use(gin.Recovery())
+
+	// The probes are registered before the other middlewares so they are not traced nor logged
+	r.GET("/healthz", js.Healthz)
+	r.GET("/readyz", js.Readyz)
+
+	// Traces every request, continuing the trace of the traceparent header when present
+	r.Use(otelgin.Middleware(tracing.ServiceName))
+	r.Use(middleware.RequestID(), middleware.AccessLog(logger))
 
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:  []string{"http://localhost:3000"},
-		AllowMethods:  []string{"POST"},
-		AllowHeaders:  []string{"Content-Type"},
-		ExposeHeaders: []string{"Content-Length"},
+		AllowMethods:  []string{"GET", "POST", "DELETE"},
+		AllowHeaders:  []string{"Content-Type", middleware.IdempotencyKeyHeader, middleware.RequestIDHeader},
+		ExposeHeaders: []string{"Content-Length", middleware.RequestIDHeader},
 		AllowOriginFunc: func(origin string) bool {
 			return origin == "http://localhost:3000"
 		},
 		MaxAge: 12 * time.Hour,
 	}))
 
+	if m != nil {
+		r.Use(middleware.RequestMetrics(m))
+		r.GET("/metrics", gin.WrapH(m.Handler()))
+	}
+
+	createJob := []gin.HandlerFunc{js.CreateJob}
+	jobAction := []gin.HandlerFunc{js.JobAction}
+	if idempotency != nil {
+		createJob = append([]gin.HandlerFunc{idempotency.Handler()}, createJob...)
+		jobAction = append([]gin.HandlerFunc{idempotency.Handler()}, jobAction...)
+	}
+
 	v1 := r.Group("/v1")
-	v1.POST("/jobs", js.CreateJob)
+	v1.POST("/jobs", createJob...)
+	// Custom methods, e.g. POST /v1/jobs:explain or POST /v1/jobs:batch
+	v1.POST("/jobs:action", jobAction...)
+	v1.GET("/requests", js.ListRequests)
+	v1.GET("/requests/:id", js.GetRequest)
+	v1.GET("/admin/cache", js.CacheStats)
+	v1.DELETE("/admin/cache", js.InvalidateCache)
 
 	return r
 }
@@ -0,0 +1,52 @@
+// Command fakeoptii serves a fake Optii API, to run the engine offline.
+//
+// Point the engine to it with:
+//
+//	OPTII_BASE_URL="http://localhost:8080"
+//	OPTII_AUTH_URL="http://localhost:8080/oauth/authorize"
+package main
+
+import (
+	"flag"
+	"log/slog"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/infrastructure/fakeoptii"
+	"github.com/gin-gonic/gin"
+)
+
+func main() {
+	addr := flag.String("addr", ":8080", "address to listen on")
+	fixturePath := flag.String("fixture", "", "JSON file with the departments, job items, locations and faults, the built-in fixture when empty")
+	clientID := flag.String("client-id", "", "client ID accepted by the token endpoint, any credentials are accepted when empty")
+	clientSecret := flag.String("client-secret", "", "client secret accepted by the token endpoint")
+	tokenTTL := flag.Duration("token-ttl", time.Hour, "lifetime of the access tokens")
+	latency := flag.Duration("latency", 0, "delay of every response")
+	flag.Parse()
+
+	fixture := fakeoptii.DefaultFixture()
+	if *fixturePath != "" {
+		var err error
+		fixture, err = fakeoptii.LoadFixture(*fixturePath)
+		if err != nil {
+			slog.Error("error loading the fixture", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	gin.SetMode(gin.ReleaseMode)
+	fake := fakeoptii.New(fixture)
+	fake.ClientID = *clientID
+	fake.ClientSecret = *clientSecret
+	fake.TokenTTL = *tokenTTL
+	fake.Latency = *latency
+
+	slog.Info("fake optii running", "addr", *addr, "departments", len(fixture.Departments),
+		"jobItems", len(fixture.JobItems), "locations", len(fixture.Locations), "faults", len(fixture.Faults))
+	if err := http.ListenAndServe(*addr, fake); err != nil {
+		slog.Error("fake optii stopped", "error", err)
+		os.Exit(1)
+	}
+}
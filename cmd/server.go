@@ -1,33 +1,130 @@
 package main
 
 import (
-	"fmt"
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/Twsouza/job-rule-engine/application/handler"
+	"github.com/Twsouza/job-rule-engine/application/middleware"
 	"github.com/Twsouza/job-rule-engine/application/router"
 	"github.com/Twsouza/job-rule-engine/domain/factories"
+	"github.com/Twsouza/job-rule-engine/infrastructure/cache"
+	"github.com/Twsouza/job-rule-engine/infrastructure/metrics"
+	"github.com/Twsouza/job-rule-engine/infrastructure/tracing"
 	"github.com/joho/godotenv"
 )
 
-var (
-	port string
-)
+var config factories.ServerConfig
 
 func init() {
 	godotenv.Load()
 
-	port = os.Getenv("PORT")
-	if port == "" {
-		port = "3000"
-	}
+	config = factories.NewServerConfig()
 }
 
 func main() {
-	js := factories.NewJobService()
+	logger := factories.NewLogger()
+	slog.SetDefault(logger)
+
+	shutdownTracing, err := tracing.Setup(context.Background(), os.Getenv("OTEL_TRACES_EXPORTER"))
+	if err != nil {
+		panic(err)
+	}
+	defer shutdownTracing(context.Background())
+
+	m := metrics.New()
+
+	optiiSdk := factories.NewOptiiAPI()
+	optiiSdk.Observer = m
+	optiiSdk.Logger = logger
+	var api cache.OptiiAPI = optiiSdk
+	index := factories.NewLocationIndex(api, optiiSdk)
+	if index != nil {
+		defer index.Close()
+		api = index
+	}
+
+	optiiCache := factories.NewOptiiCache(api)
+	if optiiCache != nil {
+		api = optiiCache
+	}
+
+	js := factories.NewJobService(api)
+	js.Metrics = m
+	js.Logger = logger
 	jrHandler := handler.NewJobRuleEngineHandler(js)
+	jrHandler.Logger = logger
+	jrHandler.Readiness = factories.NewReadiness(optiiSdk, index)
+	// Assigned only when set, a nil *OptiiCache would be a non-nil interface
+	if optiiCache != nil {
+		jrHandler.Cache = optiiCache
+	}
+	jrHandler.Timeout = config.RequestTimeout
+	jrHandler.ResultVersion = config.ResultVersion
+	if config.BatchConcurrency > 0 {
+		jrHandler.BatchConcurrency = config.BatchConcurrency
+	}
+	queue := factories.NewJobQueue(js)
+	jrHandler.Queue = queue
+
+	// Assigned only when set, a nil *BoltRequestRepository would be a non-nil interface
+	if repo := factories.NewRequestRepository(); repo != nil {
+		defer repo.Close()
+		jrHandler.Repository = repo
+		queue.Repository = repo
+	}
+
+	routes := router.SetupRouter(jrHandler, middleware.NewIdempotency(config.IdempotencyWindow), m, logger)
+
+	// The requests still running when the shutdown deadline expires are cancelled through their base context
+	requestsCtx, cancelRequests := context.WithCancel(context.Background())
+	defer cancelRequests()
+	server := &http.Server{
+		Addr:        ":" + config.Port,
+		Handler:     routes,
+		BaseContext: func(net.Listener) context.Context { return requestsCtx },
+	}
+
+	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("server running", "port", config.Port)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serverErr:
+		panic(err)
+	case <-signalCtx.Done():
+	}
+	// A second signal kills the process
+	stop()
+
+	logger.Info("shutting down", "timeout", config.ShutdownTimeout)
+	ctx, cancel := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancel()
+
+	// Stops accepting connections and waits for the requests being handled, and the rules they execute
+	if err := server.Shutdown(ctx); err != nil {
+		logger.Error("error draining the requests", "error", err)
+		cancelRequests()
+	}
+
+	// Executes the async requests already queued, then stops the workers. The queue has its own
+	// SHUTDOWN_TIMEOUT, the one of the server may have been used up. Close returns once every
+	// worker has exited, before the deferred close of the repository.
+	queueCtx, cancelQueue := context.WithTimeout(context.Background(), config.ShutdownTimeout)
+	defer cancelQueue()
+	if err := queue.Close(queueCtx); err != nil {
+		logger.Error("error draining the async requests", "error", err)
+	}
 
-	routes := router.SetupRouter(jrHandler)
-	fmt.Printf("Server running on port %s\n", port)
-	routes.Run(":" + port)
+	logger.Info("server stopped")
 }
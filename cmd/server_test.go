@@ -8,23 +8,40 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 
 	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/infrastructure/fakeoptii"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestMain(t *testing.T) {
 	port := "3000"
 
+	// The engine calls a fake Optii serving the default fixture
+	fake := fakeoptii.New(nil)
+	fake.ClientID = "e2e"
+	fake.ClientSecret = "secret"
+	optii := httptest.NewServer(fake)
+	defer optii.Close()
+
+	os.Setenv("PORT", port)
+	os.Setenv("OPTII_CLIENT_ID", fake.ClientID)
+	os.Setenv("OPTII_CLIENT_SECRET", fake.ClientSecret)
+	os.Setenv("OPTII_AUTH_URL", optii.URL+fakeoptii.AuthPath)
+	os.Setenv("OPTII_BASE_URL", optii.URL)
+	os.Setenv("OPTII_API_VERSION", "v1")
+
 	// Call the main function
 	go main()
 
 	// Wait for the server to start
 	time.Sleep(3 * time.Second)
 
-	// This payload is a valid one from the Optii API
+	// The beds of the rooms on floor 2 are cleaned
 	payload := `{
 		"departmentId": 13,
 		"jobItemId": 184,
@@ -39,9 +56,15 @@ func TestMain(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, http.StatusOK, res.StatusCode)
 
-	jobResult := []domain.JobResult{}
+	// The results are in the JSON of version 1 by default
+	jobResult := []domain.JobResultV1{}
 	err = json.NewDecoder(res.Body).Decode(&jobResult)
 	assert.NoError(t, err)
-	assert.NoError(t, err)
 	assert.Empty(t, jobResult[0].Err)
+
+	jobs := fake.Jobs()
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, "clean", jobs[0].Action)
+		assert.Equal(t, []domain.JLocation{{ID: 101}, {ID: 102}, {ID: 103}, {ID: 104}}, jobs[0].Locations)
+	}
 }
@@ -0,0 +1,14 @@
+package domain
+
+// CacheStats describes the entries of one kind of entity in the cache of the Optii API.
+type CacheStats struct {
+	Kind string `json:"kind"`
+	// TTL is how long the entries are kept, e.g. "10m0s", the kind is not cached when it's "0s".
+	TTL        string `json:"ttl"`
+	Entries    int    `json:"entries"`
+	MaxEntries int    `json:"maxEntries,omitempty"`
+	Hits       uint64 `json:"hits"`
+	Misses     uint64 `json:"misses"`
+	// Evictions counts the entries removed to respect MaxEntries.
+	Evictions uint64 `json:"evictions"`
+}
@@ -0,0 +1,13 @@
+package domain
+
+import "errors"
+
+var (
+	// ErrNotFound is matched, with errors.Is, by the errors of the Optii API for an entity which doesn't exist.
+	ErrNotFound = errors.New("not found")
+	// ErrUpstream is matched, with errors.Is, by the errors of the Optii API which failed on its side,
+	// rejected the credentials of the server or couldn't be reached.
+	ErrUpstream = errors.New("optii failure")
+	// ErrNoLocations is returned by the rules which found no locations for their job.
+	ErrNoLocations = errors.New("no locations found for this job")
+)
@@ -0,0 +1,29 @@
+package domain
+
+// Explanation describes how the rules were evaluated for a job request, without creating any job.
+type Explanation struct {
+	Request  *JobRequest       `json:"request"`
+	Strategy ExecutionStrategy `json:"strategy"`
+	Rules    []RuleTrace       `json:"rules"`
+}
+
+// RuleTrace is the evaluation of a single rule.
+type RuleTrace struct {
+	Rule      string `json:"rule"`
+	Priority  int    `json:"priority"`
+	Exclusive bool   `json:"exclusive"`
+	// Matched is true when every condition of the rule is satisfied.
+	Matched bool `json:"matched"`
+	// Selected is true when the rule would be executed according to the execution strategy.
+	Selected   bool             `json:"selected"`
+	Conditions []ConditionTrace `json:"conditions,omitempty"`
+	// Job is the job that would be sent to Optii by a selected rule.
+	Job *Job   `json:"job,omitempty"`
+	Err string `json:"error,omitempty"`
+}
+
+// ConditionTrace is the outcome of a single condition of a rule.
+type ConditionTrace struct {
+	Condition string `json:"condition"`
+	Passed    bool   `json:"passed"`
+}
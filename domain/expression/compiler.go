@@ -0,0 +1,215 @@
+package expression
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Type is the type of a value in an expression.
+type Type int
+
+const (
+	TypeBool Type = iota
+	TypeInt
+	TypeString
+	TypeLocations
+)
+
+func (t Type) String() string {
+	switch t {
+	case TypeBool:
+		return "bool"
+	case TypeInt:
+		return "int"
+	case TypeString:
+		return "string"
+	case TypeLocations:
+		return "locations"
+	}
+
+	return "unknown"
+}
+
+// compiled is a type checked node ready to be evaluated.
+type compiled struct {
+	typ  Type
+	eval func(e *env) interface{}
+}
+
+type compiler struct {
+	source string
+}
+
+// compile type checks the node and returns its evaluation function.
+// inLocation is true inside the predicate of any(), all() and count(), where the location fields are available.
+func (c *compiler) compile(n node, inLocation bool) (*compiled, error) {
+	switch n := n.(type) {
+	case *literalNode:
+		value := n.value
+		return &compiled{typ: n.typ, eval: func(*env) interface{} { return value }}, nil
+	case *pathNode:
+		return c.compilePath(n, inLocation)
+	case *unaryNode:
+		return c.compileUnary(n, inLocation)
+	case *binaryNode:
+		return c.compileBinary(n, inLocation)
+	case *callNode:
+		return c.compileCall(n, inLocation)
+	}
+
+	return nil, newError(c.source, n.position(), "unsupported expression")
+}
+
+// compilePath resolves a field, location fields take precedence over the job request fields.
+func (c *compiler) compilePath(n *pathNode, inLocation bool) (*compiled, error) {
+	name := strings.Join(n.parts, ".")
+
+	if inLocation {
+		if f, ok := locationFields[name]; ok {
+			return &compiled{typ: f.typ, eval: f.get}, nil
+		}
+	}
+	if f, ok := requestFields[name]; ok {
+		return &compiled{typ: f.typ, eval: f.get}, nil
+	}
+
+	if !inLocation {
+		if _, ok := locationFields[name]; ok {
+			return nil, newError(c.source, n.pos, "location field %s can only be used inside any(), all() or count()", name)
+		}
+	}
+
+	return nil, newError(c.source, n.pos, "unknown field %s", name)
+}
+
+func (c *compiler) compileUnary(n *unaryNode, inLocation bool) (*compiled, error) {
+	x, err := c.compileTyped(n.x, inLocation, TypeBool, "operand of !")
+	if err != nil {
+		return nil, err
+	}
+
+	return &compiled{typ: TypeBool, eval: func(e *env) interface{} {
+		return !x.eval(e).(bool)
+	}}, nil
+}
+
+func (c *compiler) compileBinary(n *binaryNode, inLocation bool) (*compiled, error) {
+	switch n.op {
+	case "&&", "||":
+		x, err := c.compileTyped(n.x, inLocation, TypeBool, "operand of "+n.op)
+		if err != nil {
+			return nil, err
+		}
+		y, err := c.compileTyped(n.y, inLocation, TypeBool, "operand of "+n.op)
+		if err != nil {
+			return nil, err
+		}
+
+		if n.op == "&&" {
+			return &compiled{typ: TypeBool, eval: func(e *env) interface{} {
+				return x.eval(e).(bool) && y.eval(e).(bool)
+			}}, nil
+		}
+		return &compiled{typ: TypeBool, eval: func(e *env) interface{} {
+			return x.eval(e).(bool) || y.eval(e).(bool)
+		}}, nil
+
+	case "=~", "!~":
+		return c.compileMatch(n, inLocation)
+	}
+
+	x, err := c.compile(n.x, inLocation)
+	if err != nil {
+		return nil, err
+	}
+	y, err := c.compile(n.y, inLocation)
+	if err != nil {
+		return nil, err
+	}
+	if x.typ != y.typ {
+		return nil, newError(c.source, n.pos, "mismatched types %s and %s for operator %s", x.typ, y.typ, n.op)
+	}
+
+	switch n.op {
+	case "==", "!=":
+		if x.typ == TypeLocations {
+			return nil, newError(c.source, n.pos, "operator %s is not defined on %s", n.op, x.typ)
+		}
+		equal := n.op == "=="
+		return &compiled{typ: TypeBool, eval: func(e *env) interface{} {
+			return (x.eval(e) == y.eval(e)) == equal
+		}}, nil
+	}
+
+	// Ordering operators: <, <=, > and >=
+	var compare func(e *env) int
+	switch x.typ {
+	case TypeInt:
+		compare = func(e *env) int {
+			a, b := x.eval(e).(int), y.eval(e).(int)
+			switch {
+			case a < b:
+				return -1
+			case a > b:
+				return 1
+			}
+			return 0
+		}
+	case TypeString:
+		compare = func(e *env) int {
+			return strings.Compare(x.eval(e).(string), y.eval(e).(string))
+		}
+	default:
+		return nil, newError(c.source, n.pos, "operator %s is not defined on %s", n.op, x.typ)
+	}
+
+	op := n.op
+	return &compiled{typ: TypeBool, eval: func(e *env) interface{} {
+		cmp := compare(e)
+		switch op {
+		case "<":
+			return cmp < 0
+		case "<=":
+			return cmp <= 0
+		case ">":
+			return cmp > 0
+		}
+		return cmp >= 0
+	}}, nil
+}
+
+// compileMatch compiles the regular expression operators, the pattern must be a string literal
+// so it's validated when the expression is compiled.
+func (c *compiler) compileMatch(n *binaryNode, inLocation bool) (*compiled, error) {
+	x, err := c.compileTyped(n.x, inLocation, TypeString, "left operand of "+n.op)
+	if err != nil {
+		return nil, err
+	}
+
+	lit, ok := n.y.(*literalNode)
+	if !ok || lit.typ != TypeString {
+		return nil, newError(c.source, n.y.position(), "right operand of %s must be a string literal", n.op)
+	}
+	re, err := regexp.Compile(lit.value.(string))
+	if err != nil {
+		return nil, newError(c.source, lit.pos, "invalid regular expression: %s", err)
+	}
+
+	match := n.op == "=~"
+	return &compiled{typ: TypeBool, eval: func(e *env) interface{} {
+		return re.MatchString(x.eval(e).(string)) == match
+	}}, nil
+}
+
+// compileTyped compiles the node and checks it has the expected type.
+func (c *compiler) compileTyped(n node, inLocation bool, expected Type, what string) (*compiled, error) {
+	x, err := c.compile(n, inLocation)
+	if err != nil {
+		return nil, err
+	}
+	if x.typ != expected {
+		return nil, newError(c.source, n.position(), "%s must be %s, got %s", what, expected, x.typ)
+	}
+
+	return x, nil
+}
@@ -0,0 +1,64 @@
+// Package expression implements a small typed expression language evaluated over a domain.JobRequest.
+//
+// An expression is a boolean condition such as:
+//
+//	Department.Name == "Housekeeping" && JobItem.DisplayName =~ "(?i)blanket|sheets"
+//	any(Locations, LocationType.DisplayName == "Floor") && count(Locations) == 1
+//	all(Locations, ParentLocation.ID == 12)
+//
+// The job request fields are Department.ID, Department.Name, JobItem.ID, JobItem.DisplayName and Locations.
+// Inside the predicate of any(), all() and count() the fields of the current location are also available:
+// ID, Name, DisplayName, LocationType.ID, LocationType.DisplayName, ParentLocation.ID,
+// ParentLocation.Name and ParentLocation.DisplayName.
+// Fields of a missing department, job item, location type or parent location evaluate to "" or 0.
+//
+// Expressions are type checked when compiled, errors report the line and column of the offending part.
+package expression
+
+import "github.com/Twsouza/job-rule-engine/domain"
+
+// Program is a compiled expression.
+type Program struct {
+	source string
+	eval   func(e *env) interface{}
+}
+
+// Compile parses and type checks the source, the expression must evaluate to a bool.
+// The returned error is an *Error with the position of the problem.
+func Compile(source string) (*Program, error) {
+	n, err := parse(source)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &compiler{source: source}
+	x, err := c.compile(n, false)
+	if err != nil {
+		return nil, err
+	}
+	if x.typ != TypeBool {
+		return nil, newError(source, n.position(), "expression must be bool, got %s", x.typ)
+	}
+
+	return &Program{source: source, eval: x.eval}, nil
+}
+
+// MustCompile is like Compile but panics if the expression can't be compiled.
+func MustCompile(source string) *Program {
+	p, err := Compile(source)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// Evaluate returns the result of the expression for the given job request.
+func (p *Program) Evaluate(jobRequest domain.JobRequest) bool {
+	return p.eval(&env{request: &jobRequest}).(bool)
+}
+
+// String returns the source of the expression.
+func (p *Program) String() string {
+	return p.source
+}
@@ -0,0 +1,140 @@
+package expression
+
+import (
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+	t.Run("should return the position of syntax errors", func(t *testing.T) {
+		tests := map[string]string{
+			``:                                      "1:1: empty expression",
+			`Department.Name ==`:                    "1:19: unexpected end of expression",
+			`Department.Name == "Housekeeping`:      "1:20: unterminated string literal",
+			`Department.Name = "Housekeeping"`:      "1:17: unexpected character '='",
+			`(Department.Name == "Housekeeping"`:    "1:35: expected ')', found end of expression",
+			`Department. == "Housekeeping"`:         "1:13: expected identifier, found operator ==",
+			`any(Locations ID == 1)`:                "1:15: expected ',' or ')', found identifier ID",
+			`JobItem.ID == 1 == true`:               "1:17: unexpected operator ==, comparisons can't be chained",
+			"Department.ID == 1 &&\n  JobItem.ID $": "2:14: unexpected character '$'",
+		}
+
+		for source, expected := range tests {
+			_, err := Compile(source)
+			assert.EqualError(t, err, expected, source)
+		}
+	})
+
+	t.Run("should return the position of type errors", func(t *testing.T) {
+		tests := map[string]string{
+			`Department.Name`:                        "1:1: expression must be bool, got string",
+			`Department.Title == "Housekeeping"`:     "1:1: unknown field Department.Title",
+			`LocationType.DisplayName == "Floor"`:    "1:1: location field LocationType.DisplayName can only be used inside any(), all() or count()",
+			`Department.ID == "1"`:                   "1:15: mismatched types int and string for operator ==",
+			`Department.Name && true`:                "1:1: operand of && must be bool, got string",
+			`!Department.ID`:                         "1:2: operand of ! must be bool, got int",
+			`Locations == Locations`:                 "1:11: operator == is not defined on locations",
+			`true < false`:                           "1:6: operator < is not defined on bool",
+			`JobItem.DisplayName =~ Department.Name`: "1:24: right operand of =~ must be a string literal",
+			`JobItem.DisplayName =~ "(?i)(blanket"`:  "1:24: invalid regular expression: error parsing regexp: missing closing ): `(?i)(blanket`",
+			`JobItem.ID =~ "1"`:                      "1:1: left operand of =~ must be string, got int",
+			`any(Locations)`:                         "1:1: any expects 2 argument(s), got 1",
+			`any(Department.Name, true)`:             "1:5: first argument of any must be locations, got string",
+			`all(Locations, ID)`:                     "1:16: predicate of all must be bool, got int",
+			`count(Locations, true, true) > 1`:       "1:1: count expects 2 argument(s), got 3",
+			`len(Department.ID) > 1`:                 "1:5: argument of len must be string or locations, got int",
+			`contains(Department.Name, 1)`:           "1:27: second argument of contains must be string, got int",
+			`lower(Department.Name, "a") == "a"`:     "1:1: lower expects 1 argument(s), got 2",
+			`size(Locations) == 1`:                   "1:1: unknown function size",
+		}
+
+		for source, expected := range tests {
+			_, err := Compile(source)
+			assert.EqualError(t, err, expected, source)
+		}
+	})
+
+	t.Run("should return an *Error with the position", func(t *testing.T) {
+		_, err := Compile("Department.ID == 1 &&\n  JobItem.Name == \"a\"")
+		if assert.IsType(t, &Error{}, err) {
+			assert.Equal(t, &Error{Offset: 24, Line: 2, Column: 3, Msg: "unknown field JobItem.Name"}, err)
+		}
+	})
+
+	t.Run("should panic on MustCompile with an invalid expression", func(t *testing.T) {
+		assert.Panics(t, func() { MustCompile("Department.Name") })
+		assert.Equal(t, "true", MustCompile("true").String())
+	})
+}
+
+func TestProgram_Evaluate(t *testing.T) {
+	jobRequest := domain.JobRequest{
+		Department: &domain.Department{ID: 1, Name: "Housekeeping"},
+		JobItem:    &domain.JobItem{ID: 2, DisplayName: "Clean Sheets"},
+		Locations: []domain.Location{
+			{
+				ID:             10,
+				Name:           "101",
+				DisplayName:    "Room 101",
+				LocationType:   &domain.LocationType{ID: 3, DisplayName: "Room"},
+				ParentLocation: &domain.ParentLocation{ID: 5, Name: "1", DisplayName: "Floor 1"},
+			},
+			{
+				ID:           5,
+				Name:         "1",
+				DisplayName:  "Floor 1",
+				LocationType: &domain.LocationType{ID: 4, DisplayName: "Floor"},
+			},
+		},
+	}
+
+	tests := map[string]bool{
+		`Department.Name == "Housekeeping" && JobItem.DisplayName =~ "(?i)blanket|sheets"`:           true,
+		`Department.Name == "Housekeeping" && JobItem.DisplayName =~ "(?i)blanket|towels"`:           false,
+		`JobItem.DisplayName !~ "(?i)towels"`:                                                        true,
+		`Department.ID == 1 && JobItem.ID != 1`:                                                      true,
+		`Department.ID < 2 && Department.ID <= 1 && Department.ID > 0 && Department.ID >= 1`:         true,
+		`Department.Name > "Engineering"`:                                                            true,
+		`Department.Name == "Engineering" || JobItem.ID == 2`:                                        true,
+		`!(Department.Name == "Engineering")`:                                                        true,
+		`any(Locations, LocationType.DisplayName == "Floor")`:                                        true,
+		`all(Locations, LocationType.DisplayName == "Room")`:                                         false,
+		`all(Locations, ID > 0)`:                                                                     true,
+		`count(Locations) == 2 && len(Locations) == 2`:                                               true,
+		`count(Locations, LocationType.DisplayName == "Room") == 1`:                                  true,
+		`any(Locations, ParentLocation.DisplayName == "Floor 1" && ParentLocation.ID == 5)`:          true,
+		`any(Locations, ParentLocation.Name == "2")`:                                                 false,
+		`any(Locations, Department.Name == "Housekeeping" && LocationType.ID == 4)`:                  true,
+		`any(Locations, any(Locations, ID == 10) && ID == 5)`:                                        true,
+		`contains(JobItem.DisplayName, "Sheet") && startsWith(JobItem.DisplayName, "Clean")`:         true,
+		`endsWith(lower(JobItem.DisplayName), "sheets") && upper(Department.Name) == "HOUSEKEEPING"`: true,
+		"len(JobItem.DisplayName) == 12 && `Clean Sheets` == JobItem.DisplayName":                    true,
+		`true && !false`: true,
+	}
+
+	for source, expected := range tests {
+		p, err := Compile(source)
+		if assert.NoError(t, err, source) {
+			assert.Equal(t, expected, p.Evaluate(jobRequest), source)
+		}
+	}
+
+	t.Run("should use zero values for missing fields", func(t *testing.T) {
+		empty := domain.JobRequest{
+			Locations: []domain.Location{{ID: 1}},
+		}
+
+		assert.True(t, MustCompile(`Department.Name == "" && Department.ID == 0 && JobItem.DisplayName == "" && JobItem.ID == 0`).Evaluate(empty))
+		assert.True(t, MustCompile(`all(Locations, LocationType.DisplayName == "" && ParentLocation.ID == 0)`).Evaluate(empty))
+	})
+
+	t.Run("should evaluate quantifiers over empty locations", func(t *testing.T) {
+		empty := domain.JobRequest{}
+
+		assert.False(t, MustCompile(`any(Locations, ID > 0)`).Evaluate(empty))
+		assert.True(t, MustCompile(`all(Locations, ID > 0)`).Evaluate(empty))
+		assert.True(t, MustCompile(`count(Locations, ID > 0) == 0`).Evaluate(empty))
+	})
+}
@@ -0,0 +1,91 @@
+package expression
+
+import "github.com/Twsouza/job-rule-engine/domain"
+
+// field is a value of the job request, or of a location inside any(), all() and count().
+type field struct {
+	typ Type
+	get func(e *env) interface{}
+}
+
+// requestFields are the fields of the job request.
+// Fields of a nil department or job item evaluate to their zero value.
+var requestFields = map[string]field{
+	"Department.ID": {TypeInt, func(e *env) interface{} {
+		if e.request.Department == nil {
+			return 0
+		}
+		return e.request.Department.ID
+	}},
+	"Department.Name": {TypeString, func(e *env) interface{} {
+		if e.request.Department == nil {
+			return ""
+		}
+		return e.request.Department.Name
+	}},
+	"JobItem.ID": {TypeInt, func(e *env) interface{} {
+		if e.request.JobItem == nil {
+			return 0
+		}
+		return e.request.JobItem.ID
+	}},
+	"JobItem.DisplayName": {TypeString, func(e *env) interface{} {
+		if e.request.JobItem == nil {
+			return ""
+		}
+		return e.request.JobItem.DisplayName
+	}},
+	"Locations": {TypeLocations, func(e *env) interface{} {
+		return e.request.Locations
+	}},
+}
+
+// locationFields are the fields of the current location inside any(), all() and count().
+// Fields of a nil location type or parent location evaluate to their zero value.
+var locationFields = map[string]field{
+	"ID": {TypeInt, func(e *env) interface{} {
+		return e.location.ID
+	}},
+	"Name": {TypeString, func(e *env) interface{} {
+		return e.location.Name
+	}},
+	"DisplayName": {TypeString, func(e *env) interface{} {
+		return e.location.DisplayName
+	}},
+	"LocationType.ID": {TypeInt, func(e *env) interface{} {
+		if e.location.LocationType == nil {
+			return 0
+		}
+		return e.location.LocationType.ID
+	}},
+	"LocationType.DisplayName": {TypeString, func(e *env) interface{} {
+		if e.location.LocationType == nil {
+			return ""
+		}
+		return e.location.LocationType.DisplayName
+	}},
+	"ParentLocation.ID": {TypeInt, func(e *env) interface{} {
+		if e.location.ParentLocation == nil {
+			return 0
+		}
+		return e.location.ParentLocation.ID
+	}},
+	"ParentLocation.Name": {TypeString, func(e *env) interface{} {
+		if e.location.ParentLocation == nil {
+			return ""
+		}
+		return e.location.ParentLocation.Name
+	}},
+	"ParentLocation.DisplayName": {TypeString, func(e *env) interface{} {
+		if e.location.ParentLocation == nil {
+			return ""
+		}
+		return e.location.ParentLocation.DisplayName
+	}},
+}
+
+// env holds the values available while evaluating an expression.
+type env struct {
+	request  *domain.JobRequest
+	location *domain.Location
+}
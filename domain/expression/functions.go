@@ -0,0 +1,152 @@
+package expression
+
+import (
+	"strings"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+// compileCall compiles the built-in functions:
+//   - any(Locations, predicate) and all(Locations, predicate) return a bool
+//   - count(Locations) and count(Locations, predicate) return an int
+//   - len(string) and len(Locations) return an int
+//   - contains, startsWith and endsWith take two strings and return a bool
+//   - lower and upper take a string and return a string
+func (c *compiler) compileCall(n *callNode, inLocation bool) (*compiled, error) {
+	switch n.name {
+	case "any", "all", "count":
+		return c.compileQuantifier(n, inLocation)
+
+	case "len":
+		if err := c.checkArgs(n, 1); err != nil {
+			return nil, err
+		}
+		x, err := c.compile(n.args[0], inLocation)
+		if err != nil {
+			return nil, err
+		}
+
+		switch x.typ {
+		case TypeString:
+			return &compiled{typ: TypeInt, eval: func(e *env) interface{} {
+				return len(x.eval(e).(string))
+			}}, nil
+		case TypeLocations:
+			return &compiled{typ: TypeInt, eval: func(e *env) interface{} {
+				return len(x.eval(e).([]domain.Location))
+			}}, nil
+		}
+		return nil, newError(c.source, n.args[0].position(), "argument of len must be string or locations, got %s", x.typ)
+
+	case "contains", "startsWith", "endsWith":
+		if err := c.checkArgs(n, 2); err != nil {
+			return nil, err
+		}
+		s, err := c.compileTyped(n.args[0], inLocation, TypeString, "first argument of "+n.name)
+		if err != nil {
+			return nil, err
+		}
+		sub, err := c.compileTyped(n.args[1], inLocation, TypeString, "second argument of "+n.name)
+		if err != nil {
+			return nil, err
+		}
+
+		fn := map[string]func(string, string) bool{
+			"contains":   strings.Contains,
+			"startsWith": strings.HasPrefix,
+			"endsWith":   strings.HasSuffix,
+		}[n.name]
+		return &compiled{typ: TypeBool, eval: func(e *env) interface{} {
+			return fn(s.eval(e).(string), sub.eval(e).(string))
+		}}, nil
+
+	case "lower", "upper":
+		if err := c.checkArgs(n, 1); err != nil {
+			return nil, err
+		}
+		s, err := c.compileTyped(n.args[0], inLocation, TypeString, "argument of "+n.name)
+		if err != nil {
+			return nil, err
+		}
+
+		fn := strings.ToLower
+		if n.name == "upper" {
+			fn = strings.ToUpper
+		}
+		return &compiled{typ: TypeString, eval: func(e *env) interface{} {
+			return fn(s.eval(e).(string))
+		}}, nil
+	}
+
+	return nil, newError(c.source, n.pos, "unknown function %s", n.name)
+}
+
+// compileQuantifier compiles any(), all() and count(), the predicate is evaluated for each location.
+func (c *compiler) compileQuantifier(n *callNode, inLocation bool) (*compiled, error) {
+	if n.name != "count" || len(n.args) != 1 {
+		if err := c.checkArgs(n, 2); err != nil {
+			return nil, err
+		}
+	}
+
+	list, err := c.compileTyped(n.args[0], inLocation, TypeLocations, "first argument of "+n.name)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(n.args) == 1 {
+		return &compiled{typ: TypeInt, eval: func(e *env) interface{} {
+			return len(list.eval(e).([]domain.Location))
+		}}, nil
+	}
+
+	pred, err := c.compileTyped(n.args[1], true, TypeBool, "predicate of "+n.name)
+	if err != nil {
+		return nil, err
+	}
+
+	// each evaluates the predicate for every location until stop returns true, it returns the number of matches
+	each := func(e *env, stop func(matched bool) bool) int {
+		matches := 0
+		locations := list.eval(e).([]domain.Location)
+		for i := range locations {
+			le := &env{request: e.request, location: &locations[i]}
+			matched := pred.eval(le).(bool)
+			if matched {
+				matches++
+			}
+			if stop(matched) {
+				break
+			}
+		}
+		return matches
+	}
+
+	switch n.name {
+	case "any":
+		return &compiled{typ: TypeBool, eval: func(e *env) interface{} {
+			return each(e, func(matched bool) bool { return matched }) > 0
+		}}, nil
+	case "all":
+		return &compiled{typ: TypeBool, eval: func(e *env) interface{} {
+			failed := false
+			each(e, func(matched bool) bool {
+				failed = !matched
+				return failed
+			})
+			return !failed
+		}}, nil
+	}
+
+	return &compiled{typ: TypeInt, eval: func(e *env) interface{} {
+		return each(e, func(bool) bool { return false })
+	}}, nil
+}
+
+func (c *compiler) checkArgs(n *callNode, expected int) error {
+	if len(n.args) != expected {
+		return newError(c.source, n.pos, "%s expects %d argument(s), got %d", n.name, expected, len(n.args))
+	}
+
+	return nil
+}
@@ -0,0 +1,180 @@
+package expression
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenInt
+	tokenString
+	tokenOperator
+	tokenLParen
+	tokenRParen
+	tokenComma
+	tokenDot
+)
+
+func (k tokenKind) String() string {
+	switch k {
+	case tokenEOF:
+		return "end of expression"
+	case tokenIdent:
+		return "identifier"
+	case tokenInt:
+		return "integer"
+	case tokenString:
+		return "string"
+	case tokenOperator:
+		return "operator"
+	case tokenLParen:
+		return "'('"
+	case tokenRParen:
+		return "')'"
+	case tokenComma:
+		return "','"
+	case tokenDot:
+		return "'.'"
+	}
+
+	return "unknown token"
+}
+
+type token struct {
+	kind tokenKind
+	// text is the source text of the token, or the unquoted value for strings
+	text string
+	pos  int
+}
+
+// operators are sorted so the longest operators are matched first.
+var operators = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "!~", "<", ">", "!"}
+
+// lex splits the source into tokens.
+func lex(source string) ([]token, error) {
+	var tokens []token
+
+	for i := 0; i < len(source); {
+		r, size := utf8.DecodeRuneInString(source[i:])
+
+		switch {
+		case unicode.IsSpace(r):
+			i += size
+
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLParen, text: "(", pos: i})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRParen, text: ")", pos: i})
+			i++
+		case r == ',':
+			tokens = append(tokens, token{kind: tokenComma, text: ",", pos: i})
+			i++
+		case r == '.':
+			tokens = append(tokens, token{kind: tokenDot, text: ".", pos: i})
+			i++
+
+		case r == '"' || r == '`':
+			end, err := scanString(source, i)
+			if err != nil {
+				return nil, err
+			}
+			value, err := strconv.Unquote(source[i:end])
+			if err != nil {
+				return nil, newError(source, i, "invalid string literal %s", source[i:end])
+			}
+			tokens = append(tokens, token{kind: tokenString, text: value, pos: i})
+			i = end
+
+		case r >= '0' && r <= '9':
+			start := i
+			for i < len(source) && source[i] >= '0' && source[i] <= '9' {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenInt, text: source[start:i], pos: start})
+
+		case r == '_' || unicode.IsLetter(r):
+			start := i
+			for i < len(source) {
+				r, size := utf8.DecodeRuneInString(source[i:])
+				if r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+					break
+				}
+				i += size
+			}
+			tokens = append(tokens, token{kind: tokenIdent, text: source[start:i], pos: start})
+
+		default:
+			op := ""
+			for _, o := range operators {
+				if strings.HasPrefix(source[i:], o) {
+					op = o
+					break
+				}
+			}
+			if op == "" {
+				return nil, newError(source, i, "unexpected character %q", r)
+			}
+			tokens = append(tokens, token{kind: tokenOperator, text: op, pos: i})
+			i += len(op)
+		}
+	}
+
+	tokens = append(tokens, token{kind: tokenEOF, pos: len(source)})
+
+	return tokens, nil
+}
+
+// scanString returns the end offset of the string literal starting at start.
+// Double quoted strings support escape sequences, back quoted strings are raw.
+func scanString(source string, start int) (int, error) {
+	quote := source[start]
+	for i := start + 1; i < len(source); i++ {
+		switch source[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case '\n':
+			if quote == '"' {
+				return 0, newError(source, start, "unterminated string literal")
+			}
+		case quote:
+			return i + 1, nil
+		}
+	}
+
+	return 0, newError(source, start, "unterminated string literal")
+}
+
+// Error is returned when an expression can't be compiled.
+// Line and Column are 1-based and point to the offending part of the expression.
+type Error struct {
+	Offset int
+	Line   int
+	Column int
+	Msg    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("%d:%d: %s", e.Line, e.Column, e.Msg)
+}
+
+func newError(source string, offset int, format string, args ...interface{}) *Error {
+	line := 1 + strings.Count(source[:offset], "\n")
+	lineStart := strings.LastIndex(source[:offset], "\n") + 1
+
+	return &Error{
+		Offset: offset,
+		Line:   line,
+		Column: utf8.RuneCountInString(source[lineStart:offset]) + 1,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
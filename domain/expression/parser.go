@@ -0,0 +1,240 @@
+package expression
+
+import "strconv"
+
+// node is an element of the parsed expression.
+type node interface {
+	position() int
+}
+
+type literalNode struct {
+	pos   int
+	typ   Type
+	value interface{}
+}
+
+type pathNode struct {
+	pos   int
+	parts []string
+}
+
+type unaryNode struct {
+	pos int
+	op  string
+	x   node
+}
+
+type binaryNode struct {
+	pos int
+	op  string
+	x   node
+	y   node
+}
+
+type callNode struct {
+	pos  int
+	name string
+	args []node
+}
+
+func (n *literalNode) position() int { return n.pos }
+func (n *pathNode) position() int    { return n.pos }
+func (n *unaryNode) position() int   { return n.pos }
+func (n *binaryNode) position() int  { return n.pos }
+func (n *callNode) position() int    { return n.pos }
+
+// precedence of the binary operators, higher binds tighter.
+var precedence = map[string]int{
+	"||": 1,
+	"&&": 2,
+	"==": 3, "!=": 3, "<": 3, "<=": 3, ">": 3, ">=": 3, "=~": 3, "!~": 3,
+}
+
+type parser struct {
+	source string
+	tokens []token
+	pos    int
+}
+
+// parse builds the syntax tree of the source.
+func parse(source string) (node, error) {
+	tokens, err := lex(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{source: source, tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, newError(source, 0, "empty expression")
+	}
+
+	n, err := p.parseBinary(1)
+	if err != nil {
+		return nil, err
+	}
+
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, p.unexpected(tok)
+	}
+
+	return n, nil
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+
+	return tok
+}
+
+func (p *parser) expect(kind tokenKind) (token, error) {
+	tok := p.next()
+	if tok.kind != kind {
+		return tok, newError(p.source, tok.pos, "expected %s, found %s", kind, describe(tok))
+	}
+
+	return tok, nil
+}
+
+func (p *parser) unexpected(tok token) error {
+	return newError(p.source, tok.pos, "unexpected %s", describe(tok))
+}
+
+// parseBinary parses binary operations with at least the given precedence.
+func (p *parser) parseBinary(minPrecedence int) (node, error) {
+	x, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		prec, ok := precedence[tok.text]
+		if tok.kind != tokenOperator || !ok || prec < minPrecedence {
+			return x, nil
+		}
+		p.next()
+
+		y, err := p.parseBinary(prec + 1)
+		if err != nil {
+			return nil, err
+		}
+
+		// Comparisons are not associative, "a == b == c" is rejected
+		if prec == precedence["=="] {
+			if next := p.peek(); next.kind == tokenOperator && precedence[next.text] == prec {
+				return nil, newError(p.source, next.pos, "unexpected %s, comparisons can't be chained", describe(next))
+			}
+		}
+
+		x = &binaryNode{pos: tok.pos, op: tok.text, x: x, y: y}
+	}
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if tok := p.peek(); tok.kind == tokenOperator && tok.text == "!" {
+		p.next()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return &unaryNode{pos: tok.pos, op: tok.text, x: x}, nil
+	}
+
+	return p.parsePrimary()
+}
+
+func (p *parser) parsePrimary() (node, error) {
+	tok := p.next()
+
+	switch tok.kind {
+	case tokenInt:
+		value, err := strconv.Atoi(tok.text)
+		if err != nil {
+			return nil, newError(p.source, tok.pos, "invalid integer %s", tok.text)
+		}
+		return &literalNode{pos: tok.pos, typ: TypeInt, value: value}, nil
+
+	case tokenString:
+		return &literalNode{pos: tok.pos, typ: TypeString, value: tok.text}, nil
+
+	case tokenLParen:
+		x, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := p.expect(tokenRParen); err != nil {
+			return nil, err
+		}
+		return x, nil
+
+	case tokenIdent:
+		switch tok.text {
+		case "true", "false":
+			return &literalNode{pos: tok.pos, typ: TypeBool, value: tok.text == "true"}, nil
+		}
+
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(tok)
+		}
+
+		path := &pathNode{pos: tok.pos, parts: []string{tok.text}}
+		for p.peek().kind == tokenDot {
+			p.next()
+			field, err := p.expect(tokenIdent)
+			if err != nil {
+				return nil, err
+			}
+			path.parts = append(path.parts, field.text)
+		}
+		return path, nil
+	}
+
+	return nil, p.unexpected(tok)
+}
+
+func (p *parser) parseCall(name token) (node, error) {
+	p.next() // (
+
+	call := &callNode{pos: name.pos, name: name.text}
+	if p.peek().kind == tokenRParen {
+		p.next()
+		return call, nil
+	}
+
+	for {
+		arg, err := p.parseBinary(1)
+		if err != nil {
+			return nil, err
+		}
+		call.args = append(call.args, arg)
+
+		tok := p.next()
+		if tok.kind == tokenRParen {
+			return call, nil
+		}
+		if tok.kind != tokenComma {
+			return nil, newError(p.source, tok.pos, "expected ',' or ')', found %s", describe(tok))
+		}
+	}
+}
+
+func describe(tok token) string {
+	switch tok.kind {
+	case tokenEOF:
+		return tok.kind.String()
+	case tokenString:
+		return "string " + strconv.Quote(tok.text)
+	case tokenIdent, tokenInt, tokenOperator:
+		return tok.kind.String() + " " + tok.text
+	}
+
+	return tok.kind.String()
+}
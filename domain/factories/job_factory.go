@@ -1,41 +1,367 @@
 package factories
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/logging"
 	"github.com/Twsouza/job-rule-engine/domain/services"
 	"github.com/Twsouza/job-rule-engine/domain/tasks"
-	hk "github.com/Twsouza/job-rule-engine/domain/tasks/housekeeping"
-	rs "github.com/Twsouza/job-rule-engine/domain/tasks/roomservice"
+	"github.com/Twsouza/job-rule-engine/domain/tasks/declarative"
+	"github.com/Twsouza/job-rule-engine/infrastructure/cache"
+	"github.com/Twsouza/job-rule-engine/infrastructure/cassette"
+	"github.com/Twsouza/job-rule-engine/infrastructure/pkg"
 	"github.com/Twsouza/job-rule-engine/infrastructure/sdk"
+	"github.com/Twsouza/job-rule-engine/infrastructure/storage"
+
+	// The task packages register their tasks when imported
+	_ "github.com/Twsouza/job-rule-engine/domain/tasks/engineering"
+	_ "github.com/Twsouza/job-rule-engine/domain/tasks/housekeeping"
+	_ "github.com/Twsouza/job-rule-engine/domain/tasks/roomservice"
 )
 
-func NewJobService() *services.JobService {
+// NewLogger creates the logger of the server, writing to stdout in the LOG_FORMAT format, json (default) or text,
+// the records of the LOG_LEVEL level or above: debug, info (default), warn or error.
+// The records logged with a context carry its logging attributes, e.g. the request ID.
+func NewLogger() *slog.Logger {
+	level := slog.LevelInfo
+	if value := os.Getenv("LOG_LEVEL"); value != "" {
+		if err := level.UnmarshalText([]byte(value)); err != nil {
+			panic(fmt.Errorf("invalid LOG_LEVEL: %w", err))
+		}
+	}
+
+	options := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch format := os.Getenv("LOG_FORMAT"); format {
+	case "", "json":
+		handler = slog.NewJSONHandler(os.Stdout, options)
+	case "text":
+		handler = slog.NewTextHandler(os.Stdout, options)
+	default:
+		panic(fmt.Errorf("unknown LOG_FORMAT %q", format))
+	}
+
+	return slog.New(logging.NewHandler(handler))
+}
+
+// NewOptiiAPI creates the client of the Optii API, authenticated with OPTII_CLIENT_ID and OPTII_CLIENT_SECRET.
+// The token is requested with the first call, so the server starts even when Optii can't be reached.
+func NewOptiiAPI() *sdk.OptiiSdk {
 	optiSdk, err := sdk.NewOptiiSdk(os.Getenv("OPTII_BASE_URL"), os.Getenv("OPTII_API_VERSION"), 3, nil)
 	if err != nil {
 		panic(err)
 	}
+	tokens := pkg.NewTokenSourceFromEnv()
+	optiSdk.Tokens = tokens
+
+	optiSdk.Timeout, err = durationEnv("OPTII_TIMEOUT")
+	if err != nil {
+		panic(err)
+	}
+
+	// The authentication calls go through the cassette too, so a replay doesn't reach Optii
+	if recorder := newCassette(); recorder != nil {
+		optiSdk.Transport = recorder
+		tokens.Client = &http.Client{Transport: recorder}
+	}
+
+	return optiSdk
+}
+
+// newCassette creates the recorder of the calls to Optii in the OPTII_CASSETTE file, in the OPTII_CASSETTE_MODE mode:
+// record, replay (default) or passthrough. It returns nil when OPTII_CASSETTE is not set.
+func newCassette() *cassette.Recorder {
+	path := os.Getenv("OPTII_CASSETTE")
+	if path == "" {
+		return nil
+	}
+
+	mode := cassette.ModeReplay
+	if value := os.Getenv("OPTII_CASSETTE_MODE"); value != "" {
+		var err error
+		mode, err = cassette.ParseMode(value)
+		if err != nil {
+			panic(fmt.Errorf("invalid OPTII_CASSETTE_MODE: %w", err))
+		}
+	}
+
+	recorder, err := cassette.New(path, mode)
+	if err != nil {
+		panic(fmt.Errorf("error loading the cassette: %w", err))
+	}
+	slog.Info("optii cassette", "path", path, "mode", mode)
+
+	return recorder
+}
+
+// NewLocationIndex decorates the Optii API with an index of the locations answering the floor lookups,
+// refreshed every LOCATION_INDEX_INTERVAL. It returns nil when LOCATION_INDEX_INTERVAL is not set.
+// The index is loaded before returning, if it fails the floor lookups are sent to the API until the next refresh.
+func NewLocationIndex(api cache.OptiiAPI, loader cache.LocationsLoader) *cache.LocationIndex {
+	interval, err := durationEnv("LOCATION_INDEX_INTERVAL")
+	if err != nil {
+		panic(err)
+	}
+	if interval == 0 {
+		return nil
+	}
+
+	index := cache.NewLocationIndex(api, loader)
+	if err := index.Refresh(context.Background()); err != nil {
+		slog.Error("error loading the location index", "error", err)
+	} else {
+		tree, _ := index.Tree()
+		slog.Info("location index loaded", "locations", tree.Len())
+	}
+	index.Start(interval)
+
+	return index
+}
+
+// NewReadiness creates the readiness checks of the server: the Optii token of the SDK can be obtained, without
+// authenticating again while it's valid, Optii answers a cheap request and, when index is not nil, the location
+// index is loaded.
+func NewReadiness(optii *sdk.OptiiSdk, index *cache.LocationIndex) *services.Readiness {
+	checks := []services.ReadinessCheck{
+		{Name: "optiiToken", Check: func(ctx context.Context) error {
+			_, err := optii.Tokens.Token(ctx)
+			return err
+		}},
+		{Name: "optii", Check: optii.Ping},
+	}
+
+	if index != nil {
+		checks = append(checks, services.ReadinessCheck{Name: "locationIndex", Check: func(ctx context.Context) error {
+			if tree, _ := index.Tree(); tree == nil {
+				return errors.New("location index not loaded")
+			}
+			return nil
+		}})
+	}
+
+	return services.NewReadiness(checks...)
+}
+
+// NewOptiiCache decorates the Optii API with a cache. CACHE_TTL is the TTL of every kind of entity,
+// and CACHE_DEPARTMENT_TTL, CACHE_JOB_ITEM_TTL, CACHE_LOCATION_TTL and CACHE_FLOOR_TTL override it.
+// The calls to the API shared by concurrent misses are bounded by OPTII_TIMEOUT, when set.
+// It returns nil when no TTL is set.
+func NewOptiiCache(api cache.OptiiAPI) *cache.OptiiCache {
+	ttl, err := durationEnv("CACHE_TTL")
+	if err != nil {
+		panic(err)
+	}
+
+	config := cache.Config{
+		DepartmentTTL: durationEnvOr("CACHE_DEPARTMENT_TTL", ttl),
+		JobItemTTL:    durationEnvOr("CACHE_JOB_ITEM_TTL", ttl),
+		LocationTTL:   durationEnvOr("CACHE_LOCATION_TTL", ttl),
+		FloorTTL:      durationEnvOr("CACHE_FLOOR_TTL", ttl),
+	}
+	if config.DepartmentTTL == 0 && config.JobItemTTL == 0 && config.LocationTTL == 0 && config.FloorTTL == 0 {
+		return nil
+	}
 
-	deliverJobItemLocation := rs.DeliverJobItemLocationTask{
-		API: optiSdk,
+	config.MaxEntries, err = intEnv("CACHE_MAX_ENTRIES", 1000)
+	if err != nil {
+		panic(err)
 	}
-	deliverJobItemRoom := rs.DeliverJobItemRoomTask{
-		API: optiSdk,
+	config.FetchTimeout, err = durationEnv("OPTII_TIMEOUT")
+	if err != nil {
+		panic(err)
 	}
-	cleanBedsRoom := hk.CleanBedsRoom{
-		API: optiSdk,
+
+	return cache.NewOptiiCache(api, config)
+}
+
+// NewJobService creates the service executing the rules with the given Optii API.
+func NewJobService(api cache.OptiiAPI) *services.JobService {
+	taskList := tasks.NewRegistered(api)
+
+	// Rules defined in the rules file are added after the built-in ones
+	if rulesFile := os.Getenv("RULES_FILE"); rulesFile != "" {
+		defs, err := declarative.LoadDefinitions(rulesFile)
+		if err != nil {
+			panic(err)
+		}
+
+		ruleTasks, err := declarative.CompileAll(defs, api)
+		if err != nil {
+			panic(err)
+		}
+		taskList = append(taskList, ruleTasks...)
 	}
-	cleanBedsFloor := hk.CleanBedsFloor{
-		API: optiSdk,
+
+	taskList, err := tasks.Select(taskList, splitList(os.Getenv("RULES_ENABLED")), splitList(os.Getenv("RULES_DISABLED")))
+	if err != nil {
+		panic(err)
 	}
-	taskList := []tasks.JobTask{
-		&deliverJobItemLocation,
-		&deliverJobItemRoom,
-		&cleanBedsRoom,
-		&cleanBedsFloor,
+
+	names := []string{}
+	for _, t := range taskList {
+		names = append(names, tasks.NameOf(t))
 	}
+	slog.Info("active rules", "rules", names)
+
+	js := services.NewJobService(taskList, api)
 
-	js := services.NewJobService(taskList, optiSdk)
+	js.Strategy = domain.ExecutionStrategy(os.Getenv("RULES_STRATEGY"))
+	if !js.Strategy.IsValid() {
+		panic(fmt.Errorf("unknown rules strategy %q", js.Strategy))
+	}
 
 	return js
 }
+
+// NewJobQueue creates the queue executing the async job requests with the given service.
+func NewJobQueue(js services.JobServiceInterface) *services.JobQueue {
+	workers, err := intEnv("ASYNC_WORKERS", 4)
+	if err != nil {
+		panic(err)
+	}
+
+	size, err := intEnv("ASYNC_QUEUE_SIZE", 100)
+	if err != nil {
+		panic(err)
+	}
+
+	q := services.NewJobQueue(js, workers, size)
+
+	q.Timeout, err = durationEnv("ASYNC_TIMEOUT")
+	if err != nil {
+		panic(err)
+	}
+
+	q.Retention, err = durationEnv("ASYNC_RETENTION")
+	if err != nil {
+		panic(err)
+	}
+	if q.Retention == 0 {
+		q.Retention = time.Hour
+	}
+
+	return q
+}
+
+// NewRequestRepository opens the database storing the job requests in the STORAGE_PATH file,
+// it returns nil when STORAGE_PATH is not set.
+func NewRequestRepository() *storage.BoltRequestRepository {
+	path := os.Getenv("STORAGE_PATH")
+	if path == "" {
+		return nil
+	}
+
+	repo, err := storage.NewBoltRequestRepository(path)
+	if err != nil {
+		panic(err)
+	}
+
+	return repo
+}
+
+// ServerConfig is the configuration of the HTTP server, see NewServerConfig.
+type ServerConfig struct {
+	Port           string
+	RequestTimeout time.Duration
+	// IdempotencyWindow is how long the responses of the requests with an Idempotency-Key are replayed
+	IdempotencyWindow time.Duration
+	// BatchConcurrency is the number of requests of a batch executed at the same time, zero means the default of the handler
+	BatchConcurrency int
+	// ResultVersion is the version of the JSON of the job results, 1 or 2
+	ResultVersion int
+	// ShutdownTimeout is how long the requests being handled, then the queued async requests, each have to finish on SIGTERM
+	ShutdownTimeout time.Duration
+}
+
+// NewServerConfig reads the configuration of the HTTP server: PORT (3000 by default), REQUEST_TIMEOUT,
+// IDEMPOTENCY_WINDOW (24h by default), BATCH_CONCURRENCY, JOB_RESULT_VERSION (1 by default)
+// and SHUTDOWN_TIMEOUT (30s by default).
+func NewServerConfig() ServerConfig {
+	config := ServerConfig{
+		Port:              os.Getenv("PORT"),
+		RequestTimeout:    durationEnvOr("REQUEST_TIMEOUT", 0),
+		IdempotencyWindow: durationEnvOr("IDEMPOTENCY_WINDOW", 24*time.Hour),
+		ShutdownTimeout:   durationEnvOr("SHUTDOWN_TIMEOUT", 30*time.Second),
+	}
+	if config.Port == "" {
+		config.Port = "3000"
+	}
+
+	var err error
+	config.BatchConcurrency, err = intEnv("BATCH_CONCURRENCY", 0)
+	if err != nil {
+		panic(err)
+	}
+
+	config.ResultVersion, err = intEnv("JOB_RESULT_VERSION", 1)
+	if err != nil || (config.ResultVersion != 1 && config.ResultVersion != 2) {
+		panic(fmt.Errorf("invalid JOB_RESULT_VERSION %q: must be 1 or 2", os.Getenv("JOB_RESULT_VERSION")))
+	}
+
+	return config
+}
+
+// splitList splits a comma separated list, ignoring blank items.
+func splitList(list string) []string {
+	items := []string{}
+	for _, item := range strings.Split(list, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// durationEnv parses the duration in the given environment variable, e.g. "30s", zero when it's not set.
+func durationEnv(key string) (time.Duration, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return d, nil
+}
+
+// durationEnvOr parses the duration in the given environment variable, or returns def when it's not set.
+func durationEnvOr(key string, def time.Duration) time.Duration {
+	if os.Getenv(key) == "" {
+		return def
+	}
+
+	ttl, err := durationEnv(key)
+	if err != nil {
+		panic(err)
+	}
+
+	return ttl
+}
+
+// intEnv parses the integer in the given environment variable, or returns def when it's not set.
+func intEnv(key string, def int) (int, error) {
+	value := os.Getenv(key)
+	if value == "" {
+		return def, nil
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+
+	return i, nil
+}
@@ -0,0 +1,17 @@
+package domain
+
+// CheckResult is the outcome of a readiness check.
+type CheckResult struct {
+	Name  string `json:"name"`
+	Ready bool   `json:"ready"`
+	// Error is the reason the dependency is not ready.
+	Error string `json:"error,omitempty"`
+	// Duration is how long the check took, e.g. "12ms".
+	Duration string `json:"duration"`
+}
+
+// Readiness is the outcome of the readiness checks, the server is ready when every check is.
+type Readiness struct {
+	Ready  bool          `json:"ready"`
+	Checks []CheckResult `json:"checks"`
+}
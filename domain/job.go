@@ -6,12 +6,8 @@ type JobRequest struct {
 	Department *Department `json:"department"`
 	JobItem    *JobItem    `json:"jobItem"`
 	Locations  []Location  `json:"locations"`
-}
-
-type JobResult struct {
-	Request *JobRequest `json:"request"`
-	Result  interface{} `json:"result"`
-	Err     string      `json:"error"`
+	// Strategy overrides the execution strategy of the service for this request.
+	Strategy ExecutionStrategy `json:"strategy,omitempty"`
 }
 
 type Job struct {
@@ -33,6 +29,7 @@ type JLocation struct {
 	ID int `json:"id"`
 }
 
+// JobCreated is a job of Optii, as returned when it's created, retrieved or changed.
 type JobCreated struct {
 	ID   int `json:"id"`
 	Item struct {
@@ -57,4 +54,43 @@ type JobCreated struct {
 	Notes    any       `json:"notes"`
 	Assignee any       `json:"assignee"`
 	DueBy    time.Time `json:"dueBy"`
+	Status   JobStatus `json:"status,omitempty"`
+}
+
+// JobStatus is the status of a job in Optii.
+type JobStatus string
+
+const (
+	// JobPending hasn't been done yet.
+	JobPending JobStatus = "pending"
+	// JobCancelled was cancelled, it can't be changed anymore.
+	JobCancelled JobStatus = "cancelled"
+	// JobCompleted was done, it can't be changed anymore.
+	JobCompleted JobStatus = "completed"
+)
+
+// JobFilter selects the jobs listed, its zero fields don't filter.
+type JobFilter struct {
+	Status       JobStatus
+	DepartmentID int
+	LocationID   int
+	Action       string
+}
+
+// JobUpdate is a change of a job, its nil fields are left unchanged.
+type JobUpdate struct {
+	Priority *string    `json:"priority,omitempty"`
+	Assignee *JAssignee `json:"assignee,omitempty"`
+	Notes    *string    `json:"notes,omitempty"`
+}
+
+// JAssignee is the user a job is assigned to.
+type JAssignee struct {
+	ID int `json:"id"`
+}
+
+// JobPage is a page of the jobs of Optii.
+type JobPage struct {
+	PageInfo *PageInfo    `json:"pageInfo"`
+	Jobs     []JobCreated `json:"items"`
 }
@@ -0,0 +1,185 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// JobOutcome is what a rule did with its job.
+type JobOutcome string
+
+const (
+	// OutcomeCreated created the job in Optii.
+	OutcomeCreated JobOutcome = "created"
+	// OutcomeSkipped had no job to create, e.g. no locations were found for it.
+	// It's a failure, as it was before the outcomes, see JobResult.Failed.
+	OutcomeSkipped JobOutcome = "skipped"
+	// OutcomeFailed couldn't create the job, see JobResult.Error.
+	OutcomeFailed JobOutcome = "failed"
+	// OutcomeDryRun planned the job without creating it.
+	OutcomeDryRun JobOutcome = "dry-run"
+)
+
+// JobErrorCode classifies the error of a rule.
+type JobErrorCode string
+
+const (
+	// ErrorNoLocations is the error of a rule which found no locations for its job.
+	ErrorNoLocations JobErrorCode = "no_locations"
+	// ErrorNoPlan is the error of a rule which can't plan its job on a dry run.
+	ErrorNoPlan JobErrorCode = "no_plan"
+	// ErrorNotFound is the error of a rule needing an entity of Optii which doesn't exist.
+	ErrorNotFound JobErrorCode = "not_found"
+	// ErrorUpstream is the error of a rule whose call to Optii failed on its side or couldn't reach it.
+	ErrorUpstream JobErrorCode = "upstream"
+	// ErrorRejected is the error of a rule whose job was rejected by Optii.
+	ErrorRejected JobErrorCode = "rejected"
+	// ErrorTimeout is the error of a rule which didn't finish before the deadline of the request.
+	ErrorTimeout JobErrorCode = "timeout"
+	// ErrorCancelled is the error of a rule whose request was cancelled.
+	ErrorCancelled JobErrorCode = "cancelled"
+	// ErrorUnknown is any other error, and the errors of the results stored before the codes.
+	ErrorUnknown JobErrorCode = "unknown"
+)
+
+// JobError is the error of a rule.
+type JobError struct {
+	Code    JobErrorCode `json:"code"`
+	Message string       `json:"message"`
+	// Retryable is true when the same request may succeed later.
+	Retryable bool `json:"retryable"`
+}
+
+func (e *JobError) Error() string {
+	return e.Message
+}
+
+// JobResult is the result of the execution of a rule.
+type JobResult struct {
+	Request *JobRequest `json:"request"`
+	// Rule is the name of the rule executed.
+	Rule    string     `json:"rule,omitempty"`
+	Outcome JobOutcome `json:"outcome"`
+	Action  string     `json:"action,omitempty"`
+	// Job is the job sent to Optii, or planned by a dry run.
+	Job *Job `json:"job,omitempty"`
+	// Created is the job created by Optii.
+	Created    *JobCreated `json:"created,omitempty"`
+	Error      *JobError   `json:"error,omitempty"`
+	StartedAt  time.Time   `json:"startedAt"`
+	FinishedAt time.Time   `json:"finishedAt"`
+}
+
+// Failed returns true if the rule failed or was skipped. The skipped rules are failures, as they were
+// before the outcomes, for the metrics and the failed filter of the stored requests.
+func (jr JobResult) Failed() bool {
+	return jr.Outcome == OutcomeFailed || jr.Outcome == OutcomeSkipped
+}
+
+// Duration returns how long the rule took.
+func (jr JobResult) Duration() time.Duration {
+	return jr.FinishedAt.Sub(jr.StartedAt)
+}
+
+// UnmarshalJSON decodes a result, or a result of version 1 as stored before the results were typed.
+func (jr *JobResult) UnmarshalJSON(data []byte) error {
+	// jobResult has the fields of JobResult without its methods, to decode them without recursion
+	type jobResult JobResult
+	v := struct {
+		*jobResult
+		Error  json.RawMessage `json:"error"`
+		Result json.RawMessage `json:"result"`
+	}{jobResult: (*jobResult)(jr)}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	var message string
+	switch {
+	case isNull(v.Error):
+	case json.Unmarshal(v.Error, &message) == nil:
+		// The error of version 1 is its message, empty when the rule succeeded
+		if message != "" {
+			jr.Error = &JobError{Code: ErrorUnknown, Message: message}
+		}
+		if message == ErrNoLocations.Error() {
+			jr.Error.Code = ErrorNoLocations
+			if jr.Outcome == "" {
+				jr.Outcome = OutcomeSkipped
+			}
+		}
+	default:
+		jr.Error = &JobError{}
+		if err := json.Unmarshal(v.Error, jr.Error); err != nil {
+			return err
+		}
+	}
+
+	if !isNull(v.Result) && jr.Created == nil {
+		// The result of version 1 is ignored when it's not a job
+		created := &JobCreated{}
+		if json.Unmarshal(v.Result, created) == nil {
+			jr.Created = created
+		}
+	}
+
+	jr.Normalize()
+
+	return nil
+}
+
+// Normalize sets the outcome of a result without one: failed when it has an error, created otherwise.
+// A failed result without an error gets an unknown error.
+func (jr *JobResult) Normalize() {
+	if jr.Outcome == "" {
+		jr.Outcome = OutcomeCreated
+		if jr.Error != nil {
+			jr.Outcome = OutcomeFailed
+		}
+	}
+	if jr.Failed() && jr.Error == nil {
+		jr.Error = &JobError{Code: ErrorUnknown, Message: "rule failed without an error"}
+	}
+}
+
+// JobResultV1 is a result in the JSON of version 1, see JobResult.V1.
+type JobResultV1 struct {
+	Request *JobRequest `json:"request"`
+	Result  interface{} `json:"result"`
+	Err     string      `json:"error"`
+}
+
+// V1 returns the result in the JSON of version 1: the job created, or the job planned by a dry run,
+// and the message of the error.
+func (jr JobResult) V1() JobResultV1 {
+	v1 := JobResultV1{Request: jr.Request}
+	switch {
+	case jr.Created != nil:
+		v1.Result = jr.Created
+	case jr.Outcome == OutcomeDryRun && jr.Job != nil:
+		v1.Result = jr.Job
+	}
+	if jr.Error != nil {
+		v1.Err = jr.Error.Message
+	}
+
+	return v1
+}
+
+// ResultsV1 returns the results in the JSON of version 1, see JobResult.V1.
+func ResultsV1(results []JobResult) []JobResultV1 {
+	if results == nil {
+		return nil
+	}
+
+	v1 := make([]JobResultV1, len(results))
+	for i, jr := range results {
+		v1[i] = jr.V1()
+	}
+
+	return v1
+}
+
+func isNull(data json.RawMessage) bool {
+	return len(data) == 0 || string(data) == "null"
+}
@@ -0,0 +1,107 @@
+package domain
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobResult_JSON(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("should decode the results it encodes", func(t *testing.T) {
+		jr := JobResult{
+			Request:    &JobRequest{Department: &Department{ID: 1}},
+			Rule:       "CleanBedsRoom",
+			Outcome:    OutcomeFailed,
+			Action:     "clean",
+			Job:        &Job{Action: "clean", Locations: []JLocation{{ID: 101}}},
+			Error:      &JobError{Code: ErrorUpstream, Message: "optii failure", Retryable: true},
+			StartedAt:  start,
+			FinishedAt: start.Add(time.Second),
+		}
+
+		data, err := json.Marshal(jr)
+		assert.NoError(t, err)
+		decoded := JobResult{}
+		assert.NoError(t, json.Unmarshal(data, &decoded))
+		assert.Equal(t, jr, decoded)
+		assert.Equal(t, time.Second, decoded.Duration())
+	})
+
+	t.Run("should decode the results of version 1", func(t *testing.T) {
+		results := []JobResult{}
+		err := json.Unmarshal([]byte(`[
+			{"request": null, "result": {"id": 7, "action": "clean"}, "error": ""},
+			{"request": null, "result": null, "error": "no locations found for this job"},
+			{"request": null, "result": "created", "error": ""},
+			{"request": null, "result": null, "error": "optii failure"}
+		]`), &results)
+		assert.NoError(t, err)
+
+		assert.Equal(t, OutcomeCreated, results[0].Outcome)
+		assert.Equal(t, 7, results[0].Created.ID)
+		assert.Nil(t, results[0].Error)
+
+		assert.True(t, results[1].Failed())
+		assert.Equal(t, OutcomeSkipped, results[1].Outcome)
+		assert.Equal(t, &JobError{Code: ErrorNoLocations, Message: "no locations found for this job"}, results[1].Error)
+
+		assert.Equal(t, OutcomeCreated, results[2].Outcome)
+		assert.Nil(t, results[2].Created)
+
+		assert.Equal(t, OutcomeFailed, results[3].Outcome)
+		assert.Equal(t, &JobError{Code: ErrorUnknown, Message: "optii failure"}, results[3].Error)
+	})
+}
+
+func TestJobResult_Normalize(t *testing.T) {
+	t.Run("should set the outcome from the error when it's missing", func(t *testing.T) {
+		jr := JobResult{}
+		jr.Normalize()
+		assert.Equal(t, OutcomeCreated, jr.Outcome)
+
+		jr = JobResult{Error: &JobError{Code: ErrorTimeout, Message: "timeout"}}
+		jr.Normalize()
+		assert.Equal(t, OutcomeFailed, jr.Outcome)
+	})
+
+	t.Run("should keep the outcome when it's set", func(t *testing.T) {
+		jr := JobResult{Outcome: OutcomeSkipped, Error: &JobError{Code: ErrorNoLocations, Message: "no locations found for this job"}}
+		jr.Normalize()
+		assert.Equal(t, OutcomeSkipped, jr.Outcome)
+	})
+
+	t.Run("should give an error to the failed results without one", func(t *testing.T) {
+		jr := JobResult{Outcome: OutcomeFailed}
+		jr.Normalize()
+		assert.Equal(t, ErrorUnknown, jr.Error.Code)
+	})
+}
+
+func TestJobResult_V1(t *testing.T) {
+	request := &JobRequest{Department: &Department{ID: 1}}
+	job := &Job{Action: "clean"}
+
+	t.Run("should have the job created in result", func(t *testing.T) {
+		created := &JobCreated{ID: 7}
+		v1 := JobResult{Request: request, Outcome: OutcomeCreated, Job: job, Created: created}.V1()
+		assert.Equal(t, JobResultV1{Request: request, Result: created}, v1)
+	})
+
+	t.Run("should have the message of the error in error", func(t *testing.T) {
+		v1 := JobResult{Request: request, Outcome: OutcomeFailed, Job: job, Error: &JobError{Code: ErrorRejected, Message: "rejected"}}.V1()
+		assert.Equal(t, JobResultV1{Request: request, Err: "rejected"}, v1)
+
+		data, err := json.Marshal(v1)
+		assert.NoError(t, err)
+		assert.JSONEq(t, `{"request": {"department": {"id": 1, "name": ""}, "jobItem": null, "locations": null}, "result": null, "error": "rejected"}`, string(data))
+	})
+
+	t.Run("should have the planned job of a dry run in result", func(t *testing.T) {
+		v1 := JobResult{Request: request, Outcome: OutcomeDryRun, Job: job}.V1()
+		assert.Equal(t, JobResultV1{Request: request, Result: job}, v1)
+	})
+}
@@ -18,4 +18,6 @@ type Location struct {
 	DisplayName    string          `json:"displayName"`
 	ParentLocation *ParentLocation `json:"parentLocation,omitempty"`
 	LocationType   *LocationType   `json:"locationType,omitempty"`
+	// Status is the status of the location in Optii, e.g. "Occupied" or "Blocked" for a room.
+	Status string `json:"status,omitempty"`
 }
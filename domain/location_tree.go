@@ -0,0 +1,135 @@
+package domain
+
+// LocationTree indexes the locations of a property by their parent, e.g. property → building → floor → room.
+// Every query is answered from maps built once by NewLocationTree, the returned slices are shared
+// and must not be modified.
+type LocationTree struct {
+	locations      map[int]Location
+	children       map[int][]Location
+	childrenByType map[int]map[string][]Location
+	descendants    map[int][]Location
+	ancestors      map[int][]Location
+	byType         map[string][]Location
+}
+
+// NewLocationTree builds the tree of the locations, keeping their order among siblings.
+// A location whose parent is not in the list is still a child of its parent ID.
+func NewLocationTree(locations []Location) *LocationTree {
+	lt := &LocationTree{
+		locations:      map[int]Location{},
+		children:       map[int][]Location{},
+		childrenByType: map[int]map[string][]Location{},
+		descendants:    map[int][]Location{},
+		ancestors:      map[int][]Location{},
+		byType:         map[string][]Location{},
+	}
+
+	for _, location := range locations {
+		lt.locations[location.ID] = location
+
+		locationType := typeOf(location)
+		if locationType != "" {
+			lt.byType[locationType] = append(lt.byType[locationType], location)
+		}
+
+		if location.ParentLocation == nil {
+			continue
+		}
+
+		parentID := location.ParentLocation.ID
+		lt.children[parentID] = append(lt.children[parentID], location)
+		if lt.childrenByType[parentID] == nil {
+			lt.childrenByType[parentID] = map[string][]Location{}
+		}
+		lt.childrenByType[parentID][locationType] = append(lt.childrenByType[parentID][locationType], location)
+	}
+
+	for id := range lt.locations {
+		lt.ancestors[id] = lt.collectAncestors(id)
+		lt.descendants[id] = lt.collectDescendants(id, map[int]bool{})
+	}
+
+	return lt
+}
+
+func typeOf(location Location) string {
+	if location.LocationType == nil {
+		return ""
+	}
+
+	return location.LocationType.DisplayName
+}
+
+// collectAncestors follows the parents of the location, stopping at a parent which is not indexed.
+func (lt *LocationTree) collectAncestors(id int) []Location {
+	ancestors := []Location{}
+	visited := map[int]bool{id: true}
+
+	location := lt.locations[id]
+	for location.ParentLocation != nil && !visited[location.ParentLocation.ID] {
+		parent, ok := lt.locations[location.ParentLocation.ID]
+		if !ok {
+			break
+		}
+
+		visited[parent.ID] = true
+		ancestors = append(ancestors, parent)
+		location = parent
+	}
+
+	return ancestors
+}
+
+// collectDescendants returns the descendants of the location depth first.
+// The visited set guards against parent cycles in the data.
+func (lt *LocationTree) collectDescendants(id int, visited map[int]bool) []Location {
+	visited[id] = true
+
+	descendants := []Location{}
+	for _, child := range lt.children[id] {
+		if visited[child.ID] {
+			continue
+		}
+
+		descendants = append(descendants, child)
+		descendants = append(descendants, lt.collectDescendants(child.ID, visited)...)
+	}
+
+	return descendants
+}
+
+// Len returns the number of locations in the tree.
+func (lt *LocationTree) Len() int {
+	return len(lt.locations)
+}
+
+// Get returns the location with the ID.
+func (lt *LocationTree) Get(id int) (Location, bool) {
+	location, ok := lt.locations[id]
+	return location, ok
+}
+
+// Children returns the locations whose parent is the given location.
+func (lt *LocationTree) Children(id int) []Location {
+	return lt.children[id]
+}
+
+// ChildrenOfType returns the children of the location with the location type display name, e.g. "Room".
+func (lt *LocationTree) ChildrenOfType(id int, locationType string) []Location {
+	return lt.childrenByType[id][locationType]
+}
+
+// Descendants returns the children of the location, their children and so on, depth first.
+func (lt *LocationTree) Descendants(id int) []Location {
+	return lt.descendants[id]
+}
+
+// Ancestors returns the parent of the location, the parent of its parent and so on, up to the root.
+func (lt *LocationTree) Ancestors(id int) []Location {
+	return lt.ancestors[id]
+}
+
+// ByType returns the locations with the location type display name, e.g. "Floor".
+func (lt *LocationTree) ByType(locationType string) []Location {
+	return lt.byType[locationType]
+}
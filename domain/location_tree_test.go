@@ -0,0 +1,68 @@
+package domain
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func location(id int, parentID int, locationType string) Location {
+	l := Location{ID: id, LocationType: &LocationType{DisplayName: locationType}}
+	if parentID != 0 {
+		l.ParentLocation = &ParentLocation{ID: parentID}
+	}
+	return l
+}
+
+func TestLocationTree(t *testing.T) {
+	property := location(1, 0, "Property")
+	building := location(2, 1, "Building")
+	floor1 := location(3, 2, "Floor")
+	floor2 := location(4, 2, "Floor")
+	room1 := location(5, 3, "Room")
+	room2 := location(6, 3, "Room")
+	area := location(7, 3, "Area")
+	room3 := location(8, 4, "Room")
+
+	tree := NewLocationTree([]Location{property, building, floor1, floor2, room1, room2, area, room3})
+
+	t.Run("should return the location by ID", func(t *testing.T) {
+		l, ok := tree.Get(5)
+		assert.True(t, ok)
+		assert.Equal(t, room1, l)
+
+		_, ok = tree.Get(99)
+		assert.False(t, ok)
+		assert.Equal(t, 8, tree.Len())
+	})
+
+	t.Run("should return the children of the location", func(t *testing.T) {
+		assert.Equal(t, []Location{room1, room2, area}, tree.Children(3))
+		assert.Equal(t, []Location{room1, room2}, tree.ChildrenOfType(3, "Room"))
+		assert.Empty(t, tree.Children(5))
+	})
+
+	t.Run("should return the descendants of the location depth first", func(t *testing.T) {
+		assert.Equal(t, []Location{floor1, room1, room2, area, floor2, room3}, tree.Descendants(2))
+	})
+
+	t.Run("should return the ancestors of the location up to the root", func(t *testing.T) {
+		assert.Equal(t, []Location{floor1, building, property}, tree.Ancestors(5))
+		assert.Empty(t, tree.Ancestors(1))
+	})
+
+	t.Run("should return the locations by type", func(t *testing.T) {
+		assert.Equal(t, []Location{floor1, floor2}, tree.ByType("Floor"))
+		assert.Empty(t, tree.ByType("Elevator"))
+	})
+
+	t.Run("should not loop on a parent cycle", func(t *testing.T) {
+		a := location(1, 2, "Floor")
+		b := location(2, 1, "Floor")
+
+		tree := NewLocationTree([]Location{a, b})
+
+		assert.Equal(t, []Location{b}, tree.Descendants(1))
+		assert.Equal(t, []Location{b}, tree.Ancestors(1))
+	})
+}
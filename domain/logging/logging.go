@@ -0,0 +1,82 @@
+// Package logging tags the log records with the attributes of the context, e.g. the request ID,
+// so the lines logged while handling a request can be correlated.
+package logging
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Keys of the attributes identifying what a log record is about.
+const (
+	// RequestIDKey is the ID of the HTTP request, from the X-Request-ID header or generated.
+	RequestIDKey = "request_id"
+	// RuleKey is the name of the rule being executed.
+	RuleKey = "rule"
+	// EndpointKey is the Optii endpoint called, e.g. "/departments/{id}".
+	EndpointKey = "endpoint"
+)
+
+type attrsKey struct{}
+
+type loggerKey struct{}
+
+// WithAttrs returns a copy of the context with the attributes added to the ones already in the context.
+func WithAttrs(ctx context.Context, attrs ...slog.Attr) context.Context {
+	current := Attrs(ctx)
+	merged := make([]slog.Attr, 0, len(current)+len(attrs))
+	merged = append(merged, current...)
+	merged = append(merged, attrs...)
+
+	return context.WithValue(ctx, attrsKey{}, merged)
+}
+
+// Attrs returns the attributes of the context, the returned slice must not be modified.
+func Attrs(ctx context.Context) []slog.Attr {
+	attrs, _ := ctx.Value(attrsKey{}).([]slog.Attr)
+	return attrs
+}
+
+// NewContext returns a copy of the context carrying the logger, see FromContext.
+func NewContext(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// FromContext returns the logger of the context, or the default logger when it has none.
+func FromContext(ctx context.Context) *slog.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok && logger != nil {
+		return logger
+	}
+
+	return slog.Default()
+}
+
+// Handler adds the attributes of the context to the records logged with a context, e.g. InfoContext.
+type Handler struct {
+	slog.Handler
+}
+
+// NewHandler wraps the handler so the records carry the attributes of their context.
+func NewHandler(handler slog.Handler) *Handler {
+	return &Handler{Handler: handler}
+}
+
+// Handle adds the attributes of the context to the record before handling it.
+func (h *Handler) Handle(ctx context.Context, record slog.Record) error {
+	if attrs := Attrs(ctx); len(attrs) > 0 {
+		record = record.Clone()
+		record.AddAttrs(attrs...)
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+// WithAttrs returns a Handler whose wrapped handler has the attributes.
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &Handler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+// WithGroup returns a Handler whose wrapped handler has the group.
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{Handler: h.Handler.WithGroup(name)}
+}
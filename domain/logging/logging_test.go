@@ -0,0 +1,46 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler(t *testing.T) {
+	t.Run("should add the attributes of the context to the records", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		logger := slog.New(NewHandler(slog.NewJSONHandler(out, nil))).With("component", "test")
+
+		ctx := WithAttrs(context.Background(), slog.String(RequestIDKey, "abc"))
+		ctx = WithAttrs(ctx, slog.String(RuleKey, "CleanBedsFloor"))
+		logger.InfoContext(ctx, "rule executed")
+
+		line := map[string]interface{}{}
+		assert.NoError(t, json.Unmarshal(out.Bytes(), &line))
+		assert.Equal(t, "rule executed", line["msg"])
+		assert.Equal(t, "test", line["component"])
+		assert.Equal(t, "abc", line[RequestIDKey])
+		assert.Equal(t, "CleanBedsFloor", line[RuleKey])
+	})
+
+	t.Run("should not share the attributes between derived contexts", func(t *testing.T) {
+		ctx := WithAttrs(context.Background(), slog.String(RequestIDKey, "abc"))
+		first := WithAttrs(ctx, slog.String(RuleKey, "first"))
+		second := WithAttrs(ctx, slog.String(RuleKey, "second"))
+
+		assert.Equal(t, []slog.Attr{slog.String(RequestIDKey, "abc")}, Attrs(ctx))
+		assert.Equal(t, "first", Attrs(first)[1].Value.String())
+		assert.Equal(t, "second", Attrs(second)[1].Value.String())
+	})
+
+	t.Run("should return the logger of the context or the default one", func(t *testing.T) {
+		logger := slog.New(slog.NewTextHandler(&bytes.Buffer{}, nil))
+
+		assert.Same(t, logger, FromContext(NewContext(context.Background(), logger)))
+		assert.Same(t, slog.Default(), FromContext(context.Background()))
+	})
+}
@@ -0,0 +1,8 @@
+package domain
+
+// PageInfo locates a page of a list of Optii. The next page starts at EndCursor.
+type PageInfo struct {
+	EndCursor   int  `json:"endCursor"`
+	HasNextPage bool `json:"hasNextPage"`
+	TotalCount  int  `json:"totalCount"`
+}
@@ -0,0 +1,25 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/Twsouza/job-rule-engine/domain/repository"
+)
+
+type RequestRepositoryMock struct {
+	SaveFunc func(ctx context.Context, record *repository.RequestRecord) error
+	GetFunc  func(ctx context.Context, id string) (*repository.RequestRecord, error)
+	ListFunc func(ctx context.Context, filter repository.RequestFilter) ([]repository.RequestRecord, error)
+}
+
+func (m *RequestRepositoryMock) Save(ctx context.Context, record *repository.RequestRecord) error {
+	return m.SaveFunc(ctx, record)
+}
+
+func (m *RequestRepositoryMock) Get(ctx context.Context, id string) (*repository.RequestRecord, error) {
+	return m.GetFunc(ctx, id)
+}
+
+func (m *RequestRepositoryMock) List(ctx context.Context, filter repository.RequestFilter) ([]repository.RequestRecord, error) {
+	return m.ListFunc(ctx, filter)
+}
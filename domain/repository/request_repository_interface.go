@@ -0,0 +1,96 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/application/dto"
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+// ErrRequestNotFound is returned by RequestRepositoryInterface.Get when the request is not stored.
+var ErrRequestNotFound = errors.New("request not found")
+
+// RequestRecord is a job request stored with its outcome.
+type RequestRecord struct {
+	ID string `json:"id"`
+	// Input is the job request as received.
+	Input *dto.JobRequestDto `json:"input"`
+	// Request is the job request loaded from Optii, nil when it couldn't be loaded.
+	Request *domain.JobRequest `json:"request,omitempty"`
+	// MatchedRules are the names of the rules executed for the request.
+	MatchedRules []string           `json:"matchedRules"`
+	Results      []domain.JobResult `json:"results"`
+	// Errors are the errors loading the job request, or the reason why no rule was executed.
+	Errors     []string  `json:"errors,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+// NewRequestRecord returns the record of a job request received now.
+func NewRequestRecord(id string, input *dto.JobRequestDto) *RequestRecord {
+	return &RequestRecord{
+		ID:        id,
+		Input:     input,
+		CreatedAt: time.Now(),
+	}
+}
+
+// Failed returns true if the request couldn't be executed or any rule failed.
+func (r *RequestRecord) Failed() bool {
+	if len(r.Errors) > 0 {
+		return true
+	}
+
+	for _, result := range r.Results {
+		if result.Failed() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// RequestFilter selects the records returned by RequestRepositoryInterface.List, zero fields are ignored.
+type RequestFilter struct {
+	DepartmentID int64
+	JobItemID    int64
+	// From and To restrict the creation date of the requests, To is exclusive.
+	From time.Time
+	To   time.Time
+	// Failed selects the failed requests when true, or the successful ones when false, see RequestRecord.Failed.
+	Failed *bool
+	// Limit is the maximum number of records returned.
+	Limit int
+}
+
+// Match returns true if the record satisfies the filter, ignoring the limit.
+func (f RequestFilter) Match(r *RequestRecord) bool {
+	if f.DepartmentID != 0 && (r.Input == nil || r.Input.DepartmentID != f.DepartmentID) {
+		return false
+	}
+	if f.JobItemID != 0 && (r.Input == nil || r.Input.JobItemID != f.JobItemID) {
+		return false
+	}
+	if !f.From.IsZero() && r.CreatedAt.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !r.CreatedAt.Before(f.To) {
+		return false
+	}
+	if f.Failed != nil && r.Failed() != *f.Failed {
+		return false
+	}
+
+	return true
+}
+
+type RequestRepositoryInterface interface {
+	// Save stores the record, replacing any record with the same ID.
+	Save(ctx context.Context, record *RequestRecord) error
+	// Get returns the record with the given ID, or ErrRequestNotFound.
+	Get(ctx context.Context, id string) (*RequestRecord, error)
+	// List returns the records matching the filter, the most recent first.
+	List(ctx context.Context, filter RequestFilter) ([]RequestRecord, error)
+}
@@ -0,0 +1,26 @@
+package domain
+
+import "time"
+
+// RequestStatus is the status of a job request submitted asynchronously.
+type RequestStatus string
+
+const (
+	// RequestPending is waiting for a worker.
+	RequestPending RequestStatus = "pending"
+	// RequestRunning is being loaded or executed by a worker.
+	RequestRunning RequestStatus = "running"
+	// RequestDone has finished, successfully or not.
+	RequestDone RequestStatus = "done"
+)
+
+// AsyncRequest is a job request executed in the background.
+type AsyncRequest struct {
+	ID      string        `json:"id"`
+	Status  RequestStatus `json:"status"`
+	Results []JobResult   `json:"results,omitempty"`
+	// Errors are the errors loading the job request, or the reason why no rule was executed.
+	Errors     []string   `json:"errors,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"errors"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+var ErrUnknownCacheKind = errors.New("unknown cache kind")
+
+type CacheInterface interface {
+	Stats() []domain.CacheStats
+	// Invalidate removes the entries with the given IDs of a kind, or all of them when no ID is given.
+	// An empty kind removes every entry of the cache.
+	Invalidate(kind string, ids ...int64) error
+}
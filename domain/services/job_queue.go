@@ -0,0 +1,263 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/application/dto"
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/logging"
+	"github.com/Twsouza/job-rule-engine/domain/repository"
+)
+
+var (
+	// ErrQueueFull is returned by JobQueue.Submit when every worker is busy and the queue is full.
+	ErrQueueFull = errors.New("too many pending job requests")
+	// ErrQueueClosed is returned by JobQueue.Submit after the queue is closed.
+	ErrQueueClosed = errors.New("job queue is closed")
+	// ErrNoRulesMatched is the error of a job request that didn't match any rule.
+	ErrNoRulesMatched = errors.New("no rules matched for this job")
+	// ErrQueueShutdown is the error of a queued job request not executed before the deadline of JobQueue.Close.
+	ErrQueueShutdown = errors.New("job request not executed before the shutdown")
+)
+
+type queuedJob struct {
+	id     string
+	reqDto *dto.JobRequestDto
+	// attrs are the logging attributes of the submitted request, e.g. its request ID
+	attrs []slog.Attr
+}
+
+// JobQueue loads and executes job requests in the background with a pool of workers.
+// The requests can be polled by ID until they are older than the retention.
+type JobQueue struct {
+	JobService JobServiceInterface
+	// Timeout is the deadline to load and execute each job request, zero means no deadline.
+	Timeout time.Duration
+	// Retention is how long finished requests are kept, zero keeps them forever.
+	Retention time.Duration
+	// Repository stores the executed requests, when set.
+	Repository repository.RequestRepositoryInterface
+
+	queue chan queuedJob
+	wg    sync.WaitGroup
+	// baseCtx is the parent of the contexts executing the requests, cancelled when Close times out
+	baseCtx    context.Context
+	cancelBase context.CancelFunc
+
+	mu       sync.RWMutex
+	closed   bool
+	requests map[string]*domain.AsyncRequest
+}
+
+// NewJobQueue starts the given number of workers, at least one, executing the job requests
+// with the JobService. Up to size requests wait for a worker before Submit returns ErrQueueFull.
+func NewJobQueue(js JobServiceInterface, workers, size int) *JobQueue {
+	if workers < 1 {
+		workers = 1
+	}
+	if size < 0 {
+		size = 0
+	}
+
+	q := &JobQueue{
+		JobService: js,
+		queue:      make(chan queuedJob, size),
+		requests:   map[string]*domain.AsyncRequest{},
+	}
+	q.baseCtx, q.cancelBase = context.WithCancel(context.Background())
+
+	q.wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go q.work()
+	}
+
+	return q
+}
+
+// Submit queues the job request and returns it with the pending status.
+// The request must have been validated, loading errors are reported in the request errors.
+// Only the logging attributes of the context are kept, the request is executed with its own context.
+func (q *JobQueue) Submit(ctx context.Context, reqDto *dto.JobRequestDto) (*domain.AsyncRequest, error) {
+	id, err := NewRequestID()
+	if err != nil {
+		return nil, err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return nil, ErrQueueClosed
+	}
+	q.prune()
+
+	select {
+	case q.queue <- queuedJob{id: id, reqDto: reqDto, attrs: logging.Attrs(ctx)}:
+	default:
+		return nil, ErrQueueFull
+	}
+
+	ar := &domain.AsyncRequest{
+		ID:        id,
+		Status:    domain.RequestPending,
+		CreatedAt: time.Now(),
+	}
+	q.requests[id] = ar
+
+	return copyRequest(ar), nil
+}
+
+// Get returns a copy of the request with the given ID.
+func (q *JobQueue) Get(id string) (*domain.AsyncRequest, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	ar, ok := q.requests[id]
+	if !ok {
+		return nil, false
+	}
+
+	return copyRequest(ar), true
+}
+
+// Close stops accepting requests and waits for the queued requests to be executed.
+// If the context is done first, the running requests are cancelled, the requests still queued are
+// finished with ErrQueueShutdown without being executed, and the context error is returned once
+// every worker has exited, so the Repository can be closed after Close returns.
+func (q *JobQueue) Close(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.queue)
+	}
+	q.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		q.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		q.cancelBase()
+		return nil
+	case <-ctx.Done():
+		q.cancelBase()
+		<-done
+		return ctx.Err()
+	}
+}
+
+// work executes the queued requests until the queue is closed.
+func (q *JobQueue) work() {
+	defer q.wg.Done()
+
+	for job := range q.queue {
+		if q.baseCtx.Err() != nil {
+			q.abandon(job)
+			continue
+		}
+		q.execute(job)
+	}
+}
+
+// execute loads and executes a queued request, updating its status.
+func (q *JobQueue) execute(job queuedJob) {
+	record := repository.NewRequestRecord(job.id, job.reqDto)
+	q.update(job.id, func(ar *domain.AsyncRequest) {
+		ar.Status = domain.RequestRunning
+		record.CreatedAt = ar.CreatedAt
+	})
+
+	ctx, cancel := q.context()
+	defer cancel()
+	ctx = logging.WithAttrs(ctx, job.attrs...)
+
+	ExecuteRequest(ctx, q.JobService, record)
+	SaveRecord(q.Repository, record)
+
+	q.update(job.id, func(ar *domain.AsyncRequest) {
+		ar.Status = domain.RequestDone
+		ar.Results = record.Results
+		ar.Errors = record.Errors
+		ar.FinishedAt = &record.FinishedAt
+	})
+}
+
+// abandon finishes a queued request with ErrQueueShutdown without executing it, and stores it.
+func (q *JobQueue) abandon(job queuedJob) {
+	record := repository.NewRequestRecord(job.id, job.reqDto)
+	record.Errors = []string{ErrQueueShutdown.Error()}
+	record.FinishedAt = time.Now()
+	q.update(job.id, func(ar *domain.AsyncRequest) {
+		record.CreatedAt = ar.CreatedAt
+	})
+
+	SaveRecord(q.Repository, record)
+
+	q.update(job.id, func(ar *domain.AsyncRequest) {
+		ar.Status = domain.RequestDone
+		ar.Errors = record.Errors
+		ar.FinishedAt = &record.FinishedAt
+	})
+}
+
+// context returns the context to execute a request, with the Timeout of the queue when set.
+// It's cancelled when Close times out.
+func (q *JobQueue) context() (context.Context, context.CancelFunc) {
+	if q.Timeout <= 0 {
+		return context.WithCancel(q.baseCtx)
+	}
+
+	return context.WithTimeout(q.baseCtx, q.Timeout)
+}
+
+// update changes the request with the given ID while holding the lock.
+func (q *JobQueue) update(id string, fn func(ar *domain.AsyncRequest)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if ar, ok := q.requests[id]; ok {
+		fn(ar)
+	}
+}
+
+// prune removes the requests finished before the retention, the caller must hold the lock.
+func (q *JobQueue) prune() {
+	if q.Retention <= 0 {
+		return
+	}
+
+	limit := time.Now().Add(-q.Retention)
+	for id, ar := range q.requests {
+		if ar.FinishedAt != nil && ar.FinishedAt.Before(limit) {
+			delete(q.requests, id)
+		}
+	}
+}
+
+// copyRequest returns a copy of the request that can be read without holding the lock.
+func copyRequest(ar *domain.AsyncRequest) *domain.AsyncRequest {
+	c := *ar
+	c.Results = append([]domain.JobResult(nil), ar.Results...)
+	c.Errors = append([]string(nil), ar.Errors...)
+
+	return &c
+}
+
+// NewRequestID returns a random ID for a request.
+func NewRequestID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
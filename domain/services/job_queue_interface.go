@@ -0,0 +1,13 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Twsouza/job-rule-engine/application/dto"
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+type JobQueueInterface interface {
+	Submit(ctx context.Context, reqDto *dto.JobRequestDto) (*domain.AsyncRequest, error)
+	Get(id string) (*domain.AsyncRequest, bool)
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/application/dto"
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/repository"
+	repositoryMock "github.com/Twsouza/job-rule-engine/domain/repository/mock"
+	servicesMock "github.com/Twsouza/job-rule-engine/domain/services/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+// waitDone polls the request until it's done.
+func waitDone(t *testing.T, q *JobQueue, id string) *domain.AsyncRequest {
+	t.Helper()
+
+	var ar *domain.AsyncRequest
+	assert.Eventually(t, func() bool {
+		var ok bool
+		ar, ok = q.Get(id)
+		return ok && ar.Status == domain.RequestDone
+	}, time.Second, 5*time.Millisecond)
+
+	return ar
+}
+
+func TestJobQueue(t *testing.T) {
+	reqDto := &dto.JobRequestDto{DepartmentID: 1, JobItemID: 2, LocationsID: []int64{3}}
+	jobRequest := &domain.JobRequest{Department: &domain.Department{ID: 1, Name: "Engineering"}}
+
+	t.Run("should execute the submitted request in the background", func(t *testing.T) {
+		release := make(chan struct{})
+		js := &servicesMock.JobServiceMock{
+			LoadJobFunc: func(ctx context.Context, d *dto.JobRequestDto) (*domain.JobRequest, []error) {
+				assert.Equal(t, reqDto, d)
+				<-release
+				return jobRequest, nil
+			},
+			CreateJobFunc: func(ctx context.Context, jr *domain.JobRequest) []domain.JobResult {
+				return []domain.JobResult{{Request: jr, Outcome: domain.OutcomeCreated, Created: &domain.JobCreated{ID: 1}}}
+			},
+		}
+
+		q := NewJobQueue(js, 1, 1)
+		defer q.Close(context.Background())
+
+		ar, err := q.Submit(context.Background(), reqDto)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.RequestPending, ar.Status)
+		assert.NotEmpty(t, ar.ID)
+
+		assert.Eventually(t, func() bool {
+			ar, _ := q.Get(ar.ID)
+			return ar.Status == domain.RequestRunning
+		}, time.Second, 5*time.Millisecond)
+
+		close(release)
+		done := waitDone(t, q, ar.ID)
+		assert.Equal(t, []domain.JobResult{{Request: jobRequest, Outcome: domain.OutcomeCreated, Created: &domain.JobCreated{ID: 1}}}, done.Results)
+		assert.Empty(t, done.Errors)
+		assert.NotNil(t, done.FinishedAt)
+	})
+
+	t.Run("should report the errors loading the request", func(t *testing.T) {
+		js := &servicesMock.JobServiceMock{
+			LoadJobFunc: func(ctx context.Context, d *dto.JobRequestDto) (*domain.JobRequest, []error) {
+				return &domain.JobRequest{}, []error{errors.New("department not found")}
+			},
+			CreateJobFunc: func(ctx context.Context, jr *domain.JobRequest) []domain.JobResult {
+				t.Error("CreateJob should not be called")
+				return nil
+			},
+		}
+
+		q := NewJobQueue(js, 1, 1)
+		defer q.Close(context.Background())
+
+		ar, err := q.Submit(context.Background(), reqDto)
+		assert.NoError(t, err)
+
+		done := waitDone(t, q, ar.ID)
+		assert.Equal(t, []string{"department not found"}, done.Errors)
+		assert.Empty(t, done.Results)
+	})
+
+	t.Run("should report when no rules matched", func(t *testing.T) {
+		js := &servicesMock.JobServiceMock{
+			LoadJobFunc: func(ctx context.Context, d *dto.JobRequestDto) (*domain.JobRequest, []error) {
+				return jobRequest, nil
+			},
+			CreateJobFunc: func(ctx context.Context, jr *domain.JobRequest) []domain.JobResult {
+				return nil
+			},
+		}
+
+		q := NewJobQueue(js, 1, 1)
+		defer q.Close(context.Background())
+
+		ar, err := q.Submit(context.Background(), reqDto)
+		assert.NoError(t, err)
+
+		done := waitDone(t, q, ar.ID)
+		assert.Equal(t, []string{ErrNoRulesMatched.Error()}, done.Errors)
+	})
+
+	t.Run("should execute the request with the timeout", func(t *testing.T) {
+		js := &servicesMock.JobServiceMock{
+			LoadJobFunc: func(ctx context.Context, d *dto.JobRequestDto) (*domain.JobRequest, []error) {
+				_, ok := ctx.Deadline()
+				assert.True(t, ok)
+				return jobRequest, nil
+			},
+			CreateJobFunc: func(ctx context.Context, jr *domain.JobRequest) []domain.JobResult {
+				return []domain.JobResult{{Request: jr}}
+			},
+		}
+
+		q := NewJobQueue(js, 1, 1)
+		q.Timeout = time.Minute
+		defer q.Close(context.Background())
+
+		ar, err := q.Submit(context.Background(), reqDto)
+		assert.NoError(t, err)
+		waitDone(t, q, ar.ID)
+	})
+
+	t.Run("should reject requests when the queue is full", func(t *testing.T) {
+		release := make(chan struct{})
+		js := &servicesMock.JobServiceMock{
+			LoadJobFunc: func(ctx context.Context, d *dto.JobRequestDto) (*domain.JobRequest, []error) {
+				<-release
+				return jobRequest, nil
+			},
+			CreateJobFunc: func(ctx context.Context, jr *domain.JobRequest) []domain.JobResult {
+				return []domain.JobResult{{Request: jr}}
+			},
+		}
+
+		q := NewJobQueue(js, 1, 1)
+
+		running, err := q.Submit(context.Background(), reqDto)
+		assert.NoError(t, err)
+		assert.Eventually(t, func() bool {
+			ar, _ := q.Get(running.ID)
+			return ar.Status == domain.RequestRunning
+		}, time.Second, 5*time.Millisecond)
+
+		queued, err := q.Submit(context.Background(), reqDto)
+		assert.NoError(t, err)
+
+		_, err = q.Submit(context.Background(), reqDto)
+		assert.ErrorIs(t, err, ErrQueueFull)
+
+		// Closing drains the queued requests
+		close(release)
+		assert.NoError(t, q.Close(context.Background()))
+
+		ar, _ := q.Get(queued.ID)
+		assert.Equal(t, domain.RequestDone, ar.Status)
+
+		_, err = q.Submit(context.Background(), reqDto)
+		assert.ErrorIs(t, err, ErrQueueClosed)
+	})
+
+	t.Run("should cancel the running requests and abandon the queued ones when closing times out", func(t *testing.T) {
+		js := &servicesMock.JobServiceMock{
+			LoadJobFunc: func(ctx context.Context, d *dto.JobRequestDto) (*domain.JobRequest, []error) {
+				<-ctx.Done()
+				return nil, []error{ctx.Err()}
+			},
+		}
+
+		saved := map[string][]string{}
+		var mu sync.Mutex
+		q := NewJobQueue(js, 1, 1)
+		q.Repository = &repositoryMock.RequestRepositoryMock{
+			SaveFunc: func(ctx context.Context, record *repository.RequestRecord) error {
+				mu.Lock()
+				defer mu.Unlock()
+				saved[record.ID] = record.Errors
+				return nil
+			},
+		}
+
+		running, err := q.Submit(context.Background(), reqDto)
+		assert.NoError(t, err)
+		assert.Eventually(t, func() bool {
+			ar, _ := q.Get(running.ID)
+			return ar.Status == domain.RequestRunning
+		}, time.Second, 5*time.Millisecond)
+		queued, err := q.Submit(context.Background(), reqDto)
+		assert.NoError(t, err)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+		assert.ErrorIs(t, q.Close(ctx), context.DeadlineExceeded)
+
+		// Every worker has exited and saved its request when Close returns
+		assert.Equal(t, map[string][]string{
+			running.ID: {context.Canceled.Error()},
+			queued.ID:  {ErrQueueShutdown.Error()},
+		}, saved)
+		ar, _ := q.Get(queued.ID)
+		assert.Equal(t, domain.RequestDone, ar.Status)
+	})
+
+	t.Run("should remove the requests older than the retention", func(t *testing.T) {
+		js := &servicesMock.JobServiceMock{
+			LoadJobFunc: func(ctx context.Context, d *dto.JobRequestDto) (*domain.JobRequest, []error) {
+				return jobRequest, nil
+			},
+			CreateJobFunc: func(ctx context.Context, jr *domain.JobRequest) []domain.JobResult {
+				return []domain.JobResult{{Request: jr}}
+			},
+		}
+
+		q := NewJobQueue(js, 1, 1)
+		q.Retention = time.Millisecond
+		defer q.Close(context.Background())
+
+		old, err := q.Submit(context.Background(), reqDto)
+		assert.NoError(t, err)
+		waitDone(t, q, old.ID)
+		time.Sleep(5 * time.Millisecond)
+
+		_, err = q.Submit(context.Background(), reqDto)
+		assert.NoError(t, err)
+
+		_, ok := q.Get(old.ID)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,149 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/Twsouza/job-rule-engine/application/dto"
+	"github.com/Twsouza/job-rule-engine/domain"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// defaultLoadConcurrency is the number of concurrent calls made to Optii by LoadJobs when LoadConcurrency is not set.
+const defaultLoadConcurrency = 8
+
+// LoadJobs loads a batch of job requests like LoadJob, but each department, job item and location
+// shared by several requests is retrieved only once, with at most LoadConcurrency concurrent calls.
+// It returns the loaded requests and their errors, in the order of the given requests.
+func (js *JobService) LoadJobs(ctx context.Context, reqDtos []*dto.JobRequestDto) ([]*domain.JobRequest, [][]error) {
+	var departmentIDs, jobItemIDs, locationIDs []int64
+	seen := map[string]bool{}
+	unique := func(ids *[]int64, kind string, id int64) {
+		key := fmt.Sprintf("%s/%d", kind, id)
+		if !seen[key] {
+			seen[key] = true
+			*ids = append(*ids, id)
+		}
+	}
+	for _, reqDto := range reqDtos {
+		unique(&departmentIDs, "department", reqDto.DepartmentID)
+		unique(&jobItemIDs, "jobItem", reqDto.JobItemID)
+		for _, id := range reqDto.LocationsID {
+			unique(&locationIDs, "location", id)
+		}
+	}
+
+	departments := map[int64]*domain.Department{}
+	jobItems := map[int64]*domain.JobItem{}
+	locations := map[int64]*domain.Location{}
+
+	concurrency := js.LoadConcurrency
+	if concurrency < 1 {
+		concurrency = defaultLoadConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	mu := sync.Mutex{}
+	errs := map[string]error{}
+
+	// load calls fn in a goroutine once a slot is available, storing its error with the given key.
+	// The call is traced in a "Load <key>" span.
+	load := func(key string, fn func(ctx context.Context) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			ctx, span := tracer.Start(ctx, "Load "+key)
+			defer span.End()
+
+			err := fn(ctx)
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+				errs[key] = err
+			}
+		}()
+	}
+
+	// The maps are only written while holding mu, and read once every call is done
+	for _, id := range departmentIDs {
+		id := id
+		load(fmt.Sprintf("department/%d", id), func(ctx context.Context) error {
+			department, err := js.OptiiAPI.GetDepartmentByID(ctx, id)
+			mu.Lock()
+			defer mu.Unlock()
+			departments[id] = department
+			return err
+		})
+	}
+	for _, id := range jobItemIDs {
+		id := id
+		load(fmt.Sprintf("jobItem/%d", id), func(ctx context.Context) error {
+			jobItem, err := js.OptiiAPI.GetJobItemByID(ctx, id)
+			mu.Lock()
+			defer mu.Unlock()
+			jobItems[id] = jobItem
+			return err
+		})
+	}
+	for _, id := range locationIDs {
+		id := id
+		// The locations are loaded one by one so an unknown location only fails the requests using it
+		load(fmt.Sprintf("location/%d", id), func(ctx context.Context) error {
+			loaded, err := js.OptiiAPI.GetLocationsByIds(ctx, []int64{id})
+			if err == nil && len(loaded) == 0 {
+				err = fmt.Errorf("%d %w", id, ErrNotFound)
+			}
+			if err != nil {
+				return err
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			locations[id] = &loaded[0]
+			return nil
+		})
+	}
+	wg.Wait()
+
+	jobReqs := make([]*domain.JobRequest, len(reqDtos))
+	reqErrs := make([][]error, len(reqDtos))
+	for i, reqDto := range reqDtos {
+		jr := &domain.JobRequest{
+			Strategy: domain.ExecutionStrategy(reqDto.Strategy),
+		}
+		reqErrs[i] = []error{}
+
+		if err := errs[fmt.Sprintf("department/%d", reqDto.DepartmentID)]; err != nil {
+			reqErrs[i] = append(reqErrs[i], fmt.Errorf("department %w", err))
+		} else {
+			jr.Department = departments[reqDto.DepartmentID]
+		}
+
+		if err := errs[fmt.Sprintf("jobItem/%d", reqDto.JobItemID)]; err != nil {
+			reqErrs[i] = append(reqErrs[i], fmt.Errorf("jobItem %w", err))
+		} else {
+			jr.JobItem = jobItems[reqDto.JobItemID]
+		}
+
+		var locationErr error
+		for _, id := range reqDto.LocationsID {
+			if err := errs[fmt.Sprintf("location/%d", id)]; err != nil {
+				locationErr = err
+				break
+			}
+			jr.Locations = append(jr.Locations, *locations[id])
+		}
+		if locationErr != nil {
+			reqErrs[i] = append(reqErrs[i], fmt.Errorf("location %w", locationErr))
+			jr.Locations = nil
+		}
+
+		jobReqs[i] = jr
+	}
+
+	return jobReqs, reqErrs
+}
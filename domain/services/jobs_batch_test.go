@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/application/dto"
+	"github.com/Twsouza/job-rule-engine/domain"
+	servicesMock "github.com/Twsouza/job-rule-engine/domain/services/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadJobs(t *testing.T) {
+	mu := sync.Mutex{}
+	calls := map[string]int{}
+	count := func(key string) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls[key]++
+	}
+
+	optiiAPIMock := &servicesMock.OptiiApiMock{
+		GetDepartmentByIDFunc: func(ctx context.Context, id int64) (*domain.Department, error) {
+			count(fmt.Sprintf("department/%d", id))
+			if id == 99 {
+				return nil, errors.New("not found")
+			}
+			return &domain.Department{ID: int(id), Name: "Engineering"}, nil
+		},
+		GetJobItemByIDFunc: func(ctx context.Context, id int64) (*domain.JobItem, error) {
+			count(fmt.Sprintf("jobItem/%d", id))
+			return &domain.JobItem{ID: int(id), DisplayName: "Item"}, nil
+		},
+		GetLocationsByIdsFunc: func(ctx context.Context, ids []int64) ([]domain.Location, error) {
+			assert.Len(t, ids, 1)
+			count(fmt.Sprintf("location/%d", ids[0]))
+			if ids[0] == 99 {
+				return nil, errors.New("not found")
+			}
+			return []domain.Location{{ID: int(ids[0])}}, nil
+		},
+	}
+
+	jobService := &JobService{
+		OptiiAPI:        optiiAPIMock,
+		LoadConcurrency: 2,
+	}
+
+	reqDtos := []*dto.JobRequestDto{
+		{DepartmentID: 1, JobItemID: 2, LocationsID: []int64{3, 4}, Strategy: "firstMatch"},
+		{DepartmentID: 1, JobItemID: 2, LocationsID: []int64{4, 3}},
+		{DepartmentID: 99, JobItemID: 2, LocationsID: []int64{3, 99}},
+	}
+
+	jobReqs, errs := jobService.LoadJobs(context.Background(), reqDtos)
+
+	t.Run("should load each entity once", func(t *testing.T) {
+		assert.Equal(t, map[string]int{
+			"department/1":  1,
+			"department/99": 1,
+			"jobItem/2":     1,
+			"location/3":    1,
+			"location/4":    1,
+			"location/99":   1,
+		}, calls)
+	})
+
+	t.Run("should return the requests in order", func(t *testing.T) {
+		assert.Len(t, jobReqs, 3)
+		assert.Empty(t, errs[0])
+		assert.Equal(t, &domain.JobRequest{
+			Department: &domain.Department{ID: 1, Name: "Engineering"},
+			JobItem:    &domain.JobItem{ID: 2, DisplayName: "Item"},
+			Locations:  []domain.Location{{ID: 3}, {ID: 4}},
+			Strategy:   domain.StrategyFirstMatch,
+		}, jobReqs[0])
+
+		assert.Empty(t, errs[1])
+		assert.Equal(t, []domain.Location{{ID: 4}, {ID: 3}}, jobReqs[1].Locations)
+	})
+
+	t.Run("should return the errors of each request", func(t *testing.T) {
+		assert.Len(t, errs[2], 2)
+		assert.EqualError(t, errs[2][0], "department not found")
+		assert.EqualError(t, errs[2][1], "location not found")
+		assert.Nil(t, jobReqs[2].Department)
+		assert.Nil(t, jobReqs[2].Locations)
+		assert.NotNil(t, jobReqs[2].JobItem)
+	})
+}
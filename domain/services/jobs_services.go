@@ -1,17 +1,38 @@
 package services
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
+	"sort"
 	"sync"
+	"time"
 
 	"github.com/Twsouza/job-rule-engine/application/dto"
 	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/logging"
 	"github.com/Twsouza/job-rule-engine/domain/tasks"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	oteltrace "go.opentelemetry.io/otel/trace"
 )
 
+// tracer creates the spans of the service, they are not recorded until a tracer provider is registered.
+var tracer = otel.Tracer("github.com/Twsouza/job-rule-engine/domain/services")
+
 type JobService struct {
 	Tasks    []tasks.JobTask
 	OptiiAPI OptiiApiInterface
+	// Strategy is the default execution strategy, StrategyAllMatching when empty.
+	Strategy domain.ExecutionStrategy
+	// LoadConcurrency is the maximum number of concurrent calls made to Optii by LoadJobs.
+	LoadConcurrency int
+	// Metrics records the loading and the execution of the job requests, when nil they are not recorded.
+	Metrics MetricsInterface
+	// Logger logs the execution of the rules, the default logger is used when nil.
+	// It's passed to the rules in their context, see logging.FromContext.
+	Logger *slog.Logger
 }
 
 func NewJobService(tasks []tasks.JobTask, optiiAPI OptiiApiInterface) *JobService {
@@ -22,24 +43,45 @@ func NewJobService(tasks []tasks.JobTask, optiiAPI OptiiApiInterface) *JobServic
 }
 
 // CreateJob creates a job based on the given jobRequest and executes the rules associated with the JobService.
-// It returns a slice of domain.JobResult containing the results of the executed rules.
+// Only the matching rules selected by the execution strategy are executed, see MatchRules.
+// It returns a slice of domain.JobResult containing the results of the executed rules, with their name and timing.
+// In a context returned by tasks.WithDryRun the rules are not executed, their jobs are only planned, see tasks.DryRun.
 // The function uses a channel to receive the domain.JobResult from each executed rule concurrently.
 // The function waits for all rules to finish executing before returning the results.
-func (js *JobService) CreateJob(jobRequest *domain.JobRequest) []domain.JobResult {
+// The context is passed to every rule, cancelling it aborts their calls to Optii.
+// Each execution is traced in an "Execute" span, and logged with the rule name.
+func (js *JobService) CreateJob(ctx context.Context, jobRequest *domain.JobRequest) []domain.JobResult {
 	jrCh := make(chan domain.JobResult)
 	wg := sync.WaitGroup{}
 
-	for _, t := range js.Tasks {
+	metrics := js.metrics()
+	logger := js.logger()
+	ctx = logging.NewContext(ctx, logger)
+	for _, t := range js.MatchRules(ctx, jobRequest) {
+		wg.Add(1)
+		metrics.ObserveRuleMatch(tasks.NameOf(t))
+
 		// To avoid any rule changing the jobRequest, I'm passing jobRequest as a value to each rule instead of a reference.
-		if t.AssertRule(*jobRequest) {
-			wg.Add(1)
-
-			go func(t tasks.JobTask, req domain.JobRequest) {
-				defer wg.Done()
-				jr := t.Execute(req)
-				jrCh <- jr
-			}(t, *jobRequest)
-		}
+		go func(t tasks.JobTask, req domain.JobRequest) {
+			defer wg.Done()
+			ctx, span := tracer.Start(ctx, "Execute", oteltrace.WithAttributes(attribute.String("rule", tasks.NameOf(t))))
+			ctx = logging.WithAttrs(ctx, slog.String(logging.RuleKey, tasks.NameOf(t)))
+			start := time.Now()
+			jr := execute(ctx, t, req)
+			jr.Normalize()
+			jr.Rule = tasks.NameOf(t)
+			jr.StartedAt, jr.FinishedAt = start, time.Now()
+			duration := jr.Duration()
+			metrics.ObserveExecute(jr.Rule, duration, jr.Failed())
+			if jr.Failed() {
+				span.SetStatus(codes.Error, jr.Error.Message)
+				logger.ErrorContext(ctx, "rule failed", "error", jr.Error.Message, "code", jr.Error.Code, "outcome", jr.Outcome, "duration", duration)
+			} else {
+				logger.InfoContext(ctx, "rule executed", "outcome", jr.Outcome, "duration", duration)
+			}
+			span.End()
+			jrCh <- jr
+		}(t, *jobRequest)
 	}
 
 	go func() {
@@ -55,10 +97,190 @@ func (js *JobService) CreateJob(jobRequest *domain.JobRequest) []domain.JobResul
 	return results
 }
 
+// metrics returns the Metrics of the service, or a MetricsInterface recording nothing.
+func (js *JobService) metrics() MetricsInterface {
+	if js.Metrics == nil {
+		return noMetrics{}
+	}
+
+	return js.Metrics
+}
+
+// logger returns the Logger of the service, or the default logger.
+func (js *JobService) logger() *slog.Logger {
+	if js.Logger == nil {
+		return slog.Default()
+	}
+
+	return js.Logger
+}
+
+// MatchRules returns the rules to execute for the given jobRequest.
+// The rules whose AssertRule returns true are filtered by the strategy of the jobRequest,
+// or by the strategy of the service when the request has none:
+//   - StrategyAllMatching returns every matching rule.
+//   - StrategyFirstMatch returns the matching rule with the highest priority.
+//   - StrategyHighestPriority returns every matching rule sharing the highest priority.
+//
+// Regardless of the strategy, when an exclusive rule matches only the exclusive rule with the highest priority is returned.
+// Ties are broken by the order of the rules in the service. The built-in rules created by
+// tasks.NewRegistered are sorted alphabetically by name, followed by the rules of the rules file
+// in the order they are declared, so renaming a rule can change which one wins a tie.
+// Each rule assertion is traced in an "AssertRule" span.
+func (js *JobService) MatchRules(ctx context.Context, jobRequest *domain.JobRequest) []tasks.JobTask {
+	var selected []tasks.JobTask
+	for _, i := range js.selectRules(ctx, jobRequest) {
+		selected = append(selected, js.Tasks[i])
+	}
+
+	return selected
+}
+
+// MatchedRules returns the names of the rules returned by MatchRules, see tasks.NameOf.
+func (js *JobService) MatchedRules(ctx context.Context, jobRequest *domain.JobRequest) []string {
+	names := []string{}
+	for _, t := range js.MatchRules(ctx, jobRequest) {
+		names = append(names, tasks.NameOf(t))
+	}
+
+	return names
+}
+
+// selectRules returns the indexes in js.Tasks of the rules returned by MatchRules.
+func (js *JobService) selectRules(ctx context.Context, jobRequest *domain.JobRequest) []int {
+	var matched []int
+	for i, t := range js.Tasks {
+		if assertRule(ctx, t, jobRequest) {
+			matched = append(matched, i)
+		}
+	}
+
+	if len(matched) == 0 {
+		return nil
+	}
+
+	priority := func(i int) int {
+		return tasks.PriorityOf(js.Tasks[i])
+	}
+
+	// Sorting by priority keeps the order of the rules with the same priority
+	byPriority := make([]int, len(matched))
+	copy(byPriority, matched)
+	sort.SliceStable(byPriority, func(i, j int) bool {
+		return priority(byPriority[i]) > priority(byPriority[j])
+	})
+
+	for _, i := range byPriority {
+		if tasks.IsExclusive(js.Tasks[i]) {
+			return []int{i}
+		}
+	}
+
+	strategy := jobRequest.Strategy
+	if strategy == "" {
+		strategy = js.Strategy
+	}
+
+	switch strategy {
+	case domain.StrategyFirstMatch:
+		return byPriority[:1]
+	case domain.StrategyHighestPriority:
+		highest := priority(byPriority[0])
+		var selected []int
+		for _, i := range matched {
+			if priority(i) == highest {
+				selected = append(selected, i)
+			}
+		}
+		return selected
+	}
+
+	return matched
+}
+
+// assertRule calls the AssertRule of the rule in an "AssertRule" span.
+func assertRule(ctx context.Context, t tasks.JobTask, jobRequest *domain.JobRequest) bool {
+	_, span := tracer.Start(ctx, "AssertRule", oteltrace.WithAttributes(attribute.String("rule", tasks.NameOf(t))))
+	defer span.End()
+
+	matched := t.AssertRule(*jobRequest)
+	span.SetAttributes(attribute.Bool("matched", matched))
+
+	return matched
+}
+
+// Explain evaluates every rule for the given jobRequest without creating any job.
+// Each rule trace contains the outcome of the rule conditions, when the rule can explain them,
+// and whether the rule would be executed according to the execution strategy.
+// The rules that would be executed build the job they would send to Optii, concurrently.
+func (js *JobService) Explain(ctx context.Context, jobRequest *domain.JobRequest) *domain.Explanation {
+	strategy := jobRequest.Strategy
+	if strategy == "" {
+		strategy = js.Strategy
+	}
+	if strategy == "" {
+		strategy = domain.StrategyAllMatching
+	}
+
+	explanation := &domain.Explanation{
+		Request:  jobRequest,
+		Strategy: strategy,
+		Rules:    make([]domain.RuleTrace, len(js.Tasks)),
+	}
+
+	selected := map[int]bool{}
+	for _, i := range js.selectRules(ctx, jobRequest) {
+		selected[i] = true
+	}
+
+	wg := sync.WaitGroup{}
+	for i, t := range js.Tasks {
+		trace := &explanation.Rules[i]
+		trace.Rule = tasks.NameOf(t)
+		trace.Priority = tasks.PriorityOf(t)
+		trace.Exclusive = tasks.IsExclusive(t)
+		trace.Matched = t.AssertRule(*jobRequest)
+		trace.Selected = selected[i]
+
+		if e, ok := t.(tasks.ExplainableTask); ok {
+			trace.Conditions = e.Explain(*jobRequest)
+		}
+
+		planner, ok := t.(tasks.JobPlanner)
+		if !trace.Selected || !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(planner tasks.JobPlanner, req domain.JobRequest) {
+			defer wg.Done()
+			ctx, span := tracer.Start(ctx, "Plan", oteltrace.WithAttributes(attribute.String("rule", trace.Rule)))
+			defer span.End()
+
+			job, err := planner.Plan(ctx, req)
+			if err != nil {
+				span.SetStatus(codes.Error, err.Error())
+				trace.Err = err.Error()
+				return
+			}
+			trace.Job = job
+		}(planner, *jobRequest)
+	}
+	wg.Wait()
+
+	return explanation
+}
+
 // LoadJob loads a job request by retrieving the department, job item, and locations
 // associated with the given JobRequestDto. It uses concurrent goroutines to fetch
 // the data and returns the loaded JobRequest along with any errors encountered.
-func (js *JobService) LoadJob(reqDto *dto.JobRequestDto) (*domain.JobRequest, []error) {
+// The context is passed to every call made to Optii, each goroutine is traced in its own span.
+func (js *JobService) LoadJob(ctx context.Context, reqDto *dto.JobRequestDto) (jr *domain.JobRequest, errs []error) {
+	start := time.Now()
+	defer func() {
+		js.metrics().ObserveLoadJob(time.Since(start), len(errs) > 0)
+	}()
+
 	var wg sync.WaitGroup
 	errChan := make(chan error, 3)
 	departmentChan := make(chan *domain.Department, 1)
@@ -68,8 +290,12 @@ func (js *JobService) LoadJob(reqDto *dto.JobRequestDto) (*domain.JobRequest, []
 	wg.Add(3)
 	go func() {
 		defer wg.Done()
-		department, err := js.OptiiAPI.GetDepartmentByID(reqDto.DepartmentID)
+		ctx, span := tracer.Start(ctx, "Load department")
+		defer span.End()
+
+		department, err := js.OptiiAPI.GetDepartmentByID(ctx, reqDto.DepartmentID)
 		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
 			errChan <- fmt.Errorf("department %w", err)
 			close(departmentChan)
 			return
@@ -79,8 +305,12 @@ func (js *JobService) LoadJob(reqDto *dto.JobRequestDto) (*domain.JobRequest, []
 
 	go func() {
 		defer wg.Done()
-		jobItem, err := js.OptiiAPI.GetJobItemByID(reqDto.JobItemID)
+		ctx, span := tracer.Start(ctx, "Load jobItem")
+		defer span.End()
+
+		jobItem, err := js.OptiiAPI.GetJobItemByID(ctx, reqDto.JobItemID)
 		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
 			errChan <- fmt.Errorf("jobItem %w", err)
 			close(jobItemChan)
 			return
@@ -90,8 +320,12 @@ func (js *JobService) LoadJob(reqDto *dto.JobRequestDto) (*domain.JobRequest, []
 
 	go func() {
 		defer wg.Done()
-		locations, err := js.OptiiAPI.GetLocationsByIds(reqDto.LocationsID)
+		ctx, span := tracer.Start(ctx, "Load locations")
+		defer span.End()
+
+		locations, err := js.OptiiAPI.GetLocationsByIds(ctx, reqDto.LocationsID)
 		if err != nil {
+			span.SetStatus(codes.Error, err.Error())
 			errChan <- fmt.Errorf("location %w", err)
 			close(locationsChan)
 			return
@@ -102,17 +336,27 @@ func (js *JobService) LoadJob(reqDto *dto.JobRequestDto) (*domain.JobRequest, []
 	wg.Wait()
 	close(errChan)
 
-	errs := []error{}
+	errs = []error{}
 	for err := range errChan {
 		errs = append(errs, err)
 	}
 
 	// receiving all values from the channels to avoid memory leaks
-	jr := &domain.JobRequest{
+	jr = &domain.JobRequest{
 		Department: <-departmentChan,
 		JobItem:    <-jobItemChan,
 		Locations:  <-locationsChan,
+		Strategy:   domain.ExecutionStrategy(reqDto.Strategy),
 	}
 
 	return jr, errs
 }
+
+// execute executes the rule, or only plans its job in a context returned by tasks.WithDryRun.
+func execute(ctx context.Context, t tasks.JobTask, jobRequest domain.JobRequest) domain.JobResult {
+	if tasks.IsDryRun(ctx) {
+		return tasks.DryRun(ctx, t, jobRequest)
+	}
+
+	return t.Execute(ctx, jobRequest)
+}
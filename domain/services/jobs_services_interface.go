@@ -1,11 +1,16 @@
 package services
 
 import (
+	"context"
+
 	"github.com/Twsouza/job-rule-engine/application/dto"
 	"github.com/Twsouza/job-rule-engine/domain"
 )
 
 type JobServiceInterface interface {
-	CreateJob(jobRequest *domain.JobRequest) []domain.JobResult
-	LoadJob(dto *dto.JobRequestDto) (*domain.JobRequest, []error)
+	CreateJob(ctx context.Context, jobRequest *domain.JobRequest) []domain.JobResult
+	Explain(ctx context.Context, jobRequest *domain.JobRequest) *domain.Explanation
+	LoadJob(ctx context.Context, dto *dto.JobRequestDto) (*domain.JobRequest, []error)
+	LoadJobs(ctx context.Context, dtos []*dto.JobRequestDto) ([]*domain.JobRequest, [][]error)
+	MatchedRules(ctx context.Context, jobRequest *domain.JobRequest) []string
 }
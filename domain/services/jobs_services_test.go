@@ -1,16 +1,29 @@
 package services
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/Twsouza/job-rule-engine/application/dto"
 	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/logging"
 	servicesMock "github.com/Twsouza/job-rule-engine/domain/services/mock"
 	"github.com/Twsouza/job-rule-engine/domain/tasks"
 	"github.com/Twsouza/job-rule-engine/domain/tasks/mock"
 	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
 )
 
 func TestCreateJob(t *testing.T) {
@@ -20,7 +33,7 @@ func TestCreateJob(t *testing.T) {
 			AssertFunc: func(jobRequest domain.JobRequest) bool {
 				return jobRequest.Department.Name == "Engineering"
 			},
-			ExecuteFunc: func(jobRequest domain.JobRequest) domain.JobResult {
+			ExecuteFunc: func(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
 				return domain.JobResult{}
 			},
 		},
@@ -28,7 +41,7 @@ func TestCreateJob(t *testing.T) {
 			AssertFunc: func(jobRequest domain.JobRequest) bool {
 				return jobRequest.Department.Name == "Engineering"
 			},
-			ExecuteFunc: func(jobRequest domain.JobRequest) domain.JobResult {
+			ExecuteFunc: func(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
 				return domain.JobResult{}
 			},
 		},
@@ -36,7 +49,7 @@ func TestCreateJob(t *testing.T) {
 			AssertFunc: func(jobRequest domain.JobRequest) bool {
 				return jobRequest.Department.Name == "Housekeeping"
 			},
-			ExecuteFunc: func(jobRequest domain.JobRequest) domain.JobResult {
+			ExecuteFunc: func(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
 				return domain.JobResult{}
 			},
 		},
@@ -56,10 +69,63 @@ func TestCreateJob(t *testing.T) {
 
 	t.Run("should return no errors when all rules execute successfully", func(t *testing.T) {
 		// Call the CreateJob function
-		jr := jobService.CreateJob(jobRequest)
+		jr := jobService.CreateJob(context.Background(), jobRequest)
 		assert.Len(t, jr, 2)
-		assert.Empty(t, jr[0].Err)
-		assert.Empty(t, jr[1].Err)
+		assert.False(t, jr[0].Failed())
+		assert.False(t, jr[1].Failed())
+	})
+
+	t.Run("should set the outcome of the results without one", func(t *testing.T) {
+		js := &JobService{
+			Tasks: []tasks.JobTask{
+				&mock.MockRule{
+					AssertFunc: func(domain.JobRequest) bool { return true },
+					ExecuteFunc: func(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
+						return domain.JobResult{Error: &domain.JobError{Code: domain.ErrorRejected, Message: "rejected"}}
+					},
+				},
+			},
+		}
+
+		jr := js.CreateJob(context.Background(), jobRequest)
+		assert.Equal(t, domain.OutcomeFailed, jr[0].Outcome)
+		assert.Equal(t, domain.OutcomeCreated, jobService.CreateJob(context.Background(), jobRequest)[0].Outcome)
+	})
+
+	t.Run("should only plan the jobs without executing the rules on a dry run", func(t *testing.T) {
+		execute := func(context.Context, domain.JobRequest) domain.JobResult {
+			t.Error("the rule should not be executed on a dry run")
+			return domain.JobResult{}
+		}
+		js := &JobService{
+			Tasks: []tasks.JobTask{
+				&mock.MockExplainableRule{
+					MockRule: mock.MockRule{AssertFunc: func(domain.JobRequest) bool { return true }, ExecuteFunc: execute},
+					PlanFunc: func(context.Context, domain.JobRequest) (*domain.Job, error) {
+						return &domain.Job{Action: "repair"}, nil
+					},
+				},
+				&mock.MockRule{AssertFunc: func(domain.JobRequest) bool { return true }, ExecuteFunc: execute},
+			},
+		}
+
+		jr := js.CreateJob(tasks.WithDryRun(context.Background()), jobRequest)
+		assert.Len(t, jr, 2)
+		outcomes := map[domain.JobOutcome]*domain.JobResult{}
+		for i := range jr {
+			outcomes[jr[i].Outcome] = &jr[i]
+		}
+		assert.Equal(t, &domain.Job{Action: "repair"}, outcomes[domain.OutcomeDryRun].Job)
+		assert.Equal(t, domain.ErrorNoPlan, outcomes[domain.OutcomeSkipped].Error.Code)
+	})
+
+	t.Run("should set the rule name and the timing of the results", func(t *testing.T) {
+		before := time.Now()
+		jr := jobService.CreateJob(context.Background(), jobRequest)
+		assert.Equal(t, "MockRule", jr[0].Rule)
+		assert.False(t, jr[0].StartedAt.Before(before))
+		assert.False(t, jr[0].FinishedAt.Before(jr[0].StartedAt))
+		assert.GreaterOrEqual(t, jr[0].Duration(), time.Duration(0))
 	})
 
 	t.Run("should return an error when a rule fails", func(t *testing.T) {
@@ -67,17 +133,190 @@ func TestCreateJob(t *testing.T) {
 			AssertFunc: func(jobRequest domain.JobRequest) bool {
 				return jobRequest.Department.Name == "Engineering"
 			},
-			ExecuteFunc: func(jobRequest domain.JobRequest) domain.JobResult {
+			ExecuteFunc: func(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
 				return domain.JobResult{
-					Err: "failed to execute rule",
+					Outcome: domain.OutcomeFailed,
+					Error:   &domain.JobError{Code: domain.ErrorUnknown, Message: "failed to execute rule"},
 				}
 			},
 		})
 
 		// Call the CreateJob function
-		jr := jobService.CreateJob(jobRequest)
+		jr := jobService.CreateJob(context.Background(), jobRequest)
 		assert.Len(t, jr, 3)
-		assert.Contains(t, jr, domain.JobResult{Err: "failed to execute rule"})
+		failed := 0
+		for _, result := range jr {
+			if result.Failed() {
+				failed++
+				assert.Equal(t, "failed to execute rule", result.Error.Message)
+			}
+		}
+		assert.Equal(t, 1, failed)
+	})
+}
+
+func TestMatchRules(t *testing.T) {
+	engineering := func(jobRequest domain.JobRequest) bool {
+		return jobRequest.Department.Name == "Engineering"
+	}
+	location := &mock.MockRule{AssertFunc: engineering}
+	floor := &mock.MockRule{AssertFunc: engineering, PriorityValue: 1}
+	floorRooms := &mock.MockRule{AssertFunc: engineering, PriorityValue: 1}
+	housekeeping := &mock.MockRule{
+		AssertFunc: func(jobRequest domain.JobRequest) bool {
+			return jobRequest.Department.Name == "Housekeeping"
+		},
+		PriorityValue: 5,
+	}
+
+	jobService := &JobService{
+		Tasks: []tasks.JobTask{location, floor, floorRooms, housekeeping},
+	}
+
+	t.Run("should return every matching rule by default", func(t *testing.T) {
+		jobRequest := &domain.JobRequest{Department: &domain.Department{Name: "Engineering"}}
+
+		matched := jobService.MatchRules(context.Background(), jobRequest)
+		assert.Equal(t, []tasks.JobTask{location, floor, floorRooms}, matched)
+	})
+
+	t.Run("should return the first rule with the highest priority", func(t *testing.T) {
+		jobRequest := &domain.JobRequest{
+			Department: &domain.Department{Name: "Engineering"},
+			Strategy:   domain.StrategyFirstMatch,
+		}
+
+		matched := jobService.MatchRules(context.Background(), jobRequest)
+		assert.Equal(t, []tasks.JobTask{floor}, matched)
+	})
+
+	t.Run("should return every rule with the highest priority", func(t *testing.T) {
+		jobRequest := &domain.JobRequest{
+			Department: &domain.Department{Name: "Engineering"},
+			Strategy:   domain.StrategyHighestPriority,
+		}
+
+		matched := jobService.MatchRules(context.Background(), jobRequest)
+		assert.Equal(t, []tasks.JobTask{floor, floorRooms}, matched)
+	})
+
+	t.Run("should use the service strategy when the request has none", func(t *testing.T) {
+		js := &JobService{
+			Tasks:    jobService.Tasks,
+			Strategy: domain.StrategyFirstMatch,
+		}
+		jobRequest := &domain.JobRequest{Department: &domain.Department{Name: "Engineering"}}
+		assert.Equal(t, []tasks.JobTask{floor}, js.MatchRules(context.Background(), jobRequest))
+
+		jobRequest.Strategy = domain.StrategyAllMatching
+		assert.Equal(t, []tasks.JobTask{location, floor, floorRooms}, js.MatchRules(context.Background(), jobRequest))
+	})
+
+	t.Run("should return only the exclusive rule when it matches", func(t *testing.T) {
+		exclusive := &mock.MockRule{AssertFunc: engineering, ExclusiveValue: true}
+		higherExclusive := &mock.MockRule{AssertFunc: engineering, PriorityValue: 2, ExclusiveValue: true}
+		js := &JobService{
+			Tasks: []tasks.JobTask{location, exclusive, floor, higherExclusive},
+		}
+		jobRequest := &domain.JobRequest{Department: &domain.Department{Name: "Engineering"}}
+
+		matched := js.MatchRules(context.Background(), jobRequest)
+		assert.Equal(t, []tasks.JobTask{higherExclusive}, matched)
+	})
+
+	t.Run("should return the names of the matching rules", func(t *testing.T) {
+		jobRequest := &domain.JobRequest{Department: &domain.Department{Name: "Engineering"}}
+
+		assert.Equal(t, []string{"MockRule", "MockRule", "MockRule"}, jobService.MatchedRules(context.Background(), jobRequest))
+	})
+
+	t.Run("should return no rules when nothing matches", func(t *testing.T) {
+		jobRequest := &domain.JobRequest{
+			Department: &domain.Department{Name: "Room Service"},
+			Strategy:   domain.StrategyFirstMatch,
+		}
+
+		assert.Empty(t, jobService.MatchRules(context.Background(), jobRequest))
+	})
+}
+
+func TestExplain(t *testing.T) {
+	engineering := func(jobRequest domain.JobRequest) bool {
+		return jobRequest.Department.Name == "Engineering"
+	}
+	explain := func(jobRequest domain.JobRequest) []domain.ConditionTrace {
+		return []domain.ConditionTrace{{Condition: "department is Engineering", Passed: engineering(jobRequest)}}
+	}
+
+	floor := &mock.MockExplainableRule{
+		MockRule:    mock.MockRule{AssertFunc: engineering, PriorityValue: 1},
+		NameValue:   "Floor",
+		ExplainFunc: explain,
+		PlanFunc: func(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error) {
+			return &domain.Job{Action: "repair"}, nil
+		},
+	}
+	location := &mock.MockExplainableRule{
+		MockRule:    mock.MockRule{AssertFunc: engineering},
+		NameValue:   "Location",
+		ExplainFunc: explain,
+		PlanFunc: func(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error) {
+			return nil, errors.New("no locations found for this job")
+		},
+	}
+	housekeeping := &mock.MockRule{
+		AssertFunc: func(jobRequest domain.JobRequest) bool {
+			return jobRequest.Department.Name == "Housekeeping"
+		},
+	}
+
+	jobService := &JobService{
+		Tasks: []tasks.JobTask{floor, location, housekeeping},
+	}
+
+	t.Run("should explain every rule and plan the selected ones", func(t *testing.T) {
+		jobRequest := &domain.JobRequest{Department: &domain.Department{Name: "Engineering"}}
+
+		expected := &domain.Explanation{
+			Request:  jobRequest,
+			Strategy: domain.StrategyAllMatching,
+			Rules: []domain.RuleTrace{
+				{
+					Rule:       "Floor",
+					Priority:   1,
+					Matched:    true,
+					Selected:   true,
+					Conditions: []domain.ConditionTrace{{Condition: "department is Engineering", Passed: true}},
+					Job:        &domain.Job{Action: "repair"},
+				},
+				{
+					Rule:       "Location",
+					Matched:    true,
+					Selected:   true,
+					Conditions: []domain.ConditionTrace{{Condition: "department is Engineering", Passed: true}},
+					Err:        "no locations found for this job",
+				},
+				{
+					Rule: "MockRule",
+				},
+			},
+		}
+
+		assert.Equal(t, expected, jobService.Explain(context.Background(), jobRequest))
+	})
+
+	t.Run("should not plan the rules discarded by the strategy", func(t *testing.T) {
+		jobRequest := &domain.JobRequest{
+			Department: &domain.Department{Name: "Engineering"},
+			Strategy:   domain.StrategyFirstMatch,
+		}
+
+		explanation := jobService.Explain(context.Background(), jobRequest)
+		assert.Equal(t, domain.StrategyFirstMatch, explanation.Strategy)
+		assert.True(t, explanation.Rules[0].Selected)
+		assert.True(t, explanation.Rules[1].Matched)
+		assert.False(t, explanation.Rules[1].Selected)
+		assert.Empty(t, explanation.Rules[1].Err)
 	})
 }
 
@@ -120,17 +359,17 @@ func TestLoadJob(t *testing.T) {
 	}
 
 	t.Run("should load job request successfully", func(t *testing.T) {
-		optiiAPIMock.GetDepartmentByIDFunc = func(id int64) (*domain.Department, error) {
+		optiiAPIMock.GetDepartmentByIDFunc = func(ctx context.Context, id int64) (*domain.Department, error) {
 			assert.Equal(t, reqDto.DepartmentID, id)
 			return department, nil
 		}
 
-		optiiAPIMock.GetJobItemByIDFunc = func(id int64) (*domain.JobItem, error) {
+		optiiAPIMock.GetJobItemByIDFunc = func(ctx context.Context, id int64) (*domain.JobItem, error) {
 			assert.Equal(t, reqDto.JobItemID, id)
 			return jobItem, nil
 		}
 
-		optiiAPIMock.GetLocationsByIdsFunc = func(ids []int64) ([]domain.Location, error) {
+		optiiAPIMock.GetLocationsByIdsFunc = func(ctx context.Context, ids []int64) ([]domain.Location, error) {
 			assert.Equal(t, reqDto.LocationsID, ids)
 			return locations, nil
 		}
@@ -140,7 +379,7 @@ func TestLoadJob(t *testing.T) {
 			Locations:  locations,
 		}
 
-		jr, err := jobService.LoadJob(reqDto)
+		jr, err := jobService.LoadJob(context.Background(), reqDto)
 		assert.Len(t, err, 0)
 		assert.Equal(t, expectedJobRequest, jr)
 	})
@@ -149,22 +388,22 @@ func TestLoadJob(t *testing.T) {
 		departmentError := errors.New("failed to get department")
 		expectedError := []error{fmt.Errorf("department %w", departmentError)}
 
-		optiiAPIMock.GetDepartmentByIDFunc = func(id int64) (*domain.Department, error) {
+		optiiAPIMock.GetDepartmentByIDFunc = func(ctx context.Context, id int64) (*domain.Department, error) {
 			assert.Equal(t, reqDto.DepartmentID, id)
 			return nil, departmentError
 		}
 
-		optiiAPIMock.GetJobItemByIDFunc = func(id int64) (*domain.JobItem, error) {
+		optiiAPIMock.GetJobItemByIDFunc = func(ctx context.Context, id int64) (*domain.JobItem, error) {
 			assert.Equal(t, reqDto.JobItemID, id)
 			return jobItem, nil
 		}
 
-		optiiAPIMock.GetLocationsByIdsFunc = func(ids []int64) ([]domain.Location, error) {
+		optiiAPIMock.GetLocationsByIdsFunc = func(ctx context.Context, ids []int64) ([]domain.Location, error) {
 			assert.Equal(t, reqDto.LocationsID, ids)
 			return locations, nil
 		}
 
-		jr, err := jobService.LoadJob(reqDto)
+		jr, err := jobService.LoadJob(context.Background(), reqDto)
 		assert.Len(t, err, 1)
 		assert.Error(t, err[0])
 		assert.Nil(t, jr.Department)
@@ -175,22 +414,22 @@ func TestLoadJob(t *testing.T) {
 		jobItemError := errors.New("failed to get job item")
 		expectedError := []error{fmt.Errorf("jobItem %w", jobItemError)}
 
-		optiiAPIMock.GetDepartmentByIDFunc = func(id int64) (*domain.Department, error) {
+		optiiAPIMock.GetDepartmentByIDFunc = func(ctx context.Context, id int64) (*domain.Department, error) {
 			assert.Equal(t, reqDto.DepartmentID, id)
 			return department, nil
 		}
 
-		optiiAPIMock.GetJobItemByIDFunc = func(id int64) (*domain.JobItem, error) {
+		optiiAPIMock.GetJobItemByIDFunc = func(ctx context.Context, id int64) (*domain.JobItem, error) {
 			assert.Equal(t, reqDto.JobItemID, id)
 			return nil, jobItemError
 		}
 
-		optiiAPIMock.GetLocationsByIdsFunc = func(ids []int64) ([]domain.Location, error) {
+		optiiAPIMock.GetLocationsByIdsFunc = func(ctx context.Context, ids []int64) ([]domain.Location, error) {
 			assert.Equal(t, reqDto.LocationsID, ids)
 			return locations, nil
 		}
 
-		jr, err := jobService.LoadJob(reqDto)
+		jr, err := jobService.LoadJob(context.Background(), reqDto)
 		assert.Len(t, err, 1)
 		assert.Error(t, err[0])
 		assert.Nil(t, jr.JobItem)
@@ -201,25 +440,192 @@ func TestLoadJob(t *testing.T) {
 		getLocationsError := errors.New("failed to get locations")
 		expectedError := []error{fmt.Errorf("location %w", getLocationsError)}
 
-		optiiAPIMock.GetDepartmentByIDFunc = func(id int64) (*domain.Department, error) {
+		optiiAPIMock.GetDepartmentByIDFunc = func(ctx context.Context, id int64) (*domain.Department, error) {
 			assert.Equal(t, reqDto.DepartmentID, id)
 			return department, nil
 		}
 
-		optiiAPIMock.GetJobItemByIDFunc = func(id int64) (*domain.JobItem, error) {
+		optiiAPIMock.GetJobItemByIDFunc = func(ctx context.Context, id int64) (*domain.JobItem, error) {
 			assert.Equal(t, reqDto.JobItemID, id)
 			return jobItem, nil
 		}
 
-		optiiAPIMock.GetLocationsByIdsFunc = func(ids []int64) ([]domain.Location, error) {
+		optiiAPIMock.GetLocationsByIdsFunc = func(ctx context.Context, ids []int64) ([]domain.Location, error) {
 			assert.Equal(t, reqDto.LocationsID, ids)
 			return nil, getLocationsError
 		}
 
-		jr, err := jobService.LoadJob(reqDto)
+		jr, err := jobService.LoadJob(context.Background(), reqDto)
 		assert.Len(t, err, 1)
 		assert.Error(t, err[0])
 		assert.Len(t, jr.Locations, 0)
 		assert.Equal(t, expectedError, err)
 	})
 }
+
+func TestJobServiceMetrics(t *testing.T) {
+	mu := sync.Mutex{}
+	observed := []string{}
+	observe := func(format string, args ...interface{}) {
+		mu.Lock()
+		defer mu.Unlock()
+		observed = append(observed, fmt.Sprintf(format, args...))
+	}
+
+	metrics := &servicesMock.MetricsMock{
+		ObserveLoadJobFunc: func(duration time.Duration, failed bool) {
+			observe("load failed=%t", failed)
+		},
+		ObserveRuleMatchFunc: func(rule string) {
+			observe("match %s", rule)
+		},
+		ObserveExecuteFunc: func(rule string, duration time.Duration, failed bool) {
+			observe("execute %s failed=%t", rule, failed)
+		},
+	}
+
+	t.Run("should record the matched rules and their execution", func(t *testing.T) {
+		observed = []string{}
+		jobService := &JobService{
+			Tasks: []tasks.JobTask{
+				&mock.MockRule{
+					AssertFunc: func(jobRequest domain.JobRequest) bool {
+						return true
+					},
+					ExecuteFunc: func(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
+						return domain.JobResult{Outcome: domain.OutcomeFailed, Error: &domain.JobError{Message: "failed to execute rule"}}
+					},
+				},
+			},
+			Metrics: metrics,
+		}
+
+		jobService.CreateJob(context.Background(), &domain.JobRequest{})
+
+		assert.Equal(t, []string{"match MockRule", "execute MockRule failed=true"}, observed)
+	})
+
+	t.Run("should record the loading of the job request", func(t *testing.T) {
+		observed = []string{}
+		jobService := &JobService{
+			OptiiAPI: &servicesMock.OptiiApiMock{
+				GetDepartmentByIDFunc: func(ctx context.Context, id int64) (*domain.Department, error) {
+					return nil, errors.New("not found")
+				},
+				GetJobItemByIDFunc: func(ctx context.Context, id int64) (*domain.JobItem, error) {
+					return &domain.JobItem{}, nil
+				},
+				GetLocationsByIdsFunc: func(ctx context.Context, id []int64) ([]domain.Location, error) {
+					return []domain.Location{}, nil
+				},
+			},
+			Metrics: metrics,
+		}
+
+		_, errs := jobService.LoadJob(context.Background(), &dto.JobRequestDto{})
+
+		assert.Len(t, errs, 1)
+		assert.Equal(t, []string{"load failed=true"}, observed)
+	})
+}
+
+func TestJobServiceTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	spans := func() map[string]sdktrace.ReadOnlySpan {
+		byName := map[string]sdktrace.ReadOnlySpan{}
+		for _, span := range recorder.Ended() {
+			byName[span.Name()] = span
+		}
+		return byName
+	}
+
+	t.Run("should trace the assertion and the execution of the rules under the request span", func(t *testing.T) {
+		jobService := &JobService{
+			Tasks: []tasks.JobTask{
+				&mock.MockRule{
+					AssertFunc: func(jobRequest domain.JobRequest) bool {
+						return true
+					},
+					ExecuteFunc: func(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
+						return domain.JobResult{Outcome: domain.OutcomeFailed, Error: &domain.JobError{Message: "failed to execute rule"}}
+					},
+				},
+			},
+		}
+
+		ctx, parent := provider.Tracer("test").Start(context.Background(), "request")
+		jobService.CreateJob(ctx, &domain.JobRequest{})
+		parent.End()
+
+		byName := spans()
+		assert.Contains(t, byName, "AssertRule")
+		assert.Contains(t, byName, "Execute")
+		execute := byName["Execute"]
+		assert.Equal(t, parent.SpanContext().SpanID(), execute.Parent().SpanID())
+		assert.Equal(t, codes.Error, execute.Status().Code)
+		assert.Contains(t, execute.Attributes(), attribute.String("rule", "MockRule"))
+		assert.Contains(t, byName["AssertRule"].Attributes(), attribute.Bool("matched", true))
+	})
+
+	t.Run("should trace each call loading the job request", func(t *testing.T) {
+		jobService := &JobService{
+			OptiiAPI: &servicesMock.OptiiApiMock{
+				GetDepartmentByIDFunc: func(ctx context.Context, id int64) (*domain.Department, error) {
+					return nil, errors.New("not found")
+				},
+				GetJobItemByIDFunc: func(ctx context.Context, id int64) (*domain.JobItem, error) {
+					return &domain.JobItem{}, nil
+				},
+				GetLocationsByIdsFunc: func(ctx context.Context, id []int64) ([]domain.Location, error) {
+					return []domain.Location{}, nil
+				},
+			},
+		}
+
+		jobService.LoadJob(context.Background(), &dto.JobRequestDto{})
+
+		byName := spans()
+		assert.Equal(t, codes.Error, byName["Load department"].Status().Code)
+		assert.Equal(t, codes.Unset, byName["Load jobItem"].Status().Code)
+		assert.Contains(t, byName, "Load locations")
+	})
+}
+
+func TestJobServiceLogging(t *testing.T) {
+	t.Run("should log the failed rules with the rule name and the context attributes", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		jobService := &JobService{
+			Tasks: []tasks.JobTask{
+				&mock.MockRule{
+					AssertFunc: func(jobRequest domain.JobRequest) bool {
+						return true
+					},
+					ExecuteFunc: func(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
+						logging.FromContext(ctx).InfoContext(ctx, "from the rule")
+						return domain.JobResult{Outcome: domain.OutcomeFailed, Error: &domain.JobError{Message: "failed to execute rule"}}
+					},
+				},
+			},
+			Logger: slog.New(logging.NewHandler(slog.NewJSONHandler(out, nil))),
+		}
+
+		ctx := logging.WithAttrs(context.Background(), slog.String(logging.RequestIDKey, "abc"))
+		jobService.CreateJob(ctx, &domain.JobRequest{})
+
+		lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+		assert.Len(t, lines, 2)
+		for _, line := range lines {
+			record := map[string]interface{}{}
+			assert.NoError(t, json.Unmarshal([]byte(line), &record))
+			assert.Equal(t, "abc", record[logging.RequestIDKey])
+			assert.Equal(t, "MockRule", record[logging.RuleKey])
+		}
+		assert.Contains(t, lines[1], `"level":"ERROR","msg":"rule failed"`)
+		assert.Contains(t, lines[1], `"error":"failed to execute rule"`)
+	})
+}
@@ -0,0 +1,21 @@
+package services
+
+import "time"
+
+type MetricsInterface interface {
+	// ObserveLoadJob records the duration of LoadJob, and whether it failed.
+	ObserveLoadJob(duration time.Duration, failed bool)
+	// ObserveRuleMatch records a rule selected to be executed.
+	ObserveRuleMatch(rule string)
+	// ObserveExecute records the duration of the execution of a rule, and whether it failed.
+	ObserveExecute(rule string, duration time.Duration, failed bool)
+}
+
+// noMetrics is the MetricsInterface used when the service has no metrics.
+type noMetrics struct{}
+
+func (noMetrics) ObserveLoadJob(duration time.Duration, failed bool) {}
+
+func (noMetrics) ObserveRuleMatch(rule string) {}
+
+func (noMetrics) ObserveExecute(rule string, duration time.Duration, failed bool) {}
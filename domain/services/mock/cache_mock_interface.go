@@ -0,0 +1,18 @@
+package mock
+
+import (
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+type CacheMock struct {
+	StatsFunc      func() []domain.CacheStats
+	InvalidateFunc func(kind string, ids ...int64) error
+}
+
+func (m *CacheMock) Stats() []domain.CacheStats {
+	return m.StatsFunc()
+}
+
+func (m *CacheMock) Invalidate(kind string, ids ...int64) error {
+	return m.InvalidateFunc(kind, ids...)
+}
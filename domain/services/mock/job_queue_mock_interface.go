@@ -0,0 +1,21 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/Twsouza/job-rule-engine/application/dto"
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+type JobQueueMock struct {
+	SubmitFunc func(ctx context.Context, reqDto *dto.JobRequestDto) (*domain.AsyncRequest, error)
+	GetFunc    func(id string) (*domain.AsyncRequest, bool)
+}
+
+func (m *JobQueueMock) Submit(ctx context.Context, reqDto *dto.JobRequestDto) (*domain.AsyncRequest, error) {
+	return m.SubmitFunc(ctx, reqDto)
+}
+
+func (m *JobQueueMock) Get(id string) (*domain.AsyncRequest, bool) {
+	return m.GetFunc(id)
+}
@@ -1,19 +1,40 @@
 package mock
 
 import (
+	"context"
+
 	"github.com/Twsouza/job-rule-engine/application/dto"
 	"github.com/Twsouza/job-rule-engine/domain"
 )
 
 type JobServiceMock struct {
-	CreateJobFunc func(jobRequest *domain.JobRequest) []domain.JobResult
-	LoadJobFunc   func(dto *dto.JobRequestDto) (*domain.JobRequest, []error)
+	CreateJobFunc func(ctx context.Context, jobRequest *domain.JobRequest) []domain.JobResult
+	ExplainFunc   func(ctx context.Context, jobRequest *domain.JobRequest) *domain.Explanation
+	LoadJobFunc   func(ctx context.Context, dto *dto.JobRequestDto) (*domain.JobRequest, []error)
+	LoadJobsFunc  func(ctx context.Context, dtos []*dto.JobRequestDto) ([]*domain.JobRequest, [][]error)
+	// MatchedRulesFunc is optional, no rules are returned when it's nil.
+	MatchedRulesFunc func(ctx context.Context, jobRequest *domain.JobRequest) []string
+}
+
+func (m *JobServiceMock) CreateJob(ctx context.Context, jobRequest *domain.JobRequest) []domain.JobResult {
+	return m.CreateJobFunc(ctx, jobRequest)
+}
+
+func (m *JobServiceMock) Explain(ctx context.Context, jobRequest *domain.JobRequest) *domain.Explanation {
+	return m.ExplainFunc(ctx, jobRequest)
+}
+
+func (m *JobServiceMock) LoadJob(ctx context.Context, dto *dto.JobRequestDto) (*domain.JobRequest, []error) {
+	return m.LoadJobFunc(ctx, dto)
 }
 
-func (m *JobServiceMock) CreateJob(jobRequest *domain.JobRequest) []domain.JobResult {
-	return m.CreateJobFunc(jobRequest)
+func (m *JobServiceMock) LoadJobs(ctx context.Context, dtos []*dto.JobRequestDto) ([]*domain.JobRequest, [][]error) {
+	return m.LoadJobsFunc(ctx, dtos)
 }
 
-func (m *JobServiceMock) LoadJob(dto *dto.JobRequestDto) (*domain.JobRequest, []error) {
-	return m.LoadJobFunc(dto)
+func (m *JobServiceMock) MatchedRules(ctx context.Context, jobRequest *domain.JobRequest) []string {
+	if m.MatchedRulesFunc == nil {
+		return nil
+	}
+	return m.MatchedRulesFunc(ctx, jobRequest)
 }
@@ -0,0 +1,21 @@
+package mock
+
+import "time"
+
+type MetricsMock struct {
+	ObserveLoadJobFunc   func(duration time.Duration, failed bool)
+	ObserveRuleMatchFunc func(rule string)
+	ObserveExecuteFunc   func(rule string, duration time.Duration, failed bool)
+}
+
+func (m *MetricsMock) ObserveLoadJob(duration time.Duration, failed bool) {
+	m.ObserveLoadJobFunc(duration, failed)
+}
+
+func (m *MetricsMock) ObserveRuleMatch(rule string) {
+	m.ObserveRuleMatchFunc(rule)
+}
+
+func (m *MetricsMock) ObserveExecute(rule string, duration time.Duration, failed bool) {
+	m.ObserveExecuteFunc(rule, duration, failed)
+}
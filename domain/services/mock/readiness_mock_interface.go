@@ -0,0 +1,15 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+type ReadinessMock struct {
+	ReadyFunc func(ctx context.Context) *domain.Readiness
+}
+
+func (m *ReadinessMock) Ready(ctx context.Context) *domain.Readiness {
+	return m.ReadyFunc(ctx)
+}
@@ -1,9 +1,57 @@
 package services
 
-import "github.com/Twsouza/job-rule-engine/domain"
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+// The errors of the Optii API are declared in domain, to be classified by the rules, see domain.ErrNotFound.
+var (
+	ErrNotFound = domain.ErrNotFound
+	ErrUpstream = domain.ErrUpstream
+)
 
 type OptiiApiInterface interface {
-	GetDepartmentByID(id int64) (*domain.Department, error)
-	GetJobItemByID(id int64) (*domain.JobItem, error)
-	GetLocationsByIds(id []int64) ([]domain.Location, error)
+	GetDepartmentByID(ctx context.Context, id int64) (*domain.Department, error)
+	GetJobItemByID(ctx context.Context, id int64) (*domain.JobItem, error)
+	GetLocationsByIds(ctx context.Context, id []int64) ([]domain.Location, error)
+}
+
+// LocationErrors is returned by GetLocationsByIds, along with the locations which were loaded,
+// when some of the locations couldn't be loaded. It maps the ID of each of these locations to its error.
+// It matches, with errors.Is and errors.As, any of these errors.
+type LocationErrors map[int64]error
+
+// IDs returns the IDs of the locations which couldn't be loaded, in ascending order.
+func (e LocationErrors) IDs() []int64 {
+	ids := make([]int64, 0, len(e))
+	for id := range e {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	return ids
+}
+
+func (e LocationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, id := range e.IDs() {
+		msgs = append(msgs, fmt.Sprintf("%d: %s", id, e[id]))
+	}
+
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the errors of the locations, ordered by ID.
+func (e LocationErrors) Unwrap() []error {
+	errs := make([]error, 0, len(e))
+	for _, id := range e.IDs() {
+		errs = append(errs, e[id])
+	}
+
+	return errs
 }
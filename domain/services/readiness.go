@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+// defaultReadinessTimeout is the deadline of the readiness checks when Readiness.Timeout is not set.
+const defaultReadinessTimeout = 5 * time.Second
+
+// ReadinessCheck checks a dependency of the server, Check returns nil when it's ready.
+type ReadinessCheck struct {
+	Name  string
+	Check func(ctx context.Context) error
+}
+
+// Readiness runs the readiness checks of the server.
+type Readiness struct {
+	Checks []ReadinessCheck
+	// Timeout is the deadline of the checks, defaultReadinessTimeout when zero.
+	Timeout time.Duration
+}
+
+// NewReadiness creates the readiness of the server with the given checks.
+func NewReadiness(checks ...ReadinessCheck) *Readiness {
+	return &Readiness{Checks: checks}
+}
+
+// Ready runs every check concurrently and returns their results in the order of the checks.
+// A check which doesn't return before the Timeout is not ready.
+func (r *Readiness) Ready(ctx context.Context) *domain.Readiness {
+	timeout := r.Timeout
+	if timeout <= 0 {
+		timeout = defaultReadinessTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	readiness := &domain.Readiness{
+		Ready:  true,
+		Checks: make([]domain.CheckResult, len(r.Checks)),
+	}
+
+	wg := sync.WaitGroup{}
+	for i, check := range r.Checks {
+		wg.Add(1)
+		go func(result *domain.CheckResult, check ReadinessCheck) {
+			defer wg.Done()
+			start := time.Now()
+
+			// The check may not honor the context, its result is ignored once the deadline is exceeded
+			errCh := make(chan error, 1)
+			go func() {
+				errCh <- check.Check(ctx)
+			}()
+
+			var err error
+			select {
+			case err = <-errCh:
+			case <-ctx.Done():
+				err = ctx.Err()
+			}
+
+			result.Name = check.Name
+			result.Ready = err == nil
+			if err != nil {
+				result.Error = err.Error()
+			}
+			result.Duration = time.Since(start).Round(time.Millisecond).String()
+		}(&readiness.Checks[i], check)
+	}
+	wg.Wait()
+
+	for _, result := range readiness.Checks {
+		if !result.Ready {
+			readiness.Ready = false
+		}
+	}
+
+	return readiness
+}
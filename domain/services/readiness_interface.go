@@ -0,0 +1,11 @@
+package services
+
+import (
+	"context"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+type ReadinessInterface interface {
+	Ready(ctx context.Context) *domain.Readiness
+}
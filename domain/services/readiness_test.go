@@ -0,0 +1,55 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadiness(t *testing.T) {
+	ok := ReadinessCheck{Name: "optii", Check: func(ctx context.Context) error { return nil }}
+
+	t.Run("should be ready when every check is", func(t *testing.T) {
+		readiness := NewReadiness(ok, ok).Ready(context.Background())
+
+		assert.True(t, readiness.Ready)
+		assert.Len(t, readiness.Checks, 2)
+		assert.True(t, readiness.Checks[1].Ready)
+	})
+
+	t.Run("should report the failed checks in the order of the checks", func(t *testing.T) {
+		failed := ReadinessCheck{Name: "locationIndex", Check: func(ctx context.Context) error {
+			return errors.New("location index not loaded")
+		}}
+
+		readiness := NewReadiness(ok, failed).Ready(context.Background())
+
+		assert.False(t, readiness.Ready)
+		assert.Equal(t, "optii", readiness.Checks[0].Name)
+		assert.Equal(t, domain.CheckResult{
+			Name:     "locationIndex",
+			Error:    "location index not loaded",
+			Duration: readiness.Checks[1].Duration,
+		}, readiness.Checks[1])
+	})
+
+	t.Run("should not wait for a check after the timeout", func(t *testing.T) {
+		release := make(chan struct{})
+		defer close(release)
+		blocked := ReadinessCheck{Name: "optii", Check: func(ctx context.Context) error {
+			<-release
+			return nil
+		}}
+
+		r := NewReadiness(blocked)
+		r.Timeout = 10 * time.Millisecond
+		readiness := r.Ready(context.Background())
+
+		assert.False(t, readiness.Ready)
+		assert.Equal(t, context.DeadlineExceeded.Error(), readiness.Checks[0].Error)
+	})
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/application/dto"
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/repository"
+)
+
+// ExecuteRequest loads the input of the record and executes the matching rules with the service,
+// filling the record with the loaded request, the matched rules, the results and the errors.
+// It returns the errors loading the request, in which case no rule is executed.
+func ExecuteRequest(ctx context.Context, js JobServiceInterface, record *repository.RequestRecord) []error {
+	jobReq, errs := js.LoadJob(ctx, record.Input)
+
+	return executeLoaded(ctx, js, record, jobReq, errs)
+}
+
+// ExecuteBatch executes the records like ExecuteRequest, with at most concurrency requests executed at once.
+// The requests of the batch are loaded together, see JobServiceInterface.LoadJobs.
+// It returns the errors loading each request, in the order of the records.
+func ExecuteBatch(ctx context.Context, js JobServiceInterface, records []*repository.RequestRecord, concurrency int) [][]error {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	inputs := make([]*dto.JobRequestDto, len(records))
+	for i, record := range records {
+		inputs[i] = record.Input
+	}
+	jobReqs, errs := js.LoadJobs(ctx, inputs)
+
+	sem := make(chan struct{}, concurrency)
+	wg := sync.WaitGroup{}
+	for i, record := range records {
+		wg.Add(1)
+		go func(i int, record *repository.RequestRecord) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			executeLoaded(ctx, js, record, jobReqs[i], errs[i])
+		}(i, record)
+	}
+	wg.Wait()
+
+	return errs
+}
+
+// executeLoaded executes the matching rules of the loaded job request, unless there are errors loading it.
+func executeLoaded(ctx context.Context, js JobServiceInterface, record *repository.RequestRecord, jobReq *domain.JobRequest, errs []error) []error {
+	defer func() {
+		record.FinishedAt = time.Now()
+	}()
+
+	if len(errs) > 0 {
+		for _, err := range errs {
+			record.Errors = append(record.Errors, err.Error())
+		}
+		return errs
+	}
+	record.Request = jobReq
+
+	record.MatchedRules = js.MatchedRules(ctx, jobReq)
+	record.Results = js.CreateJob(ctx, jobReq)
+	if len(record.Results) == 0 {
+		record.Errors = append(record.Errors, ErrNoRulesMatched.Error())
+	}
+
+	return nil
+}
+
+// SaveRecord stores the record in the repository, when set.
+// The record is saved even if the request was cancelled, failures are only reported in the output
+// since the request has already been executed.
+func SaveRecord(repo repository.RequestRepositoryInterface, record *repository.RequestRecord) {
+	if repo == nil {
+		return
+	}
+
+	if err := repo.Save(context.Background(), record); err != nil {
+		slog.Error("error saving request", "id", record.ID, "error", err)
+	}
+}
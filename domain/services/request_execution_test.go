@@ -0,0 +1,146 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/application/dto"
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/repository"
+	repositoryMock "github.com/Twsouza/job-rule-engine/domain/repository/mock"
+	servicesMock "github.com/Twsouza/job-rule-engine/domain/services/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExecuteRequest(t *testing.T) {
+	input := &dto.JobRequestDto{DepartmentID: 1, JobItemID: 2, LocationsID: []int64{3}}
+	jobRequest := &domain.JobRequest{Department: &domain.Department{ID: 1, Name: "Engineering"}}
+
+	t.Run("should record the matched rules and their results", func(t *testing.T) {
+		js := &servicesMock.JobServiceMock{
+			LoadJobFunc: func(ctx context.Context, d *dto.JobRequestDto) (*domain.JobRequest, []error) {
+				return jobRequest, nil
+			},
+			MatchedRulesFunc: func(ctx context.Context, jr *domain.JobRequest) []string {
+				return []string{"RepairJobItemLocation"}
+			},
+			CreateJobFunc: func(ctx context.Context, jr *domain.JobRequest) []domain.JobResult {
+				return []domain.JobResult{{Request: jr, Outcome: domain.OutcomeFailed, Error: &domain.JobError{Message: "failed to create job"}}}
+			},
+		}
+
+		record := repository.NewRequestRecord("1", input)
+		errs := ExecuteRequest(context.Background(), js, record)
+		assert.Empty(t, errs)
+		assert.Equal(t, jobRequest, record.Request)
+		assert.Equal(t, []string{"RepairJobItemLocation"}, record.MatchedRules)
+		assert.Equal(t, []domain.JobResult{{Request: jobRequest, Outcome: domain.OutcomeFailed, Error: &domain.JobError{Message: "failed to create job"}}}, record.Results)
+		assert.Empty(t, record.Errors)
+		assert.True(t, record.Failed())
+		assert.False(t, record.FinishedAt.IsZero())
+	})
+
+	t.Run("should record the errors loading the request", func(t *testing.T) {
+		js := &servicesMock.JobServiceMock{
+			LoadJobFunc: func(ctx context.Context, d *dto.JobRequestDto) (*domain.JobRequest, []error) {
+				return &domain.JobRequest{}, []error{errors.New("department not found")}
+			},
+		}
+
+		record := repository.NewRequestRecord("1", input)
+		errs := ExecuteRequest(context.Background(), js, record)
+		assert.Len(t, errs, 1)
+		assert.Nil(t, record.Request)
+		assert.Equal(t, []string{"department not found"}, record.Errors)
+	})
+
+	t.Run("should record when no rules matched", func(t *testing.T) {
+		js := &servicesMock.JobServiceMock{
+			LoadJobFunc: func(ctx context.Context, d *dto.JobRequestDto) (*domain.JobRequest, []error) {
+				return jobRequest, nil
+			},
+			CreateJobFunc: func(ctx context.Context, jr *domain.JobRequest) []domain.JobResult {
+				return nil
+			},
+		}
+
+		record := repository.NewRequestRecord("1", input)
+		ExecuteRequest(context.Background(), js, record)
+		assert.Equal(t, []string{ErrNoRulesMatched.Error()}, record.Errors)
+	})
+}
+
+func TestSaveRecord(t *testing.T) {
+	t.Run("should save the record in the repository", func(t *testing.T) {
+		record := repository.NewRequestRecord("1", &dto.JobRequestDto{})
+		saved := false
+		repo := &repositoryMock.RequestRepositoryMock{
+			SaveFunc: func(ctx context.Context, r *repository.RequestRecord) error {
+				assert.Same(t, record, r)
+				saved = true
+				return nil
+			},
+		}
+
+		SaveRecord(repo, record)
+		assert.True(t, saved)
+	})
+
+	t.Run("should ignore a nil repository", func(t *testing.T) {
+		SaveRecord(nil, repository.NewRequestRecord("1", &dto.JobRequestDto{}))
+	})
+}
+
+func TestExecuteBatch(t *testing.T) {
+	t.Run("should execute the loaded requests with bounded concurrency", func(t *testing.T) {
+		mu := sync.Mutex{}
+		running, maxRunning := 0, 0
+
+		js := &servicesMock.JobServiceMock{
+			LoadJobsFunc: func(ctx context.Context, dtos []*dto.JobRequestDto) ([]*domain.JobRequest, [][]error) {
+				jobReqs := make([]*domain.JobRequest, len(dtos))
+				errs := make([][]error, len(dtos))
+				for i, d := range dtos {
+					jobReqs[i] = &domain.JobRequest{Department: &domain.Department{ID: int(d.DepartmentID)}}
+				}
+				errs[1] = []error{errors.New("department not found")}
+				return jobReqs, errs
+			},
+			CreateJobFunc: func(ctx context.Context, jr *domain.JobRequest) []domain.JobResult {
+				mu.Lock()
+				running++
+				if running > maxRunning {
+					maxRunning = running
+				}
+				mu.Unlock()
+
+				time.Sleep(10 * time.Millisecond)
+
+				mu.Lock()
+				running--
+				mu.Unlock()
+				return []domain.JobResult{{Request: jr}}
+			},
+		}
+
+		records := []*repository.RequestRecord{}
+		for i := 1; i <= 5; i++ {
+			records = append(records, repository.NewRequestRecord(fmt.Sprint(i), &dto.JobRequestDto{DepartmentID: int64(i)}))
+		}
+
+		errs := ExecuteBatch(context.Background(), js, records, 2)
+		assert.Len(t, errs, 5)
+		assert.LessOrEqual(t, maxRunning, 2)
+
+		assert.Equal(t, []string{"department not found"}, records[1].Errors)
+		assert.Empty(t, records[1].Results)
+		for _, i := range []int{0, 2, 3, 4} {
+			assert.Empty(t, records[i].Errors)
+			assert.Equal(t, i+1, records[i].Results[0].Request.Department.ID)
+		}
+	})
+}
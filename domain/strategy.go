@@ -0,0 +1,24 @@
+package domain
+
+// ExecutionStrategy defines which of the matching rules are executed for a job request.
+type ExecutionStrategy string
+
+const (
+	// StrategyAllMatching executes every matching rule.
+	StrategyAllMatching ExecutionStrategy = "allMatching"
+	// StrategyFirstMatch executes only the matching rule with the highest priority,
+	// the first registered rule wins when priorities are equal.
+	StrategyFirstMatch ExecutionStrategy = "firstMatch"
+	// StrategyHighestPriority executes every matching rule sharing the highest priority.
+	StrategyHighestPriority ExecutionStrategy = "highestPriority"
+)
+
+// IsValid returns true if the strategy is known, an empty strategy is valid and means the default one.
+func (s ExecutionStrategy) IsValid() bool {
+	switch s {
+	case "", StrategyAllMatching, StrategyFirstMatch, StrategyHighestPriority:
+		return true
+	}
+
+	return false
+}
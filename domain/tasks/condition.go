@@ -0,0 +1,125 @@
+package tasks
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+// Condition is a named check of a job request.
+// The description is used to explain why a rule matched or not.
+type Condition struct {
+	Description string
+	Check       func(jobRequest domain.JobRequest) bool
+}
+
+// Conditions are satisfied when every condition is satisfied.
+type Conditions []Condition
+
+// Assert returns true if every condition is satisfied, it stops at the first unsatisfied condition.
+func (cs Conditions) Assert(jobRequest domain.JobRequest) bool {
+	for _, c := range cs {
+		if !c.Check(jobRequest) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Explain evaluates every condition and returns their outcome.
+func (cs Conditions) Explain(jobRequest domain.JobRequest) []domain.ConditionTrace {
+	traces := make([]domain.ConditionTrace, 0, len(cs))
+	for _, c := range cs {
+		traces = append(traces, domain.ConditionTrace{
+			Condition: c.Description,
+			Passed:    c.Check(jobRequest),
+		})
+	}
+
+	return traces
+}
+
+// DepartmentIs checks the department name of the job request.
+func DepartmentIs(name string) Condition {
+	return Condition{
+		Description: fmt.Sprintf("department is %q", name),
+		Check: func(jobRequest domain.JobRequest) bool {
+			return jobRequest.Department != nil && jobRequest.Department.Name == name
+		},
+	}
+}
+
+// HasDepartment checks the job request has a department.
+func HasDepartment() Condition {
+	return Condition{
+		Description: "department is set",
+		Check: func(jobRequest domain.JobRequest) bool {
+			return jobRequest.Department != nil
+		},
+	}
+}
+
+// JobItemIsSet checks the job request has a job item, regardless of its display name.
+func JobItemIsSet() Condition {
+	return Condition{
+		Description: "job item is set",
+		Check: func(jobRequest domain.JobRequest) bool {
+			return jobRequest.JobItem != nil
+		},
+	}
+}
+
+// HasJobItem checks the job request has a job item with a display name.
+func HasJobItem() Condition {
+	return Condition{
+		Description: "job item has a display name",
+		Check: func(jobRequest domain.JobRequest) bool {
+			return jobRequest.JobItem != nil && jobRequest.JobItem.DisplayName != ""
+		},
+	}
+}
+
+// JobItemMatches checks the job item display name matches the regular expression.
+func JobItemMatches(re *regexp.Regexp) Condition {
+	return Condition{
+		Description: fmt.Sprintf("job item matches %q", re.String()),
+		Check: func(jobRequest domain.JobRequest) bool {
+			return jobRequest.JobItem != nil && re.MatchString(jobRequest.JobItem.DisplayName)
+		},
+	}
+}
+
+// HasLocationType checks at least one location of the job request has the location type.
+func HasLocationType(locationType string) Condition {
+	return Condition{
+		Description: fmt.Sprintf("at least one location of type %q", locationType),
+		Check: func(jobRequest domain.JobRequest) bool {
+			for _, location := range jobRequest.Locations {
+				if location.LocationType != nil && location.LocationType.DisplayName == locationType {
+					return true
+				}
+			}
+			return false
+		},
+	}
+}
+
+// LocationsBetween checks the number of locations of the job request is between min and max, a zero max is unbounded.
+func LocationsBetween(min, max int) Condition {
+	description := fmt.Sprintf("at least %d location(s)", min)
+	switch {
+	case max > 0 && min == max:
+		description = fmt.Sprintf("exactly %d location(s)", min)
+	case max > 0:
+		description = fmt.Sprintf("between %d and %d locations", min, max)
+	}
+
+	return Condition{
+		Description: description,
+		Check: func(jobRequest domain.JobRequest) bool {
+			return len(jobRequest.Locations) >= min && (max == 0 || len(jobRequest.Locations) <= max)
+		},
+	}
+}
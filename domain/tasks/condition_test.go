@@ -0,0 +1,241 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/tasks/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConditions(t *testing.T) {
+	conditions := Conditions{
+		DepartmentIs("Housekeeping"),
+		JobItemMatches(regexp.MustCompile(`(?i)sheets`)),
+		LocationsBetween(1, 1),
+		HasLocationType("Floor"),
+	}
+
+	jobRequest := domain.JobRequest{
+		Department: &domain.Department{Name: "Housekeeping"},
+		JobItem:    &domain.JobItem{DisplayName: "Sheets"},
+		Locations: []domain.Location{
+			{LocationType: &domain.LocationType{DisplayName: "Room"}},
+		},
+	}
+
+	t.Run("should assert every condition", func(t *testing.T) {
+		assert.False(t, conditions.Assert(jobRequest))
+
+		floor := jobRequest
+		floor.Locations = []domain.Location{{LocationType: &domain.LocationType{DisplayName: "Floor"}}}
+		assert.True(t, conditions.Assert(floor))
+	})
+
+	t.Run("should explain the outcome of every condition", func(t *testing.T) {
+		expected := []domain.ConditionTrace{
+			{Condition: `department is "Housekeeping"`, Passed: true},
+			{Condition: `job item matches "(?i)sheets"`, Passed: true},
+			{Condition: "exactly 1 location(s)", Passed: true},
+			{Condition: `at least one location of type "Floor"`, Passed: false},
+		}
+		assert.Equal(t, expected, conditions.Explain(jobRequest))
+	})
+
+	t.Run("should handle missing fields", func(t *testing.T) {
+		empty := domain.JobRequest{}
+
+		assert.False(t, HasDepartment().Check(empty))
+		assert.False(t, DepartmentIs("Housekeeping").Check(empty))
+		assert.False(t, HasJobItem().Check(empty))
+		assert.False(t, JobItemIsSet().Check(empty))
+		assert.True(t, JobItemIsSet().Check(domain.JobRequest{JobItem: &domain.JobItem{}}))
+		assert.False(t, HasJobItem().Check(domain.JobRequest{JobItem: &domain.JobItem{}}))
+		assert.False(t, JobItemMatches(regexp.MustCompile(".*")).Check(empty))
+		assert.False(t, HasLocationType("Floor").Check(domain.JobRequest{Locations: []domain.Location{{}}}))
+	})
+
+	t.Run("should describe the locations range", func(t *testing.T) {
+		assert.Equal(t, "at least 2 location(s)", LocationsBetween(2, 0).Description)
+		assert.Equal(t, "between 1 and 3 locations", LocationsBetween(1, 3).Description)
+		assert.Equal(t, "exactly 1 location(s)", LocationsBetween(1, 1).Description)
+
+		assert.False(t, LocationsBetween(2, 3).Check(jobRequest))
+		assert.True(t, LocationsBetween(0, 0).Check(domain.JobRequest{}))
+	})
+
+	t.Run("should describe a zero min and max as unbounded", func(t *testing.T) {
+		condition := LocationsBetween(0, 0)
+		assert.Equal(t, "at least 0 location(s)", condition.Description)
+		assert.True(t, condition.Check(jobRequest))
+	})
+}
+
+type namedTask struct {
+	JobTask
+}
+
+func (namedTask) Name() string {
+	return "Named"
+}
+
+type unnamedTask struct {
+	JobTask
+}
+
+type plannerFunc func(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error)
+
+func (f plannerFunc) Plan(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error) {
+	return f(ctx, jobRequest)
+}
+
+type createJobAPI struct {
+	JobAPI
+	result *domain.JobCreated
+	err    error
+	calls  int
+}
+
+func (a *createJobAPI) CreateJob(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
+	a.calls++
+	return a.result, a.err
+}
+
+func TestNameOf(t *testing.T) {
+	assert.Equal(t, "Named", NameOf(namedTask{}))
+	assert.Equal(t, "unnamedTask", NameOf(&unnamedTask{}))
+	assert.Equal(t, "unnamedTask", NameOf(unnamedTask{}))
+}
+
+func TestExecutePlan(t *testing.T) {
+	jobRequest := domain.JobRequest{}
+	job := &domain.Job{Action: "clean"}
+	plan := plannerFunc(func(context.Context, domain.JobRequest) (*domain.Job, error) {
+		return job, nil
+	})
+
+	t.Run("should create the planned job", func(t *testing.T) {
+		api := &createJobAPI{result: &domain.JobCreated{ID: 7}}
+		result := ExecutePlan(context.Background(), api, plan, jobRequest)
+
+		assert.Equal(t, domain.JobResult{
+			Request: &jobRequest,
+			Outcome: domain.OutcomeCreated,
+			Action:  "clean",
+			Job:     job,
+			Created: &domain.JobCreated{ID: 7},
+		}, result)
+	})
+
+	t.Run("should skip the job when no locations are found", func(t *testing.T) {
+		api := &createJobAPI{}
+		result := ExecutePlan(context.Background(), api, plannerFunc(func(context.Context, domain.JobRequest) (*domain.Job, error) {
+			return nil, ErrNoLocations
+		}), jobRequest)
+
+		assert.Equal(t, domain.JobResult{
+			Request: &jobRequest,
+			Outcome: domain.OutcomeSkipped,
+			Error:   &domain.JobError{Code: domain.ErrorNoLocations, Message: "no locations found for this job"},
+		}, result)
+		assert.True(t, result.Failed())
+		assert.Zero(t, api.calls)
+	})
+
+	t.Run("should return the CreateJob error", func(t *testing.T) {
+		api := &createJobAPI{err: errors.New("failed to create job")}
+		result := ExecutePlan(context.Background(), api, plan, jobRequest)
+
+		assert.True(t, result.Failed())
+		assert.Equal(t, &domain.JobError{Code: domain.ErrorUnknown, Message: "failed to create job"}, result.Error)
+		assert.Equal(t, job, result.Job)
+		assert.Nil(t, result.Created)
+	})
+}
+
+func TestDryRun(t *testing.T) {
+	jobRequest := domain.JobRequest{}
+	job := &domain.Job{Action: "clean"}
+
+	t.Run("should only plan the job", func(t *testing.T) {
+		result := DryRun(context.Background(), &mock.MockExplainableRule{
+			PlanFunc: func(context.Context, domain.JobRequest) (*domain.Job, error) {
+				return job, nil
+			},
+		}, jobRequest)
+
+		assert.Equal(t, domain.JobResult{
+			Request: &jobRequest,
+			Outcome: domain.OutcomeDryRun,
+			Action:  "clean",
+			Job:     job,
+		}, result)
+	})
+
+	t.Run("should skip the job when no locations are found", func(t *testing.T) {
+		result := DryRun(context.Background(), &mock.MockExplainableRule{
+			PlanFunc: func(context.Context, domain.JobRequest) (*domain.Job, error) {
+				return nil, ErrNoLocations
+			},
+		}, jobRequest)
+
+		assert.Equal(t, domain.OutcomeSkipped, result.Outcome)
+		assert.Equal(t, domain.ErrorNoLocations, result.Error.Code)
+	})
+
+	t.Run("should skip the tasks which can't plan their job without executing them", func(t *testing.T) {
+		result := DryRun(context.Background(), &mock.MockRule{
+			ExecuteFunc: func(context.Context, domain.JobRequest) domain.JobResult {
+				t.Fatal("the task should not be executed")
+				return domain.JobResult{}
+			},
+		}, jobRequest)
+
+		assert.Equal(t, domain.OutcomeSkipped, result.Outcome)
+		assert.Equal(t, &domain.JobError{Code: domain.ErrorNoPlan, Message: ErrNoPlan.Error()}, result.Error)
+	})
+}
+
+// retryableError is an error of the API telling whether it can be retried, like sdk.APIError.
+type retryableError struct {
+	retryable bool
+	upstream  bool
+}
+
+func (e retryableError) Error() string   { return "api error" }
+func (e retryableError) Retryable() bool { return e.retryable }
+func (e retryableError) Is(target error) bool {
+	return e.upstream && target == domain.ErrUpstream
+}
+
+func TestNewJobError(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		code      domain.JobErrorCode
+		retryable bool
+	}{
+		{"no locations", fmt.Errorf("floor 2: %w", ErrNoLocations), domain.ErrorNoLocations, false},
+		{"timeout", fmt.Errorf("create job: %w", context.DeadlineExceeded), domain.ErrorTimeout, true},
+		{"cancelled", context.Canceled, domain.ErrorCancelled, true},
+		{"not found", fmt.Errorf("location 9 %w", domain.ErrNotFound), domain.ErrorNotFound, false},
+		{"unreachable", fmt.Errorf("%w: connection refused", domain.ErrUpstream), domain.ErrorUpstream, true},
+		{"failure of the API", retryableError{retryable: true, upstream: true}, domain.ErrorUpstream, true},
+		{"credentials rejected", retryableError{upstream: true}, domain.ErrorUpstream, false},
+		{"job rejected", retryableError{}, domain.ErrorRejected, false},
+		{"anything else", errors.New("boom"), domain.ErrorUnknown, false},
+	}
+
+	for _, tt := range tests {
+		t.Run("should classify "+tt.name, func(t *testing.T) {
+			jobErr := NewJobError(tt.err)
+			assert.Equal(t, tt.code, jobErr.Code)
+			assert.Equal(t, tt.retryable, jobErr.Retryable)
+			assert.Equal(t, tt.err.Error(), jobErr.Message)
+		})
+	}
+}
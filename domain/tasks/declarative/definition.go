@@ -0,0 +1,117 @@
+package declarative
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Location expansion strategies supported by a rule definition.
+const (
+	// ExpandExplicit uses the locations given in the job request.
+	ExpandExplicit = "explicit"
+	// ExpandFloorRooms uses every location of type 'Room' on the requested floor(s).
+	ExpandFloorRooms = "floorRooms"
+	// ExpandFloorLocations uses every location on the requested floor(s).
+	ExpandFloorLocations = "floorLocations"
+	// ExpandDescendants uses every location under the requested location(s), at any level.
+	ExpandDescendants = "descendants"
+	// ExpandSiblings uses the other locations sharing the parent of the requested location(s).
+	ExpandSiblings = "siblings"
+	// ExpandBuilding uses every location in the building of the requested location(s).
+	ExpandBuilding = "building"
+)
+
+// RuleSet is the root of a rule file.
+type RuleSet struct {
+	Rules []RuleDefinition `json:"rules" yaml:"rules"`
+}
+
+// RuleDefinition describes a rule without writing Go code.
+// A job request matches the rule when every condition that is set is satisfied.
+type RuleDefinition struct {
+	// Name identifies the rule, it must be unique in the rule set.
+	Name string `json:"name" yaml:"name"`
+	// Department is the name of the department the job request must belong to.
+	Department string `json:"department" yaml:"department"`
+	// JobItem is a regular expression matched against the job item display name.
+	// When empty, any job item with a display name matches.
+	JobItem string `json:"jobItem" yaml:"jobItem"`
+	// Locations restricts the locations of the job request.
+	Locations LocationCondition `json:"locations" yaml:"locations"`
+	// When is an expression (see the expression package) the job request must satisfy, e.g.
+	// `any(Locations, ParentLocation.DisplayName == "Floor 1")`.
+	When string `json:"when" yaml:"when"`
+	// Action is the action of the job sent to Optii, e.g. "deliver", "clean" or "repair".
+	Action string `json:"action" yaml:"action"`
+	// Expand is the location expansion strategy, defaults to ExpandExplicit.
+	Expand string `json:"expand" yaml:"expand"`
+	// ExpandType keeps only the expanded locations with this location type display name, e.g. "Room".
+	ExpandType string `json:"expandType" yaml:"expandType"`
+	// ExcludeStatus drops the expanded locations with one of these statuses, e.g. "Occupied" or "Blocked".
+	ExcludeStatus []string `json:"excludeStatus" yaml:"excludeStatus"`
+	// Priority of the rule when resolving conflicts between matching rules, higher values win.
+	Priority int `json:"priority" yaml:"priority"`
+	// Exclusive rules suppress every other matching rule.
+	Exclusive bool `json:"exclusive" yaml:"exclusive"`
+}
+
+// LocationCondition restricts the locations of a job request.
+type LocationCondition struct {
+	// Type is the location type display name (e.g. "Room" or "Floor").
+	// At least one location of the job request must have this type, and only
+	// locations of this type are used when expanding the job locations.
+	Type string `json:"type" yaml:"type"`
+	// Min is the minimum number of locations in the job request.
+	Min int `json:"min" yaml:"min"`
+	// Max is the maximum number of locations in the job request, zero means unbounded.
+	Max int `json:"max" yaml:"max"`
+}
+
+// LoadDefinitions reads the rule definitions from the given file.
+// Files with the .json extension are decoded as JSON, any other file is decoded as YAML.
+func LoadDefinitions(path string) ([]RuleDefinition, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading rules file: %w", err)
+	}
+
+	format := "yaml"
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		format = "json"
+	}
+
+	return ParseDefinitions(data, format)
+}
+
+// ParseDefinitions decodes the rule definitions from data using the given format ("json" or "yaml").
+func ParseDefinitions(data []byte, format string) ([]RuleDefinition, error) {
+	ruleSet := RuleSet{}
+
+	switch format {
+	case "json":
+		decoder := json.NewDecoder(bytes.NewReader(data))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&ruleSet); err != nil {
+			return nil, fmt.Errorf("error decoding rules: %w", err)
+		}
+	case "yaml":
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		// An empty document is a valid rule file without rules
+		if err := decoder.Decode(&ruleSet); err != nil && !errors.Is(err, io.EOF) {
+			return nil, fmt.Errorf("error decoding rules: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported rules format %q", format)
+	}
+
+	return ruleSet.Rules, nil
+}
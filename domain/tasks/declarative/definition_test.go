@@ -0,0 +1,118 @@
+package declarative
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDefinitions(t *testing.T) {
+	expected := []RuleDefinition{
+		{
+			Name:       "DeliverTowelsFloor",
+			Department: "Housekeeping",
+			JobItem:    `(?i)\btowels?\b`,
+			Locations: LocationCondition{
+				Type: "Floor",
+				Min:  1,
+				Max:  1,
+			},
+			Action:    "deliver",
+			Expand:    ExpandFloorRooms,
+			Priority:  10,
+			Exclusive: true,
+		},
+	}
+
+	t.Run("should parse yaml definitions", func(t *testing.T) {
+		data := `
+rules:
+  - name: DeliverTowelsFloor
+    department: Housekeeping
+    jobItem: (?i)\btowels?\b
+    locations:
+      type: Floor
+      min: 1
+      max: 1
+    action: deliver
+    expand: floorRooms
+    priority: 10
+    exclusive: true
+`
+		defs, err := ParseDefinitions([]byte(data), "yaml")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, defs)
+	})
+
+	t.Run("should parse json definitions", func(t *testing.T) {
+		data := `{"rules": [{
+			"name": "DeliverTowelsFloor",
+			"department": "Housekeeping",
+			"jobItem": "(?i)\\btowels?\\b",
+			"locations": {"type": "Floor", "min": 1, "max": 1},
+			"action": "deliver",
+			"expand": "floorRooms",
+			"priority": 10,
+			"exclusive": true
+		}]}`
+		defs, err := ParseDefinitions([]byte(data), "json")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, defs)
+	})
+
+	t.Run("should return no definitions for an empty yaml document", func(t *testing.T) {
+		defs, err := ParseDefinitions([]byte(""), "yaml")
+		assert.NoError(t, err)
+		assert.Empty(t, defs)
+	})
+
+	t.Run("should return an error for unknown fields", func(t *testing.T) {
+		_, err := ParseDefinitions([]byte("rules:\n  - name: A\n    departmnt: Housekeeping\n"), "yaml")
+		assert.Error(t, err)
+
+		_, err = ParseDefinitions([]byte(`{"rules": [{"name": "A", "departmnt": "Housekeeping"}]}`), "json")
+		assert.Error(t, err)
+	})
+
+	t.Run("should return an error for an unsupported format", func(t *testing.T) {
+		_, err := ParseDefinitions([]byte(""), "toml")
+		assert.EqualError(t, err, `unsupported rules format "toml"`)
+	})
+}
+
+func TestLoadDefinitions(t *testing.T) {
+	dir := t.TempDir()
+
+	t.Run("should choose the format based on the file extension", func(t *testing.T) {
+		jsonFile := filepath.Join(dir, "rules.json")
+		err := os.WriteFile(jsonFile, []byte(`{"rules": [{"name": "A", "action": "clean"}]}`), 0o600)
+		assert.NoError(t, err)
+
+		yamlFile := filepath.Join(dir, "rules.yaml")
+		err = os.WriteFile(yamlFile, []byte("rules:\n  - name: A\n    action: clean\n"), 0o600)
+		assert.NoError(t, err)
+
+		expected := []RuleDefinition{{Name: "A", Action: "clean"}}
+		for _, file := range []string{jsonFile, yamlFile} {
+			defs, err := LoadDefinitions(file)
+			assert.NoError(t, err)
+			assert.Equal(t, expected, defs)
+		}
+	})
+
+	t.Run("should return an error when the file does not exist", func(t *testing.T) {
+		_, err := LoadDefinitions(filepath.Join(dir, "missing.yaml"))
+		assert.Error(t, err)
+	})
+
+	t.Run("should load the example rules file", func(t *testing.T) {
+		defs, err := LoadDefinitions("../../../rules.example.yaml")
+		assert.NoError(t, err)
+		assert.NotEmpty(t, defs)
+
+		_, err = CompileAll(defs, nil)
+		assert.NoError(t, err)
+	})
+}
@@ -0,0 +1,213 @@
+package declarative
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/expression"
+	"github.com/Twsouza/job-rule-engine/domain/tasks"
+)
+
+// RuleTask is a tasks.JobTask compiled from a RuleDefinition.
+type RuleTask struct {
+	API        tasks.JobAPI
+	Definition RuleDefinition
+
+	conditions tasks.Conditions
+	expansion  tasks.Expansion
+}
+
+// expansions are the location expansion strategies by name.
+var expansions = map[string]func() tasks.Expansion{
+	ExpandExplicit:       tasks.Explicit,
+	ExpandFloorRooms:     tasks.FloorRooms,
+	ExpandFloorLocations: tasks.FloorLocations,
+	ExpandDescendants:    tasks.Descendants,
+	ExpandSiblings:       tasks.Siblings,
+	ExpandBuilding:       tasks.Building,
+}
+
+// Compile validates the given definition and returns the RuleTask that executes it.
+func Compile(def RuleDefinition, api tasks.JobAPI) (*RuleTask, error) {
+	if def.Name == "" {
+		return nil, fmt.Errorf("rule name is required")
+	}
+	if def.Action == "" {
+		return nil, fmt.Errorf("rule %s: action is required", def.Name)
+	}
+	if def.Locations.Min < 0 || def.Locations.Max < 0 {
+		return nil, fmt.Errorf("rule %s: locations min and max must not be negative", def.Name)
+	}
+	if def.Locations.Max > 0 && def.Locations.Min > def.Locations.Max {
+		return nil, fmt.Errorf("rule %s: locations min must not be greater than max", def.Name)
+	}
+
+	if def.Expand == "" {
+		def.Expand = ExpandExplicit
+	}
+	expansion, ok := expansions[def.Expand]
+	if !ok {
+		return nil, fmt.Errorf("rule %s: unknown expand strategy %q", def.Name, def.Expand)
+	}
+
+	rt := &RuleTask{
+		API:        api,
+		Definition: def,
+		expansion:  expansion(),
+	}
+	if def.ExpandType != "" {
+		rt.expansion = rt.expansion.OfType(def.ExpandType)
+	}
+	if len(def.ExcludeStatus) > 0 {
+		rt.expansion = rt.expansion.Excluding(def.ExcludeStatus...)
+	}
+
+	if def.Department != "" {
+		rt.conditions = append(rt.conditions, tasks.DepartmentIs(def.Department))
+	} else {
+		rt.conditions = append(rt.conditions, tasks.HasDepartment())
+	}
+
+	rt.conditions = append(rt.conditions, tasks.HasJobItem())
+	if def.JobItem != "" {
+		re, err := regexp.Compile(def.JobItem)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: invalid job item pattern: %w", def.Name, err)
+		}
+		rt.conditions = append(rt.conditions, tasks.JobItemMatches(re))
+	}
+
+	// A job needs at least one location
+	minLocations := def.Locations.Min
+	if minLocations < 1 {
+		minLocations = 1
+	}
+	rt.conditions = append(rt.conditions, tasks.LocationsBetween(minLocations, def.Locations.Max))
+	if def.Locations.Type != "" {
+		rt.conditions = append(rt.conditions, tasks.HasLocationType(def.Locations.Type))
+	}
+
+	if def.When != "" {
+		program, err := expression.Compile(def.When)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: invalid when expression: %w", def.Name, err)
+		}
+		rt.conditions = append(rt.conditions, tasks.Condition{
+			Description: "when " + program.String(),
+			Check:       program.Evaluate,
+		})
+	}
+
+	return rt, nil
+}
+
+// CompileAll compiles every definition, it fails if any definition is invalid or if two rules share the same name.
+func CompileAll(defs []RuleDefinition, api tasks.JobAPI) ([]tasks.JobTask, error) {
+	names := map[string]bool{}
+	taskList := []tasks.JobTask{}
+
+	for _, def := range defs {
+		if names[def.Name] {
+			return nil, fmt.Errorf("rule %s: duplicated rule name", def.Name)
+		}
+		names[def.Name] = true
+
+		rt, err := Compile(def, api)
+		if err != nil {
+			return nil, err
+		}
+		taskList = append(taskList, rt)
+	}
+
+	return taskList, nil
+}
+
+// Name returns the name of the rule.
+func (rt *RuleTask) Name() string {
+	return rt.Definition.Name
+}
+
+// Priority returns the priority of the rule.
+func (rt *RuleTask) Priority() int {
+	return rt.Definition.Priority
+}
+
+// Exclusive returns true if the rule suppresses every other matching rule.
+func (rt *RuleTask) Exclusive() bool {
+	return rt.Definition.Exclusive
+}
+
+// AssertRule checks if the given job request satisfies every condition of the rule definition:
+// - The department name is equal to the definition department, when set.
+// - The job item display name is not empty and matches the job item pattern, when set.
+// - The number of locations is between the locations min and max, with at least one location.
+// - At least one location has the definition location type, when set.
+// - The when expression evaluates to true, when set.
+func (rt *RuleTask) AssertRule(jobRequest domain.JobRequest) bool {
+	return rt.conditions.Assert(jobRequest)
+}
+
+// Explain returns the outcome of each condition of AssertRule.
+func (rt *RuleTask) Explain(jobRequest domain.JobRequest) []domain.ConditionTrace {
+	return rt.conditions.Explain(jobRequest)
+}
+
+// Plan builds the job with the rule action for the expanded locations.
+func (rt *RuleTask) Plan(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error) {
+	job := &domain.Job{
+		Action: rt.Definition.Action,
+		Department: domain.JDepartment{
+			ID: jobRequest.Department.ID,
+		},
+		Item: domain.JItem{
+			Name: jobRequest.JobItem.DisplayName,
+		},
+	}
+
+	locations, err := rt.expandLocations(ctx, jobRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, location := range locations {
+		job.Locations = append(job.Locations, domain.JLocation{
+			ID: location.ID,
+		})
+	}
+
+	if len(job.Locations) == 0 {
+		return nil, tasks.ErrNoLocations
+	}
+
+	return job, nil
+}
+
+// Execute will create a job with the rule action for the expanded locations.
+func (rt *RuleTask) Execute(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
+	return tasks.ExecutePlan(ctx, rt.API, rt, jobRequest)
+}
+
+// typedLocations returns the locations of the job request with the definition location type,
+// or every location when the definition has no location type.
+func (rt *RuleTask) typedLocations(jobRequest domain.JobRequest) []domain.Location {
+	if rt.Definition.Locations.Type == "" {
+		return jobRequest.Locations
+	}
+
+	var locations []domain.Location
+	for _, location := range jobRequest.Locations {
+		if location.LocationType != nil && location.LocationType.DisplayName == rt.Definition.Locations.Type {
+			locations = append(locations, location)
+		}
+	}
+
+	return locations
+}
+
+// expandLocations returns the locations of the job according to the definition expand strategy,
+// applied to every requested location with the definition location type.
+func (rt *RuleTask) expandLocations(ctx context.Context, jobRequest domain.JobRequest) ([]domain.Location, error) {
+	return rt.expansion.Expand(ctx, rt.API, rt.typedLocations(jobRequest))
+}
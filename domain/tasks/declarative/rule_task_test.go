@@ -0,0 +1,321 @@
+package declarative
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/tasks/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompile(t *testing.T) {
+	t.Run("should default to the explicit expand strategy", func(t *testing.T) {
+		rt, err := Compile(RuleDefinition{Name: "A", Action: "clean"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, ExpandExplicit, rt.Definition.Expand)
+		assert.Equal(t, "A", rt.Name())
+		assert.Equal(t, 0, rt.Priority())
+		assert.False(t, rt.Exclusive())
+	})
+
+	t.Run("should return an error for invalid definitions", func(t *testing.T) {
+		tests := map[string]RuleDefinition{
+			"rule name is required":                                     {Action: "clean"},
+			"rule A: action is required":                                {Name: "A"},
+			`rule A: unknown expand strategy "wing"`:                    {Name: "A", Action: "clean", Expand: "wing"},
+			"rule A: locations min must not be greater than max":        {Name: "A", Action: "clean", Locations: LocationCondition{Min: 2, Max: 1}},
+			"rule A: locations min and max must not be negative":        {Name: "A", Action: "clean", Locations: LocationCondition{Min: -1}},
+			"rule A: invalid job item pattern: error parsing regexp":    {Name: "A", Action: "clean", JobItem: "(towels"},
+			"rule A: invalid when expression: 1:1: unknown field Floor": {Name: "A", Action: "clean", When: "Floor == 1"},
+		}
+
+		for expected, def := range tests {
+			_, err := Compile(def, nil)
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), expected)
+			}
+		}
+	})
+
+	t.Run("should return an error for duplicated rule names", func(t *testing.T) {
+		_, err := CompileAll([]RuleDefinition{
+			{Name: "A", Action: "clean"},
+			{Name: "A", Action: "repair"},
+		}, nil)
+		assert.EqualError(t, err, "rule A: duplicated rule name")
+	})
+}
+
+func TestRuleTask_AssertRule(t *testing.T) {
+	rt, err := Compile(RuleDefinition{
+		Name:       "DeliverTowelsFloor",
+		Department: "Housekeeping",
+		JobItem:    `(?i)\btowels?\b`,
+		Locations: LocationCondition{
+			Type: "Floor",
+			Min:  1,
+			Max:  1,
+		},
+		Action: "deliver",
+		Expand: ExpandFloorRooms,
+	}, nil)
+	assert.NoError(t, err)
+
+	floor := domain.Location{ID: 1, LocationType: &domain.LocationType{DisplayName: "Floor"}}
+	room := domain.Location{ID: 2, LocationType: &domain.LocationType{DisplayName: "Room"}}
+
+	t.Run("should return true for valid job request", func(t *testing.T) {
+		jobRequest := domain.JobRequest{
+			Department: &domain.Department{Name: "Housekeeping"},
+			JobItem:    &domain.JobItem{DisplayName: "Bath Towels"},
+			Locations:  []domain.Location{floor},
+		}
+
+		assert.True(t, rt.AssertRule(jobRequest))
+	})
+
+	t.Run("should return false for invalid department", func(t *testing.T) {
+		jobRequest := domain.JobRequest{
+			Department: &domain.Department{Name: "Room Service"},
+			JobItem:    &domain.JobItem{DisplayName: "Towels"},
+			Locations:  []domain.Location{floor},
+		}
+
+		assert.False(t, rt.AssertRule(jobRequest))
+	})
+
+	t.Run("should return false for invalid job item", func(t *testing.T) {
+		jobRequest := domain.JobRequest{
+			Department: &domain.Department{Name: "Housekeeping"},
+			JobItem:    &domain.JobItem{DisplayName: "Sheets"},
+			Locations:  []domain.Location{floor},
+		}
+
+		assert.False(t, rt.AssertRule(jobRequest))
+	})
+
+	t.Run("should return false for missing location type", func(t *testing.T) {
+		jobRequest := domain.JobRequest{
+			Department: &domain.Department{Name: "Housekeeping"},
+			JobItem:    &domain.JobItem{DisplayName: "Towels"},
+			Locations:  []domain.Location{room},
+		}
+
+		assert.False(t, rt.AssertRule(jobRequest))
+	})
+
+	t.Run("should return false when there are too many locations", func(t *testing.T) {
+		jobRequest := domain.JobRequest{
+			Department: &domain.Department{Name: "Housekeeping"},
+			JobItem:    &domain.JobItem{DisplayName: "Towels"},
+			Locations:  []domain.Location{floor, room},
+		}
+
+		assert.False(t, rt.AssertRule(jobRequest))
+	})
+
+	t.Run("should return false when the when expression is not satisfied", func(t *testing.T) {
+		rt, err := Compile(RuleDefinition{
+			Name:   "DeliverTowelsFirstFloor",
+			When:   `any(Locations, DisplayName == "Floor 1")`,
+			Action: "deliver",
+		}, nil)
+		assert.NoError(t, err)
+
+		jobRequest := domain.JobRequest{
+			Department: &domain.Department{Name: "Housekeeping"},
+			JobItem:    &domain.JobItem{DisplayName: "Towels"},
+			Locations:  []domain.Location{{ID: 1, DisplayName: "Floor 1"}},
+		}
+		assert.True(t, rt.AssertRule(jobRequest))
+
+		jobRequest.Locations[0].DisplayName = "Floor 2"
+		assert.False(t, rt.AssertRule(jobRequest))
+	})
+
+	t.Run("should return false for missing department or job item", func(t *testing.T) {
+		assert.False(t, rt.AssertRule(domain.JobRequest{
+			JobItem:   &domain.JobItem{DisplayName: "Towels"},
+			Locations: []domain.Location{floor},
+		}))
+		assert.False(t, rt.AssertRule(domain.JobRequest{
+			Department: &domain.Department{Name: "Housekeeping"},
+			Locations:  []domain.Location{floor},
+		}))
+	})
+}
+
+func TestRuleTask_Execute(t *testing.T) {
+	jobRequest := domain.JobRequest{
+		Department: &domain.Department{ID: 1, Name: "Housekeeping"},
+		JobItem:    &domain.JobItem{DisplayName: "Towels"},
+		Locations: []domain.Location{
+			{ID: 10, LocationType: &domain.LocationType{DisplayName: "Floor"}},
+			{ID: 11, LocationType: &domain.LocationType{DisplayName: "Floor"}},
+			{ID: 20, LocationType: &domain.LocationType{DisplayName: "Room"}},
+		},
+	}
+
+	t.Run("should create a job for the explicit locations of the rule type", func(t *testing.T) {
+		mockAPI := &mock.JobAPIMock{}
+		expectedJob := &domain.Job{
+			Action:     "deliver",
+			Department: domain.JDepartment{ID: 1},
+			Item:       domain.JItem{Name: "Towels"},
+			Locations:  []domain.JLocation{{ID: 20}},
+		}
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
+			assert.Equal(t, expectedJob, job)
+			return &domain.JobCreated{ID: 1}, nil
+		}
+
+		rt, err := Compile(RuleDefinition{Name: "A", Action: "deliver", Locations: LocationCondition{Type: "Room"}}, mockAPI)
+		assert.NoError(t, err)
+
+		result := rt.Execute(context.Background(), jobRequest)
+		assert.Equal(t, domain.JobResult{
+			Request: &jobRequest,
+			Outcome: domain.OutcomeCreated,
+			Action:  "deliver",
+			Job:     expectedJob,
+			Created: &domain.JobCreated{ID: 1},
+		}, result)
+	})
+
+	t.Run("should create a job for the rooms of every requested floor", func(t *testing.T) {
+		mockAPI := &mock.JobAPIMock{}
+		mockAPI.GetFloorRoomsFunc = func(ctx context.Context, floorID int) ([]domain.Location, error) {
+			// Room 101 is returned for both floors to check it is not duplicated
+			return []domain.Location{{ID: floorID * 10}, {ID: 101}}, nil
+		}
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
+			assert.Equal(t, []domain.JLocation{{ID: 100}, {ID: 101}, {ID: 110}}, job.Locations)
+			return &domain.JobCreated{ID: 1}, nil
+		}
+
+		rt, err := Compile(RuleDefinition{Name: "A", Action: "deliver", Locations: LocationCondition{Type: "Floor"}, Expand: ExpandFloorRooms}, mockAPI)
+		assert.NoError(t, err)
+
+		result := rt.Execute(context.Background(), jobRequest)
+		assert.Equal(t, domain.OutcomeCreated, result.Outcome)
+	})
+
+	t.Run("should use the floor locations for the floorLocations strategy", func(t *testing.T) {
+		mockAPI := &mock.JobAPIMock{}
+		mockAPI.GetFloorLocationsFunc = func(ctx context.Context, floorID int) ([]domain.Location, error) {
+			return []domain.Location{{ID: floorID + 1}}, nil
+		}
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
+			assert.Equal(t, []domain.JLocation{{ID: 11}, {ID: 12}}, job.Locations)
+			return &domain.JobCreated{ID: 1}, nil
+		}
+
+		rt, err := Compile(RuleDefinition{Name: "A", Action: "repair", Locations: LocationCondition{Type: "Floor"}, Expand: ExpandFloorLocations}, mockAPI)
+		assert.NoError(t, err)
+
+		result := rt.Execute(context.Background(), jobRequest)
+		assert.Equal(t, domain.OutcomeCreated, result.Outcome)
+	})
+
+	t.Run("should filter the expanded locations by type and status", func(t *testing.T) {
+		tree := domain.NewLocationTree([]domain.Location{
+			{ID: 10, LocationType: &domain.LocationType{DisplayName: "Floor"}},
+			{ID: 100, ParentLocation: &domain.ParentLocation{ID: 10}, LocationType: &domain.LocationType{DisplayName: "Room"}},
+			{ID: 101, ParentLocation: &domain.ParentLocation{ID: 10}, LocationType: &domain.LocationType{DisplayName: "Room"}, Status: "Occupied"},
+			{ID: 102, ParentLocation: &domain.ParentLocation{ID: 10}, LocationType: &domain.LocationType{DisplayName: "Area"}},
+			{ID: 11, LocationType: &domain.LocationType{DisplayName: "Floor"}},
+			{ID: 110, ParentLocation: &domain.ParentLocation{ID: 11}, LocationType: &domain.LocationType{DisplayName: "Room"}},
+		})
+
+		mockAPI := &mock.JobAPIMock{}
+		mockAPI.GetLocationTreeFunc = func(ctx context.Context) (*domain.LocationTree, error) {
+			return tree, nil
+		}
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
+			assert.Equal(t, []domain.JLocation{{ID: 100}, {ID: 110}}, job.Locations)
+			return &domain.JobCreated{ID: 1}, nil
+		}
+
+		rt, err := Compile(RuleDefinition{
+			Name:          "A",
+			Action:        "clean",
+			Locations:     LocationCondition{Type: "Floor"},
+			Expand:        ExpandDescendants,
+			ExpandType:    "Room",
+			ExcludeStatus: []string{"Occupied", "Blocked"},
+		}, mockAPI)
+		assert.NoError(t, err)
+
+		result := rt.Execute(context.Background(), jobRequest)
+		assert.Equal(t, domain.OutcomeCreated, result.Outcome)
+	})
+
+	t.Run("should return error if the floor lookup fails", func(t *testing.T) {
+		mockAPI := &mock.JobAPIMock{}
+		mockAPI.GetFloorRoomsFunc = func(ctx context.Context, floorID int) ([]domain.Location, error) {
+			return nil, errors.New("failed to get floor rooms")
+		}
+
+		rt, err := Compile(RuleDefinition{Name: "A", Action: "deliver", Locations: LocationCondition{Type: "Floor"}, Expand: ExpandFloorRooms}, mockAPI)
+		assert.NoError(t, err)
+
+		result := rt.Execute(context.Background(), jobRequest)
+		assert.EqualError(t, result.Error, "failed to get floor rooms")
+	})
+
+	t.Run("should return error if no locations are found", func(t *testing.T) {
+		mockAPI := &mock.JobAPIMock{}
+		mockAPI.GetFloorRoomsFunc = func(ctx context.Context, floorID int) ([]domain.Location, error) {
+			return nil, nil
+		}
+
+		rt, err := Compile(RuleDefinition{Name: "A", Action: "deliver", Locations: LocationCondition{Type: "Floor"}, Expand: ExpandFloorRooms}, mockAPI)
+		assert.NoError(t, err)
+
+		result := rt.Execute(context.Background(), jobRequest)
+		assert.EqualError(t, result.Error, "no locations found for this job")
+	})
+
+	t.Run("should return error if CreateJob fails", func(t *testing.T) {
+		mockAPI := &mock.JobAPIMock{}
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
+			return nil, errors.New("failed to create job")
+		}
+
+		rt, err := Compile(RuleDefinition{Name: "A", Action: "deliver"}, mockAPI)
+		assert.NoError(t, err)
+
+		result := rt.Execute(context.Background(), jobRequest)
+		assert.EqualError(t, result.Error, "failed to create job")
+	})
+}
+
+func TestRuleTask_Explain(t *testing.T) {
+	rt, err := Compile(RuleDefinition{
+		Name:       "DeliverTowelsFloor",
+		Department: "Housekeeping",
+		JobItem:    `(?i)\btowels?\b`,
+		Locations:  LocationCondition{Type: "Floor", Max: 1},
+		When:       `Department.ID == 1`,
+		Action:     "deliver",
+	}, nil)
+	assert.NoError(t, err)
+
+	jobRequest := domain.JobRequest{
+		Department: &domain.Department{ID: 1, Name: "Housekeeping"},
+		JobItem:    &domain.JobItem{DisplayName: "Sheets"},
+		Locations:  []domain.Location{{ID: 1, LocationType: &domain.LocationType{DisplayName: "Floor"}}},
+	}
+
+	expected := []domain.ConditionTrace{
+		{Condition: `department is "Housekeeping"`, Passed: true},
+		{Condition: "job item has a display name", Passed: true},
+		{Condition: `job item matches "(?i)\\btowels?\\b"`, Passed: false},
+		{Condition: "exactly 1 location(s)", Passed: true},
+		{Condition: `at least one location of type "Floor"`, Passed: true},
+		{Condition: "when Department.ID == 1", Passed: true},
+	}
+	assert.Equal(t, expected, rt.Explain(jobRequest))
+}
@@ -0,0 +1,12 @@
+package engineering
+
+import "github.com/Twsouza/job-rule-engine/domain/tasks"
+
+func init() {
+	tasks.Register("RepairJobItemFloor", func(api tasks.JobAPI) tasks.JobTask {
+		return RepairJobItemFloor{API: api}
+	})
+	tasks.Register("RepairJobItemLocation", func(api tasks.JobAPI) tasks.JobTask {
+		return RepairJobItemLocation{API: api}
+	})
+}
@@ -0,0 +1,20 @@
+package engineering
+
+import (
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain/tasks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	t.Run("should register the engineering tasks", func(t *testing.T) {
+		assert.Subset(t, tasks.Registered(), []string{"RepairJobItemFloor", "RepairJobItemLocation"})
+
+		names := []string{}
+		for _, task := range tasks.NewRegistered(nil) {
+			names = append(names, tasks.NameOf(task))
+		}
+		assert.Equal(t, []string{"RepairJobItemFloor", "RepairJobItemLocation"}, names)
+	})
+}
@@ -1,6 +1,8 @@
 package engineering
 
 import (
+	"context"
+
 	"github.com/Twsouza/job-rule-engine/domain"
 	"github.com/Twsouza/job-rule-engine/domain/tasks"
 )
@@ -9,28 +11,41 @@ type RepairJobItemFloor struct {
 	API tasks.JobAPI
 }
 
+// repairJobItemFloorConditions are the conditions to repair a job item in all locations on a floor.
+var repairJobItemFloorConditions = tasks.Conditions{
+	tasks.DepartmentIs("Engineering"),
+	// Unlike RepairJobItemLocation, the job item display name may be empty
+	tasks.JobItemIsSet(),
+	tasks.LocationsBetween(1, 1),
+	tasks.HasLocationType("Floor"),
+}
+
+// Name returns the name the task is registered with.
+func (rj RepairJobItemFloor) Name() string {
+	return "RepairJobItemFloor"
+}
+
+// Priority is higher than RepairJobItemLocation's, which also matches floor requests,
+// so the floor rule wins with the firstMatch and highestPriority strategies.
+func (rj RepairJobItemFloor) Priority() int {
+	return 1
+}
+
 // AssertRule checks if the given job request meets the criteria for a repair job item in all locations on floor task.
 // It verifies that the job request belongs to the "Engineering" department
 // and has at least one location specified, with "Floor" being the only allowed location.
 // It returns true if the job request meets the criteria, otherwise it returns false.
 func (rj RepairJobItemFloor) AssertRule(jobRequest domain.JobRequest) bool {
-	if jobRequest.Department == nil || jobRequest.JobItem == nil {
-		return false
-	}
-
-	if jobRequest.Department.Name == "Engineering" && len(jobRequest.Locations) == 1 && jobRequest.Locations[0].LocationType.DisplayName == "Floor" {
-		return true
-	}
-
-	return false
+	return repairJobItemFloorConditions.Assert(jobRequest)
 }
 
-// Execute will create a job to repair the given job item in all locations on that floor.
-func (rj RepairJobItemFloor) Execute(jobRequest domain.JobRequest) domain.JobResult {
-	jr := domain.JobResult{
-		Request: &jobRequest,
-	}
+// Explain returns the outcome of each condition of AssertRule.
+func (rj RepairJobItemFloor) Explain(jobRequest domain.JobRequest) []domain.ConditionTrace {
+	return repairJobItemFloorConditions.Explain(jobRequest)
+}
 
+// Plan builds the job to repair the given job item in all locations on that floor.
+func (rj RepairJobItemFloor) Plan(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error) {
 	job := &domain.Job{
 		Action: "repair",
 		Department: domain.JDepartment{
@@ -41,10 +56,11 @@ func (rj RepairJobItemFloor) Execute(jobRequest domain.JobRequest) domain.JobRes
 		},
 	}
 
-	locations, err := rj.API.GetFloorLocations(jobRequest.Locations[0].ID)
+	// The locations of every requested floor
+	floors := tasks.LocationsOfType(jobRequest.Locations, "Floor")
+	locations, err := tasks.FloorLocations().Expand(ctx, rj.API, floors)
 	if err != nil {
-		jr.Err = err.Error()
-		return jr
+		return nil, err
 	}
 
 	for _, location := range locations {
@@ -54,21 +70,13 @@ func (rj RepairJobItemFloor) Execute(jobRequest domain.JobRequest) domain.JobRes
 	}
 
 	if len(job.Locations) == 0 {
-		return domain.JobResult{
-			Request: &jobRequest,
-			Err:     "no locations found for this job",
-		}
+		return nil, tasks.ErrNoLocations
 	}
 
-	var errMsg string
-	result, err := rj.API.CreateJob(job)
-	if err != nil {
-		errMsg = err.Error()
-	}
+	return job, nil
+}
 
-	return domain.JobResult{
-		Request: &jobRequest,
-		Result:  result,
-		Err:     errMsg,
-	}
+// Execute will create a job to repair the given job item in all locations on that floor.
+func (rj RepairJobItemFloor) Execute(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
+	return tasks.ExecutePlan(ctx, rj.API, rj, jobRequest)
 }
@@ -1,6 +1,7 @@
 package engineering
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -73,6 +74,25 @@ func TestRepairJobItemFloor_AssertRule(t *testing.T) {
 		assert.False(t, result)
 	})
 
+	t.Run("should return true for a job item without display name", func(t *testing.T) {
+		jobRequest := domain.JobRequest{
+			Department: &domain.Department{
+				Name: "Engineering",
+			},
+			JobItem: &domain.JobItem{},
+			Locations: []domain.Location{
+				{
+					LocationType: &domain.LocationType{
+						DisplayName: "Floor",
+					},
+				},
+			},
+		}
+
+		result := rj.AssertRule(jobRequest)
+		assert.True(t, result)
+	})
+
 	t.Run("should return false for invalid location", func(t *testing.T) {
 		jobRequest := domain.JobRequest{
 			Department: &domain.Department{
@@ -160,22 +180,24 @@ func TestRepairJobItemFloor_Execute(t *testing.T) {
 
 		expectedResult := domain.JobResult{
 			Request: &jobRequest,
-			Result:  "job created",
-			Err:     "",
+			Outcome: domain.OutcomeCreated,
+			Action:  expectedJob.Action,
+			Job:     expectedJob,
+			Created: &domain.JobCreated{ID: 1},
 		}
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.CreateJobFunc = func(job *domain.Job) (interface{}, error) {
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
 			assert.Equal(t, expectedJob, job)
-			return "job created", nil
+			return &domain.JobCreated{ID: 1}, nil
 		}
-		mockAPI.GetFloorLocationsFunc = func(floorID int) ([]domain.Location, error) {
+		mockAPI.GetFloorLocationsFunc = func(ctx context.Context, floorID int) ([]domain.Location, error) {
 			return []domain.Location{{ID: 1}}, nil
 		}
 
 		rj.API = mockAPI
 
-		result := rj.Execute(jobRequest)
+		result := rj.Execute(context.Background(), jobRequest)
 		assert.Equal(t, expectedResult, result)
 	})
 
@@ -201,15 +223,15 @@ func TestRepairJobItemFloor_Execute(t *testing.T) {
 		expectedError := errors.New("failed to get floor locations")
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.GetFloorLocationsFunc = func(floorID int) ([]domain.Location, error) {
+		mockAPI.GetFloorLocationsFunc = func(ctx context.Context, floorID int) ([]domain.Location, error) {
 			return nil, expectedError
 		}
 
 		rj.API = mockAPI
 
-		result := rj.Execute(jobRequest)
+		result := rj.Execute(context.Background(), jobRequest)
 
-		assert.Equal(t, expectedError.Error(), result.Err)
+		assert.EqualError(t, result.Error, expectedError.Error())
 	})
 
 	t.Run("should return error if CreateJob fails", func(t *testing.T) {
@@ -249,18 +271,18 @@ func TestRepairJobItemFloor_Execute(t *testing.T) {
 		expectedError := errors.New("failed to create job")
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.CreateJobFunc = func(job *domain.Job) (interface{}, error) {
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
 			assert.Equal(t, expectedJob, job)
 			return nil, expectedError
 		}
-		mockAPI.GetFloorLocationsFunc = func(floorID int) ([]domain.Location, error) {
+		mockAPI.GetFloorLocationsFunc = func(ctx context.Context, floorID int) ([]domain.Location, error) {
 			return []domain.Location{{ID: 1}}, nil
 		}
 
 		rj.API = mockAPI
 
-		result := rj.Execute(jobRequest)
+		result := rj.Execute(context.Background(), jobRequest)
 
-		assert.Equal(t, expectedError.Error(), result.Err)
+		assert.EqualError(t, result.Error, expectedError.Error())
 	})
 }
@@ -1,6 +1,8 @@
 package engineering
 
 import (
+	"context"
+
 	"github.com/Twsouza/job-rule-engine/domain"
 	"github.com/Twsouza/job-rule-engine/domain/tasks"
 )
@@ -9,23 +11,32 @@ type RepairJobItemLocation struct {
 	API tasks.JobAPI
 }
 
+// repairJobItemLocationConditions are the conditions to repair a job item at the given locations.
+var repairJobItemLocationConditions = tasks.Conditions{
+	tasks.DepartmentIs("Engineering"),
+	tasks.HasJobItem(),
+	tasks.LocationsBetween(1, 0),
+}
+
+// Name returns the name the task is registered with.
+func (rj RepairJobItemLocation) Name() string {
+	return "RepairJobItemLocation"
+}
+
 // AssertRule checks if the given job request meets the criteria for a repair job item at a location.
 // It returns true if the job request belongs to the "Engineering" department and has a non-empty job item and at least one location.
 // Otherwise, it returns false.
 func (rj RepairJobItemLocation) AssertRule(jobRequest domain.JobRequest) bool {
-	if jobRequest.Department == nil || jobRequest.JobItem == nil {
-		return false
-	}
-
-	if jobRequest.Department.Name == "Engineering" && jobRequest.JobItem.DisplayName != "" && len(jobRequest.Locations) > 0 {
-		return true
-	}
+	return repairJobItemLocationConditions.Assert(jobRequest)
+}
 
-	return false
+// Explain returns the outcome of each condition of AssertRule.
+func (rj RepairJobItemLocation) Explain(jobRequest domain.JobRequest) []domain.ConditionTrace {
+	return repairJobItemLocationConditions.Explain(jobRequest)
 }
 
-// Execute will create a job to repair the given job item at the given location(s).
-func (rj RepairJobItemLocation) Execute(jobRequest domain.JobRequest) domain.JobResult {
+// Plan builds the job to repair the given job item at the given location(s).
+func (rj RepairJobItemLocation) Plan(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error) {
 	job := &domain.Job{
 		Action: "repair",
 		Department: domain.JDepartment{
@@ -43,21 +54,13 @@ func (rj RepairJobItemLocation) Execute(jobRequest domain.JobRequest) domain.Job
 	}
 
 	if len(job.Locations) == 0 {
-		return domain.JobResult{
-			Request: &jobRequest,
-			Err:     "no locations found for this job",
-		}
+		return nil, tasks.ErrNoLocations
 	}
 
-	var errMsg string
-	result, err := rj.API.CreateJob(job)
-	if err != nil {
-		errMsg = err.Error()
-	}
+	return job, nil
+}
 
-	return domain.JobResult{
-		Request: &jobRequest,
-		Result:  result,
-		Err:     errMsg,
-	}
+// Execute will create a job to repair the given job item at the given location(s).
+func (rj RepairJobItemLocation) Execute(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
+	return tasks.ExecutePlan(ctx, rj.API, rj, jobRequest)
 }
@@ -1,6 +1,7 @@
 package engineering
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -135,19 +136,21 @@ func TestRepairJobItemLocation_Execute(t *testing.T) {
 
 		expectedResult := domain.JobResult{
 			Request: &jobRequest,
-			Result:  "success",
-			Err:     "",
+			Outcome: domain.OutcomeCreated,
+			Action:  expectedJob.Action,
+			Job:     expectedJob,
+			Created: &domain.JobCreated{ID: 1},
 		}
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.CreateJobFunc = func(job *domain.Job) (interface{}, error) {
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
 			assert.Equal(t, expectedJob, job)
-			return "success", nil
+			return &domain.JobCreated{ID: 1}, nil
 		}
 
 		rj.API = mockAPI
 
-		result := rj.Execute(jobRequest)
+		result := rj.Execute(context.Background(), jobRequest)
 		assert.Equal(t, expectedResult, result)
 	})
 
@@ -184,19 +187,21 @@ func TestRepairJobItemLocation_Execute(t *testing.T) {
 
 		expectedResult := domain.JobResult{
 			Request: &jobRequest,
-			Result:  "",
-			Err:     "failed to create job",
+			Outcome: domain.OutcomeFailed,
+			Action:  expectedJob.Action,
+			Job:     expectedJob,
+			Error:   &domain.JobError{Code: domain.ErrorUnknown, Message: "failed to create job"},
 		}
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.CreateJobFunc = func(job *domain.Job) (interface{}, error) {
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
 			assert.Equal(t, expectedJob, job)
-			return "", errors.New("failed to create job")
+			return nil, errors.New("failed to create job")
 		}
 
 		rj.API = mockAPI
 
-		result := rj.Execute(jobRequest)
+		result := rj.Execute(context.Background(), jobRequest)
 		assert.Equal(t, expectedResult, result)
 	})
 }
@@ -0,0 +1,187 @@
+package tasks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+// Expansion computes the locations of a job from the locations of the job request.
+// Every requested location is expanded, and a location found more than once is kept only the first time.
+// The description is used to explain how the locations of a job are found.
+type Expansion struct {
+	Description string
+	Expand      func(ctx context.Context, api JobAPI, locations []domain.Location) ([]domain.Location, error)
+}
+
+// expandEach expands every location with the given function, skipping duplicates.
+func expandEach(locations []domain.Location, expand func(location domain.Location) ([]domain.Location, error)) ([]domain.Location, error) {
+	seen := map[int]bool{}
+	var expanded []domain.Location
+	for _, location := range locations {
+		found, err := expand(location)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, l := range found {
+			if !seen[l.ID] {
+				seen[l.ID] = true
+				expanded = append(expanded, l)
+			}
+		}
+	}
+
+	return expanded, nil
+}
+
+// Explicit uses the requested locations.
+func Explicit() Expansion {
+	return Expansion{
+		Description: "requested locations",
+		Expand: func(ctx context.Context, api JobAPI, locations []domain.Location) ([]domain.Location, error) {
+			return expandEach(locations, func(location domain.Location) ([]domain.Location, error) {
+				return []domain.Location{location}, nil
+			})
+		},
+	}
+}
+
+// FloorRooms uses the locations of type "Room" whose parent is a requested location.
+func FloorRooms() Expansion {
+	return Expansion{
+		Description: "rooms on the requested floors",
+		Expand: func(ctx context.Context, api JobAPI, locations []domain.Location) ([]domain.Location, error) {
+			return expandEach(locations, func(floor domain.Location) ([]domain.Location, error) {
+				return api.GetFloorRooms(ctx, floor.ID)
+			})
+		},
+	}
+}
+
+// FloorLocations uses the locations whose parent is a requested location.
+func FloorLocations() Expansion {
+	return Expansion{
+		Description: "locations on the requested floors",
+		Expand: func(ctx context.Context, api JobAPI, locations []domain.Location) ([]domain.Location, error) {
+			return expandEach(locations, func(floor domain.Location) ([]domain.Location, error) {
+				return api.GetFloorLocations(ctx, floor.ID)
+			})
+		},
+	}
+}
+
+// treeExpansion is an expansion using the location tree of the property.
+func treeExpansion(description string, expand func(tree *domain.LocationTree, location domain.Location) []domain.Location) Expansion {
+	return Expansion{
+		Description: description,
+		Expand: func(ctx context.Context, api JobAPI, locations []domain.Location) ([]domain.Location, error) {
+			tree, err := api.GetLocationTree(ctx)
+			if err != nil {
+				return nil, err
+			}
+
+			return expandEach(locations, func(location domain.Location) ([]domain.Location, error) {
+				return expand(tree, location), nil
+			})
+		},
+	}
+}
+
+// Descendants uses the locations under a requested location at any level, e.g. the rooms and areas of a building.
+func Descendants() Expansion {
+	return treeExpansion("locations under the requested locations", func(tree *domain.LocationTree, location domain.Location) []domain.Location {
+		return tree.Descendants(location.ID)
+	})
+}
+
+// Siblings uses the other locations sharing the parent of a requested location.
+func Siblings() Expansion {
+	return treeExpansion("locations next to the requested locations", func(tree *domain.LocationTree, location domain.Location) []domain.Location {
+		if location.ParentLocation == nil {
+			return nil
+		}
+
+		var siblings []domain.Location
+		for _, sibling := range tree.Children(location.ParentLocation.ID) {
+			if sibling.ID != location.ID {
+				siblings = append(siblings, sibling)
+			}
+		}
+
+		return siblings
+	})
+}
+
+// Building uses the locations under the building of a requested location, which is the requested location
+// itself or its closest ancestor of type "Building". Locations outside of a building are not expanded.
+func Building() Expansion {
+	return treeExpansion("locations in the buildings of the requested locations", func(tree *domain.LocationTree, location domain.Location) []domain.Location {
+		if location.LocationType != nil && location.LocationType.DisplayName == "Building" {
+			return tree.Descendants(location.ID)
+		}
+
+		for _, ancestor := range tree.Ancestors(location.ID) {
+			if ancestor.LocationType != nil && ancestor.LocationType.DisplayName == "Building" {
+				return tree.Descendants(ancestor.ID)
+			}
+		}
+
+		return nil
+	})
+}
+
+// filter keeps the expanded locations satisfying keep.
+func (e Expansion) filter(description string, keep func(location domain.Location) bool) Expansion {
+	return Expansion{
+		Description: e.Description + description,
+		Expand: func(ctx context.Context, api JobAPI, locations []domain.Location) ([]domain.Location, error) {
+			expanded, err := e.Expand(ctx, api, locations)
+			if err != nil {
+				return nil, err
+			}
+
+			var kept []domain.Location
+			for _, location := range expanded {
+				if keep(location) {
+					kept = append(kept, location)
+				}
+			}
+
+			return kept, nil
+		},
+	}
+}
+
+// OfType keeps the expanded locations with the location type display name, e.g. "Room".
+func (e Expansion) OfType(locationType string) Expansion {
+	return e.filter(fmt.Sprintf(" of type %q", locationType), func(location domain.Location) bool {
+		return location.LocationType != nil && location.LocationType.DisplayName == locationType
+	})
+}
+
+// Excluding drops the expanded locations with one of the statuses, e.g. "Occupied" or "Blocked".
+func (e Expansion) Excluding(statuses ...string) Expansion {
+	excluded := map[string]bool{}
+	for _, status := range statuses {
+		excluded[status] = true
+	}
+
+	return e.filter(fmt.Sprintf(" excluding %s", strings.Join(statuses, ", ")), func(location domain.Location) bool {
+		return !excluded[location.Status]
+	})
+}
+
+// LocationsOfType returns the locations with the location type display name, e.g. "Floor".
+func LocationsOfType(locations []domain.Location, locationType string) []domain.Location {
+	var typed []domain.Location
+	for _, location := range locations {
+		if location.LocationType != nil && location.LocationType.DisplayName == locationType {
+			typed = append(typed, location)
+		}
+	}
+
+	return typed
+}
@@ -0,0 +1,106 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/tasks/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLocation(id int, parentID int, locationType string, status string) domain.Location {
+	l := domain.Location{ID: id, LocationType: &domain.LocationType{DisplayName: locationType}, Status: status}
+	if parentID != 0 {
+		l.ParentLocation = &domain.ParentLocation{ID: parentID}
+	}
+	return l
+}
+
+func TestExpansion(t *testing.T) {
+	property := newLocation(1, 0, "Property", "")
+	building := newLocation(2, 1, "Building", "")
+	floor1 := newLocation(3, 2, "Floor", "")
+	floor2 := newLocation(4, 2, "Floor", "")
+	room1 := newLocation(5, 3, "Room", "Occupied")
+	room2 := newLocation(6, 3, "Room", "")
+	area := newLocation(7, 3, "Area", "")
+	room3 := newLocation(8, 4, "Room", "Blocked")
+	room4 := newLocation(9, 4, "Room", "")
+
+	tree := domain.NewLocationTree([]domain.Location{property, building, floor1, floor2, room1, room2, area, room3, room4})
+	api := &mock.JobAPIMock{
+		GetLocationTreeFunc: func(ctx context.Context) (*domain.LocationTree, error) {
+			return tree, nil
+		},
+	}
+
+	expand := func(e Expansion, locations ...domain.Location) []domain.Location {
+		expanded, err := e.Expand(context.Background(), api, locations)
+		assert.NoError(t, err)
+		return expanded
+	}
+
+	t.Run("should use the requested locations without duplicates", func(t *testing.T) {
+		assert.Equal(t, []domain.Location{room1, room2}, expand(Explicit(), room1, room2, room1))
+	})
+
+	t.Run("should use the descendants of every requested location", func(t *testing.T) {
+		assert.Equal(t, []domain.Location{room1, room2, area, room3, room4}, expand(Descendants(), floor1, floor2))
+		assert.Equal(t, []domain.Location{floor1, room1, room2, area, floor2, room3, room4}, expand(Descendants(), building, floor1))
+	})
+
+	t.Run("should use the siblings of the requested locations", func(t *testing.T) {
+		assert.Equal(t, []domain.Location{room2, area, room4}, expand(Siblings(), room1, room3))
+		assert.Empty(t, expand(Siblings(), property))
+	})
+
+	t.Run("should use the locations of the building", func(t *testing.T) {
+		assert.Equal(t, []domain.Location{floor1, room1, room2, area, floor2, room3, room4}, expand(Building(), room1, room3))
+		assert.Empty(t, expand(Building(), property))
+	})
+
+	t.Run("should keep the locations of the type", func(t *testing.T) {
+		e := Building().OfType("Room")
+		assert.Equal(t, []domain.Location{room1, room2, room3, room4}, expand(e, floor1))
+		assert.Equal(t, `locations in the buildings of the requested locations of type "Room"`, e.Description)
+	})
+
+	t.Run("should drop the locations with an excluded status", func(t *testing.T) {
+		e := Descendants().OfType("Room").Excluding("Occupied", "Blocked")
+		assert.Equal(t, []domain.Location{room2, room4}, expand(e, floor1, floor2))
+		assert.Equal(t, `locations under the requested locations of type "Room" excluding Occupied, Blocked`, e.Description)
+	})
+
+	t.Run("should expand the rooms of every floor", func(t *testing.T) {
+		api := &mock.JobAPIMock{
+			GetFloorRoomsFunc: func(ctx context.Context, floorID int) ([]domain.Location, error) {
+				return tree.ChildrenOfType(floorID, "Room"), nil
+			},
+		}
+
+		expanded, err := FloorRooms().Expand(context.Background(), api, []domain.Location{floor1, floor2})
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Location{room1, room2, room3, room4}, expanded)
+	})
+
+	t.Run("should return the error loading the tree", func(t *testing.T) {
+		api := &mock.JobAPIMock{
+			GetLocationTreeFunc: func(ctx context.Context) (*domain.LocationTree, error) {
+				return nil, errors.New("unavailable")
+			},
+		}
+
+		_, err := Siblings().Expand(context.Background(), api, []domain.Location{room1})
+		assert.EqualError(t, err, "unavailable")
+	})
+}
+
+func TestLocationsOfType(t *testing.T) {
+	floor := newLocation(1, 0, "Floor", "")
+	room := newLocation(2, 1, "Room", "")
+
+	assert.Equal(t, []domain.Location{floor}, LocationsOfType([]domain.Location{room, floor}, "Floor"))
+	assert.Empty(t, LocationsOfType([]domain.Location{room}, "Floor"))
+}
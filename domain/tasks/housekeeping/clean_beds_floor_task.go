@@ -1,7 +1,7 @@
 package housekeeping
 
 import (
-	"regexp"
+	"context"
 
 	"github.com/Twsouza/job-rule-engine/domain"
 	"github.com/Twsouza/job-rule-engine/domain/tasks"
@@ -11,6 +11,18 @@ type CleanBedsFloor struct {
 	API tasks.JobAPI
 }
 
+// cleanBedsFloorConditions are the conditions to clean the beds in all rooms on a floor.
+var cleanBedsFloorConditions = tasks.Conditions{
+	tasks.DepartmentIs("Housekeeping"),
+	tasks.JobItemMatches(bedItems),
+	tasks.HasLocationType("Floor"),
+}
+
+// Name returns the name the task is registered with.
+func (cr *CleanBedsFloor) Name() string {
+	return "CleanBedsFloor"
+}
+
 // AssertRule checks if the given job request satisfies the conditions for clean the beds in all rooms with a location type of ‘Room’ on that floor.
 // It returns true if the job request meets the following criteria:
 // - The job request must have a non-nil Department with the name "Housekeeping".
@@ -18,30 +30,16 @@ type CleanBedsFloor struct {
 // - The job request must have at least one Location with a LocationType that has a display name of "Floor".
 // Otherwise, it returns false.
 func (cr *CleanBedsFloor) AssertRule(jobRequest domain.JobRequest) bool {
-	if jobRequest.Department == nil || jobRequest.Department.Name != "Housekeeping" || jobRequest.JobItem == nil {
-		return false
-	}
-
-	match, err := regexp.MatchString(`(?i)\b(?:Blanket|Sheets|Mattress)\b`, jobRequest.JobItem.DisplayName)
-	if err != nil || !match {
-		return false
-	}
-
-	for _, location := range jobRequest.Locations {
-		if location.LocationType != nil && location.LocationType.DisplayName == "Floor" {
-			return true
-		}
-	}
-
-	return false
+	return cleanBedsFloorConditions.Assert(jobRequest)
 }
 
-// Execute will create a job to clean the beds in all rooms with a location type of ‘Room’ on that floor.
-func (cr *CleanBedsFloor) Execute(jobRequest domain.JobRequest) domain.JobResult {
-	jr := domain.JobResult{
-		Request: &jobRequest,
-	}
+// Explain returns the outcome of each condition of AssertRule.
+func (cr *CleanBedsFloor) Explain(jobRequest domain.JobRequest) []domain.ConditionTrace {
+	return cleanBedsFloorConditions.Explain(jobRequest)
+}
 
+// Plan builds the job to clean the beds in all rooms with a location type of ‘Room’ on that floor.
+func (cr *CleanBedsFloor) Plan(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error) {
 	job := &domain.Job{
 		Action: "clean",
 		Department: domain.JDepartment{
@@ -52,10 +50,11 @@ func (cr *CleanBedsFloor) Execute(jobRequest domain.JobRequest) domain.JobResult
 		},
 	}
 
-	locations, err := cr.API.GetFloorRooms(jobRequest.Locations[0].ID)
+	// The rooms of every requested floor
+	floors := tasks.LocationsOfType(jobRequest.Locations, "Floor")
+	locations, err := tasks.FloorRooms().Expand(ctx, cr.API, floors)
 	if err != nil {
-		jr.Err = err.Error()
-		return jr
+		return nil, err
 	}
 
 	for _, location := range locations {
@@ -65,15 +64,13 @@ func (cr *CleanBedsFloor) Execute(jobRequest domain.JobRequest) domain.JobResult
 	}
 
 	if len(job.Locations) == 0 {
-		jr.Err = "no locations found for this job"
-		return jr
+		return nil, tasks.ErrNoLocations
 	}
 
-	result, err := cr.API.CreateJob(job)
-	if err != nil {
-		jr.Err = err.Error()
-	}
-	jr.Result = result
+	return job, nil
+}
 
-	return jr
+// Execute will create a job to clean the beds in all rooms with a location type of ‘Room’ on that floor.
+func (cr *CleanBedsFloor) Execute(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
+	return tasks.ExecutePlan(ctx, cr.API, cr, jobRequest)
 }
@@ -1,6 +1,7 @@
 package housekeeping
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -131,6 +132,33 @@ func TestCleanBedsFloor_AssertRule(t *testing.T) {
 	})
 }
 
+func TestCleanBedsFloor_Explain(t *testing.T) {
+	cr := &CleanBedsFloor{}
+
+	jobRequest := domain.JobRequest{
+		Department: &domain.Department{
+			Name: "Housekeeping",
+		},
+		JobItem: &domain.JobItem{
+			DisplayName: "Pillow",
+		},
+		Locations: []domain.Location{
+			{
+				LocationType: &domain.LocationType{
+					DisplayName: "Floor",
+				},
+			},
+		},
+	}
+
+	expected := []domain.ConditionTrace{
+		{Condition: `department is "Housekeeping"`, Passed: true},
+		{Condition: `job item matches "(?i)\\b(?:Blanket|Sheets|Mattress)\\b"`, Passed: false},
+		{Condition: `at least one location of type "Floor"`, Passed: true},
+	}
+	assert.Equal(t, expected, cr.Explain(jobRequest))
+}
+
 func TestCleanBedsFloor_Execute(t *testing.T) {
 	cr := &CleanBedsFloor{}
 
@@ -170,24 +198,73 @@ func TestCleanBedsFloor_Execute(t *testing.T) {
 
 		expectedResult := domain.JobResult{
 			Request: &jobRequest,
-			Result:  "job created",
+			Outcome: domain.OutcomeCreated,
+			Action:  expectedJob.Action,
+			Job:     expectedJob,
+			Created: &domain.JobCreated{ID: 1},
 		}
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.CreateJobFunc = func(job *domain.Job) (interface{}, error) {
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
 			assert.Equal(t, expectedJob, job)
-			return "job created", nil
+			return &domain.JobCreated{ID: 1}, nil
 		}
-		mockAPI.GetFloorRoomsFunc = func(floorID int) ([]domain.Location, error) {
+		mockAPI.GetFloorRoomsFunc = func(ctx context.Context, floorID int) ([]domain.Location, error) {
 			return []domain.Location{{ID: 1}}, nil
 		}
 
 		cr.API = mockAPI
 
-		result := cr.Execute(jobRequest)
+		result := cr.Execute(context.Background(), jobRequest)
 		assert.Equal(t, expectedResult, result)
 	})
 
+	t.Run("should clean the rooms of every requested floor", func(t *testing.T) {
+		jobRequest := domain.JobRequest{
+			Department: &domain.Department{
+				ID:   1,
+				Name: "Housekeeping",
+			},
+			JobItem: &domain.JobItem{
+				DisplayName: "Sheets",
+			},
+			Locations: []domain.Location{
+				{
+					ID: 5,
+					LocationType: &domain.LocationType{
+						DisplayName: "Room",
+					},
+				},
+				{
+					ID: 1,
+					LocationType: &domain.LocationType{
+						DisplayName: "Floor",
+					},
+				},
+				{
+					ID: 2,
+					LocationType: &domain.LocationType{
+						DisplayName: "Floor",
+					},
+				},
+			},
+		}
+
+		mockAPI := &mock.JobAPIMock{}
+		mockAPI.GetFloorRoomsFunc = func(ctx context.Context, floorID int) ([]domain.Location, error) {
+			return []domain.Location{{ID: floorID * 100}, {ID: floorID*100 + 1}}, nil
+		}
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
+			assert.Equal(t, []domain.JLocation{{ID: 100}, {ID: 101}, {ID: 200}, {ID: 201}}, job.Locations)
+			return &domain.JobCreated{ID: 1}, nil
+		}
+
+		cr.API = mockAPI
+
+		result := cr.Execute(context.Background(), jobRequest)
+		assert.Equal(t, domain.OutcomeCreated, result.Outcome)
+	})
+
 	t.Run("should return error if GetFloorRooms fails", func(t *testing.T) {
 		jobRequest := domain.JobRequest{
 			Department: &domain.Department{
@@ -210,14 +287,14 @@ func TestCleanBedsFloor_Execute(t *testing.T) {
 		expectedError := errors.New("failed to get floor rooms")
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.GetFloorRoomsFunc = func(floorID int) ([]domain.Location, error) {
+		mockAPI.GetFloorRoomsFunc = func(ctx context.Context, floorID int) ([]domain.Location, error) {
 			return nil, expectedError
 		}
 
 		cr.API = mockAPI
 
-		result := cr.Execute(jobRequest)
-		assert.Equal(t, expectedError.Error(), result.Err)
+		result := cr.Execute(context.Background(), jobRequest)
+		assert.EqualError(t, result.Error, expectedError.Error())
 	})
 
 	t.Run("should return error if CreateJob fails", func(t *testing.T) {
@@ -257,17 +334,17 @@ func TestCleanBedsFloor_Execute(t *testing.T) {
 		expectedError := errors.New("failed to create job")
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.GetFloorRoomsFunc = func(floorID int) ([]domain.Location, error) {
+		mockAPI.GetFloorRoomsFunc = func(ctx context.Context, floorID int) ([]domain.Location, error) {
 			return []domain.Location{{ID: 1}}, nil
 		}
-		mockAPI.CreateJobFunc = func(job *domain.Job) (interface{}, error) {
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
 			assert.Equal(t, expectedJob, job)
-			return "", expectedError
+			return nil, expectedError
 		}
 
 		cr.API = mockAPI
 
-		result := cr.Execute(jobRequest)
-		assert.Equal(t, expectedError.Error(), result.Err)
+		result := cr.Execute(context.Background(), jobRequest)
+		assert.EqualError(t, result.Error, expectedError.Error())
 	})
 }
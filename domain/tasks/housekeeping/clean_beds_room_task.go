@@ -1,16 +1,32 @@
 package housekeeping
 
 import (
+	"context"
 	"regexp"
 
 	"github.com/Twsouza/job-rule-engine/domain"
 	"github.com/Twsouza/job-rule-engine/domain/tasks"
 )
 
+// bedItems matches the job items related to beds.
+var bedItems = regexp.MustCompile(`(?i)\b(?:Blanket|Sheets|Mattress)\b`)
+
 type CleanBedsRoom struct {
 	API tasks.JobAPI
 }
 
+// cleanBedsRoomConditions are the conditions to clean the beds in a room.
+var cleanBedsRoomConditions = tasks.Conditions{
+	tasks.DepartmentIs("Housekeeping"),
+	tasks.JobItemMatches(bedItems),
+	tasks.HasLocationType("Room"),
+}
+
+// Name returns the name the task is registered with.
+func (cr *CleanBedsRoom) Name() string {
+	return "CleanBedsRoom"
+}
+
 // AssertRule checks if the given job request satisfies the conditions to clean beds in a room.
 // It returns true if the job request meets the following criteria:
 // - The department is "Housekeeping"
@@ -18,26 +34,16 @@ type CleanBedsRoom struct {
 // - At least one location has a location type of "Room"
 // Otherwise, it returns false.
 func (cr *CleanBedsRoom) AssertRule(jobRequest domain.JobRequest) bool {
-	if jobRequest.Department == nil || jobRequest.Department.Name != "Housekeeping" || jobRequest.JobItem == nil {
-		return false
-	}
-
-	match, err := regexp.MatchString(`(?i)\b(?:Blanket|Sheets|Mattress)\b`, jobRequest.JobItem.DisplayName)
-	if err != nil || !match {
-		return false
-	}
-
-	for _, location := range jobRequest.Locations {
-		if location.LocationType != nil && location.LocationType.DisplayName == "Room" {
-			return true
-		}
-	}
+	return cleanBedsRoomConditions.Assert(jobRequest)
+}
 
-	return false
+// Explain returns the outcome of each condition of AssertRule.
+func (cr *CleanBedsRoom) Explain(jobRequest domain.JobRequest) []domain.ConditionTrace {
+	return cleanBedsRoomConditions.Explain(jobRequest)
 }
 
-// Execute will create a job to clean the bed(s) in the given room
-func (cr *CleanBedsRoom) Execute(jobRequest domain.JobRequest) domain.JobResult {
+// Plan builds the job to clean the bed(s) in the given room
+func (cr *CleanBedsRoom) Plan(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error) {
 	job := &domain.Job{
 		Action: "clean",
 		Department: domain.JDepartment{
@@ -57,22 +63,13 @@ func (cr *CleanBedsRoom) Execute(jobRequest domain.JobRequest) domain.JobResult
 	}
 
 	if len(job.Locations) == 0 {
-		return domain.JobResult{
-			Request: &jobRequest,
-			Result:  nil,
-			Err:     "no locations found for this job",
-		}
+		return nil, tasks.ErrNoLocations
 	}
 
-	var errMsg string
-	result, err := cr.API.CreateJob(job)
-	if err != nil {
-		errMsg = err.Error()
-	}
+	return job, nil
+}
 
-	return domain.JobResult{
-		Request: &jobRequest,
-		Result:  result,
-		Err:     errMsg,
-	}
+// Execute will create a job to clean the bed(s) in the given room
+func (cr *CleanBedsRoom) Execute(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
+	return tasks.ExecutePlan(ctx, cr.API, cr, jobRequest)
 }
@@ -1,6 +1,7 @@
 package housekeeping
 
 import (
+	"context"
 	"testing"
 
 	"github.com/Twsouza/job-rule-engine/domain"
@@ -169,19 +170,21 @@ func TestCleanBedsRoom_Execute(t *testing.T) {
 
 		expectedResult := domain.JobResult{
 			Request: &jobRequest,
-			Result:  "success",
-			Err:     "",
+			Outcome: domain.OutcomeCreated,
+			Action:  expectedJob.Action,
+			Job:     expectedJob,
+			Created: &domain.JobCreated{ID: 1},
 		}
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.CreateJobFunc = func(job *domain.Job) (interface{}, error) {
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
 			assert.Equal(t, expectedJob, job)
-			return "success", nil
+			return &domain.JobCreated{ID: 1}, nil
 		}
 
 		cr.API = mockAPI
 
-		result := cr.Execute(jobRequest)
+		result := cr.Execute(context.Background(), jobRequest)
 		assert.Equal(t, expectedResult, result)
 	})
 
@@ -206,19 +209,19 @@ func TestCleanBedsRoom_Execute(t *testing.T) {
 
 		expectedResult := domain.JobResult{
 			Request: &jobRequest,
-			Result:  nil,
-			Err:     "no locations found for this job",
+			Outcome: domain.OutcomeSkipped,
+			Error:   &domain.JobError{Code: domain.ErrorNoLocations, Message: "no locations found for this job"},
 		}
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.CreateJobFunc = func(job *domain.Job) (interface{}, error) {
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
 			t.Error("CreateJob should not be called")
-			return "", nil
+			return nil, nil
 		}
 
 		cr.API = mockAPI
 
-		result := cr.Execute(jobRequest)
+		result := cr.Execute(context.Background(), jobRequest)
 		assert.Equal(t, expectedResult, result)
 	})
 }
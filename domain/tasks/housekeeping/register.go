@@ -0,0 +1,12 @@
+package housekeeping
+
+import "github.com/Twsouza/job-rule-engine/domain/tasks"
+
+func init() {
+	tasks.Register("CleanBedsFloor", func(api tasks.JobAPI) tasks.JobTask {
+		return &CleanBedsFloor{API: api}
+	})
+	tasks.Register("CleanBedsRoom", func(api tasks.JobAPI) tasks.JobTask {
+		return &CleanBedsRoom{API: api}
+	})
+}
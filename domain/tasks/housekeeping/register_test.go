@@ -0,0 +1,20 @@
+package housekeeping
+
+import (
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain/tasks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	t.Run("should register the housekeeping tasks", func(t *testing.T) {
+		assert.Subset(t, tasks.Registered(), []string{"CleanBedsFloor", "CleanBedsRoom"})
+
+		names := []string{}
+		for _, task := range tasks.NewRegistered(nil) {
+			names = append(names, tasks.NameOf(task))
+		}
+		assert.Equal(t, []string{"CleanBedsFloor", "CleanBedsRoom"}, names)
+	})
+}
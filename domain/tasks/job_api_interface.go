@@ -1,9 +1,14 @@
 package tasks
 
-import "github.com/Twsouza/job-rule-engine/domain"
+import (
+	"context"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
 
 type JobAPI interface {
-	CreateJob(job *domain.Job) (interface{}, error)
-	GetFloorRooms(floorID int) ([]domain.Location, error)
-	GetFloorLocations(floorID int) ([]domain.Location, error)
+	CreateJob(ctx context.Context, job *domain.Job) (*domain.JobCreated, error)
+	GetFloorRooms(ctx context.Context, floorID int) ([]domain.Location, error)
+	GetFloorLocations(ctx context.Context, floorID int) ([]domain.Location, error)
+	GetLocationTree(ctx context.Context) (*domain.LocationTree, error)
 }
@@ -0,0 +1,153 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"reflect"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/logging"
+)
+
+// NamedTask is implemented by tasks with a name, see NameOf.
+type NamedTask interface {
+	Name() string
+}
+
+// ExplainableTask is implemented by tasks that can explain the outcome of each of their conditions.
+type ExplainableTask interface {
+	Explain(jobRequest domain.JobRequest) []domain.ConditionTrace
+}
+
+// JobPlanner is implemented by tasks that can build the job they would send to Optii without creating it.
+type JobPlanner interface {
+	Plan(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error)
+}
+
+// NameOf returns the name of the given task, or the name of its type when it doesn't implement NamedTask.
+func NameOf(t JobTask) string {
+	if n, ok := t.(NamedTask); ok {
+		return n.Name()
+	}
+
+	return reflect.Indirect(reflect.ValueOf(t)).Type().Name()
+}
+
+// ErrNoLocations is returned by JobPlanner when no locations are found for the job.
+var ErrNoLocations = domain.ErrNoLocations
+
+// ErrNoPlan is the error of the tasks which can't plan their job on a dry run, see DryRun.
+var ErrNoPlan = errors.New("rule can't plan its job without creating it")
+
+type dryRunKey struct{}
+
+// WithDryRun returns a copy of the context in which the jobs are only planned, see DryRun.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun returns true if the context was returned by WithDryRun.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
+
+// DryRun plans the job of the task without creating it, and returns its result with the domain.OutcomeDryRun outcome.
+// The task is never executed, a task which isn't a JobPlanner is skipped with ErrNoPlan.
+func DryRun(ctx context.Context, t JobTask, jobRequest domain.JobRequest) domain.JobResult {
+	planner, ok := t.(JobPlanner)
+	if !ok {
+		return domain.JobResult{
+			Request: &jobRequest,
+			Outcome: domain.OutcomeSkipped,
+			Error:   NewJobError(ErrNoPlan),
+		}
+	}
+
+	jr, job := plan(ctx, planner, jobRequest)
+	if job != nil {
+		jr.Outcome = domain.OutcomeDryRun
+	}
+
+	return jr
+}
+
+// ExecutePlan creates the job planned by the planner and returns its result.
+// A plan failing with ErrNoLocations is skipped, the other errors fail the result, see NewJobError.
+// The planned job is logged at debug level with the logger of the context, see logging.FromContext.
+func ExecutePlan(ctx context.Context, api JobAPI, planner JobPlanner, jobRequest domain.JobRequest) domain.JobResult {
+	jr, job := plan(ctx, planner, jobRequest)
+	if job == nil {
+		return jr
+	}
+	logging.FromContext(ctx).DebugContext(ctx, "creating job", "action", job.Action, "locations", len(job.Locations))
+
+	created, err := api.CreateJob(ctx, job)
+	if err != nil {
+		jr.Error = NewJobError(err)
+		jr.Outcome = domain.OutcomeFailed
+		return jr
+	}
+	jr.Created = created
+	jr.Outcome = domain.OutcomeCreated
+
+	return jr
+}
+
+// plan returns the result of the job planned by the planner, and the job, nil when the plan failed.
+func plan(ctx context.Context, planner JobPlanner, jobRequest domain.JobRequest) (domain.JobResult, *domain.Job) {
+	jr := domain.JobResult{
+		Request: &jobRequest,
+	}
+
+	job, err := planner.Plan(ctx, jobRequest)
+	if err != nil {
+		jr.Error = NewJobError(err)
+		jr.Outcome = domain.OutcomeFailed
+		if errors.Is(err, ErrNoLocations) {
+			jr.Outcome = domain.OutcomeSkipped
+		}
+		return jr, nil
+	}
+	jr.Action = job.Action
+	jr.Job = job
+
+	return jr, job
+}
+
+// NewJobError classifies the error of a rule:
+//   - ErrNoLocations is domain.ErrorNoLocations.
+//   - ErrNoPlan is domain.ErrorNoPlan.
+//   - The deadline or the cancellation of the context are domain.ErrorTimeout and domain.ErrorCancelled, both retryable.
+//   - domain.ErrNotFound is domain.ErrorNotFound.
+//   - domain.ErrUpstream is domain.ErrorUpstream, retryable unless the error has a Retryable method returning false,
+//     e.g. when Optii rejected the credentials of the server.
+//   - Any other error with a Retryable method, e.g. a job rejected by Optii, is domain.ErrorRejected.
+//   - Anything else is domain.ErrorUnknown.
+func NewJobError(err error) *domain.JobError {
+	jobErr := &domain.JobError{Code: domain.ErrorUnknown, Message: err.Error()}
+
+	var retryable interface{ Retryable() bool }
+	switch {
+	case errors.Is(err, ErrNoLocations):
+		jobErr.Code = domain.ErrorNoLocations
+	case errors.Is(err, ErrNoPlan):
+		jobErr.Code = domain.ErrorNoPlan
+	case errors.Is(err, context.DeadlineExceeded):
+		jobErr.Code = domain.ErrorTimeout
+		jobErr.Retryable = true
+	case errors.Is(err, context.Canceled):
+		jobErr.Code = domain.ErrorCancelled
+		jobErr.Retryable = true
+	case errors.Is(err, domain.ErrNotFound):
+		jobErr.Code = domain.ErrorNotFound
+	case errors.Is(err, domain.ErrUpstream):
+		jobErr.Code = domain.ErrorUpstream
+		jobErr.Retryable = !errors.As(err, &retryable) || retryable.Retryable()
+	case errors.As(err, &retryable):
+		jobErr.Code = domain.ErrorRejected
+		jobErr.Retryable = retryable.Retryable()
+	}
+
+	return jobErr
+}
@@ -0,0 +1,17 @@
+package tasks
+
+import (
+	"context"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+// JobLifecycleAPI looks up and manages the jobs of Optii, e.g. the ones created by the rules.
+// The JobAPI given to the rules implements it too, a rule can get it with a type assertion.
+type JobLifecycleAPI interface {
+	GetJob(ctx context.Context, id int) (*domain.JobCreated, error)
+	ListJobs(ctx context.Context, filter domain.JobFilter, first int32, next int32) (*domain.JobPage, error)
+	UpdateJob(ctx context.Context, id int, update domain.JobUpdate) (*domain.JobCreated, error)
+	CancelJob(ctx context.Context, id int) (*domain.JobCreated, error)
+	CompleteJob(ctx context.Context, id int) (*domain.JobCreated, error)
+}
@@ -0,0 +1,31 @@
+package tasks
+
+// PrioritizedTask is implemented by tasks with a priority, higher values win.
+// Tasks that don't implement it have priority 0.
+type PrioritizedTask interface {
+	Priority() int
+}
+
+// ExclusiveTask is implemented by tasks that can suppress every other matching task.
+// When an exclusive task matches, only the exclusive task with the highest priority is executed.
+type ExclusiveTask interface {
+	Exclusive() bool
+}
+
+// PriorityOf returns the priority of the given task.
+func PriorityOf(t JobTask) int {
+	if p, ok := t.(PrioritizedTask); ok {
+		return p.Priority()
+	}
+
+	return 0
+}
+
+// IsExclusive returns true if the given task is exclusive.
+func IsExclusive(t JobTask) bool {
+	if e, ok := t.(ExclusiveTask); ok {
+		return e.Exclusive()
+	}
+
+	return false
+}
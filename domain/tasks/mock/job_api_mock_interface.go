@@ -1,21 +1,30 @@
 package mock
 
-import "github.com/Twsouza/job-rule-engine/domain"
+import (
+	"context"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
 
 type JobAPIMock struct {
-	CreateJobFunc         func(job *domain.Job) (interface{}, error)
-	GetFloorRoomsFunc     func(floorID int) ([]domain.Location, error)
-	GetFloorLocationsFunc func(floorID int) ([]domain.Location, error)
+	CreateJobFunc         func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error)
+	GetFloorRoomsFunc     func(ctx context.Context, floorID int) ([]domain.Location, error)
+	GetFloorLocationsFunc func(ctx context.Context, floorID int) ([]domain.Location, error)
+	GetLocationTreeFunc   func(ctx context.Context) (*domain.LocationTree, error)
+}
+
+func (m *JobAPIMock) CreateJob(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
+	return m.CreateJobFunc(ctx, job)
 }
 
-func (m *JobAPIMock) CreateJob(job *domain.Job) (interface{}, error) {
-	return m.CreateJobFunc(job)
+func (m *JobAPIMock) GetFloorRooms(ctx context.Context, floorID int) ([]domain.Location, error) {
+	return m.GetFloorRoomsFunc(ctx, floorID)
 }
 
-func (m *JobAPIMock) GetFloorRooms(floorID int) ([]domain.Location, error) {
-	return m.GetFloorRoomsFunc(floorID)
+func (m *JobAPIMock) GetFloorLocations(ctx context.Context, floorID int) ([]domain.Location, error) {
+	return m.GetFloorLocationsFunc(ctx, floorID)
 }
 
-func (m *JobAPIMock) GetFloorLocations(floorID int) ([]domain.Location, error) {
-	return m.GetFloorLocationsFunc(floorID)
+func (m *JobAPIMock) GetLocationTree(ctx context.Context) (*domain.LocationTree, error) {
+	return m.GetLocationTreeFunc(ctx)
 }
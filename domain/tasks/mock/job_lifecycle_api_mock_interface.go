@@ -0,0 +1,35 @@
+package mock
+
+import (
+	"context"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+type JobLifecycleAPIMock struct {
+	GetJobFunc      func(ctx context.Context, id int) (*domain.JobCreated, error)
+	ListJobsFunc    func(ctx context.Context, filter domain.JobFilter, first int32, next int32) (*domain.JobPage, error)
+	UpdateJobFunc   func(ctx context.Context, id int, update domain.JobUpdate) (*domain.JobCreated, error)
+	CancelJobFunc   func(ctx context.Context, id int) (*domain.JobCreated, error)
+	CompleteJobFunc func(ctx context.Context, id int) (*domain.JobCreated, error)
+}
+
+func (m *JobLifecycleAPIMock) GetJob(ctx context.Context, id int) (*domain.JobCreated, error) {
+	return m.GetJobFunc(ctx, id)
+}
+
+func (m *JobLifecycleAPIMock) ListJobs(ctx context.Context, filter domain.JobFilter, first int32, next int32) (*domain.JobPage, error) {
+	return m.ListJobsFunc(ctx, filter, first, next)
+}
+
+func (m *JobLifecycleAPIMock) UpdateJob(ctx context.Context, id int, update domain.JobUpdate) (*domain.JobCreated, error) {
+	return m.UpdateJobFunc(ctx, id, update)
+}
+
+func (m *JobLifecycleAPIMock) CancelJob(ctx context.Context, id int) (*domain.JobCreated, error) {
+	return m.CancelJobFunc(ctx, id)
+}
+
+func (m *JobLifecycleAPIMock) CompleteJob(ctx context.Context, id int) (*domain.JobCreated, error) {
+	return m.CompleteJobFunc(ctx, id)
+}
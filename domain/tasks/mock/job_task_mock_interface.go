@@ -1,16 +1,50 @@
 package mock
 
-import "github.com/Twsouza/job-rule-engine/domain"
+import (
+	"context"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
 
 type MockRule struct {
-	AssertFunc  func(jobRequest domain.JobRequest) bool
-	ExecuteFunc func(jobRequest domain.JobRequest) domain.JobResult
+	AssertFunc     func(jobRequest domain.JobRequest) bool
+	ExecuteFunc    func(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult
+	PriorityValue  int
+	ExclusiveValue bool
 }
 
 func (mr *MockRule) AssertRule(jobRequest domain.JobRequest) bool {
 	return mr.AssertFunc(jobRequest)
 }
 
-func (mr *MockRule) Execute(jobRequest domain.JobRequest) domain.JobResult {
-	return mr.ExecuteFunc(jobRequest)
+func (mr *MockRule) Execute(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
+	return mr.ExecuteFunc(ctx, jobRequest)
+}
+
+func (mr *MockRule) Priority() int {
+	return mr.PriorityValue
+}
+
+func (mr *MockRule) Exclusive() bool {
+	return mr.ExclusiveValue
+}
+
+// MockExplainableRule is a MockRule that also implements tasks.ExplainableTask and tasks.JobPlanner.
+type MockExplainableRule struct {
+	MockRule
+	NameValue   string
+	ExplainFunc func(jobRequest domain.JobRequest) []domain.ConditionTrace
+	PlanFunc    func(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error)
+}
+
+func (mr *MockExplainableRule) Name() string {
+	return mr.NameValue
+}
+
+func (mr *MockExplainableRule) Explain(jobRequest domain.JobRequest) []domain.ConditionTrace {
+	return mr.ExplainFunc(jobRequest)
+}
+
+func (mr *MockExplainableRule) Plan(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error) {
+	return mr.PlanFunc(ctx, jobRequest)
 }
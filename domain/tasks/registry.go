@@ -0,0 +1,109 @@
+package tasks
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Constructor creates a task that uses the given API.
+type Constructor func(api JobAPI) JobTask
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Constructor{}
+)
+
+// Register makes a task constructor available by name, packages usually call it in their init function.
+// It panics if the name is empty, the constructor is nil or the name is already registered.
+func Register(name string, constructor Constructor) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if name == "" || constructor == nil {
+		panic("tasks: Register requires a name and a constructor")
+	}
+	if _, ok := registry[name]; ok {
+		panic(fmt.Sprintf("tasks: Register called twice for task %s", name))
+	}
+
+	registry[name] = constructor
+}
+
+// Registered returns the names of the registered tasks, sorted alphabetically.
+func Registered() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	return sortedNames()
+}
+
+// NewRegistered creates every registered task using the given API, sorted by name.
+func NewRegistered(api JobAPI) []JobTask {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := sortedNames()
+	taskList := make([]JobTask, 0, len(names))
+	for _, name := range names {
+		taskList = append(taskList, registry[name](api))
+	}
+
+	return taskList
+}
+
+// sortedNames returns the registered names, the caller must hold registryMu.
+func sortedNames() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Select filters the tasks by name, see NameOf.
+// When enabled is not empty only the tasks it names are kept, then the tasks named in disabled are removed.
+// It returns an error if two tasks share the same name, or if a name in enabled or disabled
+// doesn't match any task, to catch typos in the configuration.
+func Select(taskList []JobTask, enabled, disabled []string) ([]JobTask, error) {
+	names := map[string]bool{}
+	for _, t := range taskList {
+		name := NameOf(t)
+		if names[name] {
+			return nil, fmt.Errorf("duplicated rule name %s", name)
+		}
+		names[name] = true
+	}
+
+	enabledSet := map[string]bool{}
+	for _, name := range enabled {
+		if !names[name] {
+			return nil, fmt.Errorf("unknown rule %s in the enabled rules", name)
+		}
+		enabledSet[name] = true
+	}
+
+	disabledSet := map[string]bool{}
+	for _, name := range disabled {
+		if !names[name] {
+			return nil, fmt.Errorf("unknown rule %s in the disabled rules", name)
+		}
+		disabledSet[name] = true
+	}
+
+	selected := []JobTask{}
+	for _, t := range taskList {
+		name := NameOf(t)
+		if len(enabledSet) > 0 && !enabledSet[name] {
+			continue
+		}
+		if disabledSet[name] {
+			continue
+		}
+		selected = append(selected, t)
+	}
+
+	return selected, nil
+}
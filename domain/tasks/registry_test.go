@@ -0,0 +1,100 @@
+package tasks
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type registeredTask struct {
+	JobTask
+	name string
+	api  JobAPI
+}
+
+func (rt *registeredTask) Name() string {
+	return rt.name
+}
+
+func TestRegister(t *testing.T) {
+	Register("test.B", func(api JobAPI) JobTask {
+		return &registeredTask{name: "test.B", api: api}
+	})
+	Register("test.A", func(api JobAPI) JobTask {
+		return &registeredTask{name: "test.A", api: api}
+	})
+
+	t.Run("should return the registered names sorted", func(t *testing.T) {
+		names := Registered()
+		assert.Subset(t, names, []string{"test.A", "test.B"})
+		assert.IsIncreasing(t, names)
+	})
+
+	t.Run("should create the registered tasks with the given API", func(t *testing.T) {
+		api := &createJobAPI{}
+		created := map[string]JobTask{}
+		for _, task := range NewRegistered(api) {
+			created[NameOf(task)] = task
+		}
+
+		if assert.Contains(t, created, "test.A") {
+			assert.Same(t, api, created["test.A"].(*registeredTask).api)
+		}
+		assert.Contains(t, created, "test.B")
+	})
+
+	t.Run("should panic when a name is registered twice", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Register("test.A", func(api JobAPI) JobTask { return nil })
+		})
+	})
+
+	t.Run("should panic without a name or a constructor", func(t *testing.T) {
+		assert.Panics(t, func() {
+			Register("", func(api JobAPI) JobTask { return nil })
+		})
+		assert.Panics(t, func() {
+			Register("test.C", nil)
+		})
+	})
+}
+
+func TestSelect(t *testing.T) {
+	a := &registeredTask{name: "A"}
+	b := &registeredTask{name: "B"}
+	c := &registeredTask{name: "C"}
+	taskList := []JobTask{a, b, c}
+
+	t.Run("should return every task by default", func(t *testing.T) {
+		selected, err := Select(taskList, nil, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, taskList, selected)
+	})
+
+	t.Run("should keep only the enabled tasks without the disabled ones", func(t *testing.T) {
+		selected, err := Select(taskList, []string{"A", "C"}, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, []JobTask{a, c}, selected)
+
+		selected, err = Select(taskList, []string{"A", "C"}, []string{"C"})
+		assert.NoError(t, err)
+		assert.Equal(t, []JobTask{a}, selected)
+
+		selected, err = Select(taskList, nil, []string{"B"})
+		assert.NoError(t, err)
+		assert.Equal(t, []JobTask{a, c}, selected)
+	})
+
+	t.Run("should return an error for unknown names", func(t *testing.T) {
+		_, err := Select(taskList, []string{"D"}, nil)
+		assert.EqualError(t, err, "unknown rule D in the enabled rules")
+
+		_, err = Select(taskList, nil, []string{"D"})
+		assert.EqualError(t, err, "unknown rule D in the disabled rules")
+	})
+
+	t.Run("should return an error for duplicated names", func(t *testing.T) {
+		_, err := Select([]JobTask{a, &registeredTask{name: "A"}}, nil, nil)
+		assert.EqualError(t, err, "duplicated rule name A")
+	})
+}
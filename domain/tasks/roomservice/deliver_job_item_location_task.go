@@ -1,6 +1,8 @@
 package roomservice
 
 import (
+	"context"
+
 	"github.com/Twsouza/job-rule-engine/domain"
 	"github.com/Twsouza/job-rule-engine/domain/tasks"
 )
@@ -9,6 +11,18 @@ type DeliverJobItemLocationTask struct {
 	API tasks.JobAPI
 }
 
+// deliverJobItemLocationConditions are the conditions to deliver a job item to the given locations.
+var deliverJobItemLocationConditions = tasks.Conditions{
+	tasks.DepartmentIs("Room Service"),
+	tasks.HasJobItem(),
+	tasks.LocationsBetween(2, 0),
+}
+
+// Name returns the name the task is registered with.
+func (dj *DeliverJobItemLocationTask) Name() string {
+	return "DeliverJobItemLocation"
+}
+
 // AssertRule checks if the given job request satisfies the conditions to create a job to deliver that job item to the given locations.
 // The conditions to return true are:
 // - The job request must have a non-nil Department and JobItem.
@@ -17,19 +31,16 @@ type DeliverJobItemLocationTask struct {
 // - The job request must have more than one location.
 // If any of these conditions are not met, false is returned.
 func (dj *DeliverJobItemLocationTask) AssertRule(jobRequest domain.JobRequest) bool {
-	if jobRequest.Department == nil || jobRequest.JobItem == nil {
-		return false
-	}
-
-	if jobRequest.Department.Name == "Room Service" && jobRequest.JobItem.DisplayName != "" && len(jobRequest.Locations) > 1 {
-		return true
-	}
+	return deliverJobItemLocationConditions.Assert(jobRequest)
+}
 
-	return false
+// Explain returns the outcome of each condition of AssertRule.
+func (dj *DeliverJobItemLocationTask) Explain(jobRequest domain.JobRequest) []domain.ConditionTrace {
+	return deliverJobItemLocationConditions.Explain(jobRequest)
 }
 
-// Execute will create a job to deliver that job item to the given locations.
-func (dj *DeliverJobItemLocationTask) Execute(jobRequest domain.JobRequest) domain.JobResult {
+// Plan builds the job to deliver that job item to the given locations.
+func (dj *DeliverJobItemLocationTask) Plan(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error) {
 	job := &domain.Job{
 		Action: "deliver",
 		Department: domain.JDepartment{
@@ -47,21 +58,13 @@ func (dj *DeliverJobItemLocationTask) Execute(jobRequest domain.JobRequest) doma
 	}
 
 	if len(job.Locations) == 0 {
-		return domain.JobResult{
-			Request: &jobRequest,
-			Err:     "no locations found for this job",
-		}
+		return nil, tasks.ErrNoLocations
 	}
 
-	var errMsg string
-	result, err := dj.API.CreateJob(job)
-	if err != nil {
-		errMsg = err.Error()
-	}
+	return job, nil
+}
 
-	return domain.JobResult{
-		Request: &jobRequest,
-		Result:  result,
-		Err:     errMsg,
-	}
+// Execute will create a job to deliver that job item to the given locations.
+func (dj *DeliverJobItemLocationTask) Execute(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
+	return tasks.ExecutePlan(ctx, dj.API, dj, jobRequest)
 }
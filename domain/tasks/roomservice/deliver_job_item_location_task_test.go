@@ -1,6 +1,7 @@
 package roomservice
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -135,19 +136,21 @@ func TestDeliverJobItemLocation_Execute(t *testing.T) {
 
 		expectedResult := domain.JobResult{
 			Request: &jobRequest,
-			Result:  "job result",
-			Err:     "",
+			Outcome: domain.OutcomeCreated,
+			Action:  expectedJob.Action,
+			Job:     expectedJob,
+			Created: &domain.JobCreated{ID: 1},
 		}
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.CreateJobFunc = func(job *domain.Job) (interface{}, error) {
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
 			assert.Equal(t, expectedJob, job)
-			return "job result", nil
+			return &domain.JobCreated{ID: 1}, nil
 		}
 
 		dj.API = mockAPI
 
-		result := dj.Execute(jobRequest)
+		result := dj.Execute(context.Background(), jobRequest)
 		assert.Equal(t, expectedResult, result)
 	})
 
@@ -184,14 +187,14 @@ func TestDeliverJobItemLocation_Execute(t *testing.T) {
 		expectedError := errors.New("job creation failed")
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.CreateJobFunc = func(job *domain.Job) (interface{}, error) {
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
 			assert.Equal(t, expectedJob, job)
 			return nil, expectedError
 		}
 
 		dj.API = mockAPI
 
-		result := dj.Execute(jobRequest)
-		assert.Equal(t, expectedError.Error(), result.Err)
+		result := dj.Execute(context.Background(), jobRequest)
+		assert.EqualError(t, result.Error, expectedError.Error())
 	})
 }
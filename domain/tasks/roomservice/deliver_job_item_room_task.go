@@ -1,6 +1,8 @@
 package roomservice
 
 import (
+	"context"
+
 	"github.com/Twsouza/job-rule-engine/domain"
 	"github.com/Twsouza/job-rule-engine/domain/tasks"
 )
@@ -9,6 +11,19 @@ type DeliverJobItemRoomTask struct {
 	API tasks.JobAPI
 }
 
+// deliverJobItemRoomConditions are the conditions to deliver a job item in all rooms on a floor.
+var deliverJobItemRoomConditions = tasks.Conditions{
+	tasks.DepartmentIs("Room Service"),
+	tasks.HasJobItem(),
+	tasks.LocationsBetween(1, 1),
+	tasks.HasLocationType("Floor"),
+}
+
+// Name returns the name the task is registered with.
+func (dj *DeliverJobItemRoomTask) Name() string {
+	return "DeliverJobItemRoom"
+}
+
 // AssertRule checks if the given job request satisfies the conditions to deliver an item in all locations.
 // It returns true if the job request meets the following conditions:
 // - The job request has a non-nil Department field with the name "Room Service".
@@ -16,23 +31,16 @@ type DeliverJobItemRoomTask struct {
 // - The job request has exactly one location of type "Floor".
 // Otherwise, it returns false.
 func (dj *DeliverJobItemRoomTask) AssertRule(jobRequest domain.JobRequest) bool {
-	if jobRequest.Department == nil || jobRequest.Department.Name != "Room Service" || jobRequest.JobItem == nil || jobRequest.JobItem.DisplayName == "" {
-		return false
-	}
-
-	if len(jobRequest.Locations) == 1 && jobRequest.Locations[0].LocationType.DisplayName == "Floor" {
-		return true
-	}
-
-	return false
+	return deliverJobItemRoomConditions.Assert(jobRequest)
 }
 
-// Execute will create a job to deliver the given job item in all locations with a location type of 'Room' on that floor
-func (dj *DeliverJobItemRoomTask) Execute(jobRequest domain.JobRequest) domain.JobResult {
-	jr := domain.JobResult{
-		Request: &jobRequest,
-	}
+// Explain returns the outcome of each condition of AssertRule.
+func (dj *DeliverJobItemRoomTask) Explain(jobRequest domain.JobRequest) []domain.ConditionTrace {
+	return deliverJobItemRoomConditions.Explain(jobRequest)
+}
 
+// Plan builds the job to deliver the given job item in all locations with a location type of 'Room' on that floor
+func (dj *DeliverJobItemRoomTask) Plan(ctx context.Context, jobRequest domain.JobRequest) (*domain.Job, error) {
 	job := &domain.Job{
 		Action: "deliver",
 		Department: domain.JDepartment{
@@ -43,10 +51,11 @@ func (dj *DeliverJobItemRoomTask) Execute(jobRequest domain.JobRequest) domain.J
 		},
 	}
 
-	locations, err := dj.API.GetFloorRooms(jobRequest.Locations[0].ID)
+	// The rooms of every requested floor
+	floors := tasks.LocationsOfType(jobRequest.Locations, "Floor")
+	locations, err := tasks.FloorRooms().Expand(ctx, dj.API, floors)
 	if err != nil {
-		jr.Err = err.Error()
-		return jr
+		return nil, err
 	}
 
 	for _, location := range locations {
@@ -56,15 +65,13 @@ func (dj *DeliverJobItemRoomTask) Execute(jobRequest domain.JobRequest) domain.J
 	}
 
 	if len(job.Locations) == 0 {
-		jr.Err = "no locations found for this job"
-		return jr
+		return nil, tasks.ErrNoLocations
 	}
 
-	result, err := dj.API.CreateJob(job)
-	if err != nil {
-		jr.Err = err.Error()
-	}
-	jr.Result = result
+	return job, nil
+}
 
-	return jr
+// Execute will create a job to deliver the given job item in all locations with a location type of 'Room' on that floor
+func (dj *DeliverJobItemRoomTask) Execute(ctx context.Context, jobRequest domain.JobRequest) domain.JobResult {
+	return tasks.ExecutePlan(ctx, dj.API, dj, jobRequest)
 }
@@ -1,6 +1,7 @@
 package roomservice
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -117,16 +118,25 @@ func TestDeliverJobItemRoom_Execute(t *testing.T) {
 			},
 		}
 
+		expectedJob := &domain.Job{
+			Action:     "deliver",
+			Department: domain.JDepartment{ID: 1},
+			Item:       domain.JItem{Name: "Food"},
+			Locations:  []domain.JLocation{{ID: 1}, {ID: 2}},
+		}
 		expectedJobResult := domain.JobResult{
 			Request: &jobRequest,
-			Result:  "success",
+			Outcome: domain.OutcomeCreated,
+			Action:  "deliver",
+			Job:     expectedJob,
+			Created: &domain.JobCreated{ID: 1},
 		}
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.CreateJobFunc = func(job *domain.Job) (interface{}, error) {
-			return "success", nil
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
+			return &domain.JobCreated{ID: 1}, nil
 		}
-		mockAPI.GetFloorRoomsFunc = func(locationID int) ([]domain.Location, error) {
+		mockAPI.GetFloorRoomsFunc = func(ctx context.Context, locationID int) ([]domain.Location, error) {
 			return []domain.Location{
 				{
 					ID: 1,
@@ -139,7 +149,7 @@ func TestDeliverJobItemRoom_Execute(t *testing.T) {
 
 		dj.API = mockAPI
 
-		actualJobResult := dj.Execute(jobRequest)
+		actualJobResult := dj.Execute(context.Background(), jobRequest)
 		assert.Equal(t, expectedJobResult, actualJobResult)
 	})
 
@@ -164,20 +174,21 @@ func TestDeliverJobItemRoom_Execute(t *testing.T) {
 
 		expectedJobResult := domain.JobResult{
 			Request: &jobRequest,
-			Err:     "API error",
+			Outcome: domain.OutcomeFailed,
+			Error:   &domain.JobError{Code: domain.ErrorUnknown, Message: "API error"},
 		}
 
 		mockAPI := &mock.JobAPIMock{}
-		mockAPI.CreateJobFunc = func(job *domain.Job) (interface{}, error) {
+		mockAPI.CreateJobFunc = func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
 			return nil, nil
 		}
-		mockAPI.GetFloorRoomsFunc = func(locationID int) ([]domain.Location, error) {
+		mockAPI.GetFloorRoomsFunc = func(ctx context.Context, locationID int) ([]domain.Location, error) {
 			return nil, errors.New("API error")
 		}
 
 		dj.API = mockAPI
 
-		actualJobResult := dj.Execute(jobRequest)
+		actualJobResult := dj.Execute(context.Background(), jobRequest)
 		assert.Equal(t, expectedJobResult, actualJobResult)
 	})
 }
@@ -0,0 +1,12 @@
+package roomservice
+
+import "github.com/Twsouza/job-rule-engine/domain/tasks"
+
+func init() {
+	tasks.Register("DeliverJobItemLocation", func(api tasks.JobAPI) tasks.JobTask {
+		return &DeliverJobItemLocationTask{API: api}
+	})
+	tasks.Register("DeliverJobItemRoom", func(api tasks.JobAPI) tasks.JobTask {
+		return &DeliverJobItemRoomTask{API: api}
+	})
+}
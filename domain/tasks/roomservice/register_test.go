@@ -0,0 +1,20 @@
+package roomservice
+
+import (
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain/tasks"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegister(t *testing.T) {
+	t.Run("should register the room service tasks", func(t *testing.T) {
+		assert.Subset(t, tasks.Registered(), []string{"DeliverJobItemLocation", "DeliverJobItemRoom"})
+
+		names := []string{}
+		for _, task := range tasks.NewRegistered(nil) {
+			names = append(names, tasks.NameOf(task))
+		}
+		assert.Equal(t, []string{"DeliverJobItemLocation", "DeliverJobItemRoom"}, names)
+	})
+}
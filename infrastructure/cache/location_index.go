@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+// LocationsLoader loads every location of the property.
+type LocationsLoader interface {
+	GetAllLocations(ctx context.Context) ([]domain.Location, error)
+}
+
+// LocationIndex is an OptiiAPI answering the floor lookups from the tree of every location of the property,
+// instead of going through all the locations in the API for each lookup. The other calls are sent to the API.
+// Until the tree is loaded, and for the floors which are not in it, the floor lookups are sent to the API too.
+type LocationIndex struct {
+	OptiiAPI
+	Loader LocationsLoader
+
+	mu          sync.RWMutex
+	tree        *domain.LocationTree
+	refreshedAt time.Time
+	stop        chan struct{}
+	done        chan struct{}
+}
+
+func NewLocationIndex(api OptiiAPI, loader LocationsLoader) *LocationIndex {
+	return &LocationIndex{
+		OptiiAPI: api,
+		Loader:   loader,
+	}
+}
+
+// Refresh loads the locations and replaces the tree, the previous tree is kept if it fails.
+func (li *LocationIndex) Refresh(ctx context.Context) error {
+	locations, err := li.Loader.GetAllLocations(ctx)
+	if err != nil {
+		return fmt.Errorf("error loading locations: %w", err)
+	}
+
+	tree := domain.NewLocationTree(locations)
+
+	li.mu.Lock()
+	defer li.mu.Unlock()
+
+	li.tree = tree
+	li.refreshedAt = time.Now()
+	return nil
+}
+
+// Start refreshes the tree every interval in the background, until Close is called.
+// Failed refreshes are printed and retried on the next tick.
+func (li *LocationIndex) Start(interval time.Duration) {
+	li.stop = make(chan struct{})
+	li.done = make(chan struct{})
+
+	go func() {
+		defer close(li.done)
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-li.stop:
+				return
+			case <-ticker.C:
+				if err := li.Refresh(context.Background()); err != nil {
+					slog.Error("error refreshing the location index", "error", err)
+				}
+			}
+		}
+	}()
+}
+
+// Close stops the background refresh started with Start, waiting for a running refresh.
+func (li *LocationIndex) Close() {
+	if li.stop == nil {
+		return
+	}
+
+	close(li.stop)
+	<-li.done
+}
+
+// Tree returns the current tree and when it was loaded, the tree is nil until the first refresh succeeds.
+func (li *LocationIndex) Tree() (*domain.LocationTree, time.Time) {
+	li.mu.RLock()
+	defer li.mu.RUnlock()
+
+	return li.tree, li.refreshedAt
+}
+
+// floor returns the tree when the floor is in it.
+func (li *LocationIndex) floor(floorID int) (*domain.LocationTree, bool) {
+	tree, _ := li.Tree()
+	if tree == nil {
+		return nil, false
+	}
+
+	if _, ok := tree.Get(floorID); !ok {
+		return nil, false
+	}
+
+	return tree, true
+}
+
+// GetFloorRooms returns the locations of type "Room" whose parent is the floor.
+func (li *LocationIndex) GetFloorRooms(ctx context.Context, floorID int) ([]domain.Location, error) {
+	tree, ok := li.floor(floorID)
+	if !ok {
+		return li.OptiiAPI.GetFloorRooms(ctx, floorID)
+	}
+
+	return append([]domain.Location(nil), tree.ChildrenOfType(floorID, "Room")...), nil
+}
+
+// GetFloorLocations returns the locations whose parent is the floor.
+func (li *LocationIndex) GetFloorLocations(ctx context.Context, floorID int) ([]domain.Location, error) {
+	tree, ok := li.floor(floorID)
+	if !ok {
+		return li.OptiiAPI.GetFloorLocations(ctx, floorID)
+	}
+
+	return append([]domain.Location(nil), tree.Children(floorID)...), nil
+}
+
+// GetLocationTree returns the current tree, or retrieves it from the API until the first refresh succeeds.
+func (li *LocationIndex) GetLocationTree(ctx context.Context) (*domain.LocationTree, error) {
+	if tree, _ := li.Tree(); tree != nil {
+		return tree, nil
+	}
+
+	return li.OptiiAPI.GetLocationTree(ctx)
+}
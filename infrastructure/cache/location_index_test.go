@@ -0,0 +1,107 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	tasksMock "github.com/Twsouza/job-rule-engine/domain/tasks/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+type locationsLoaderMock struct {
+	GetAllLocationsFunc func(ctx context.Context) ([]domain.Location, error)
+}
+
+func (m *locationsLoaderMock) GetAllLocations(ctx context.Context) ([]domain.Location, error) {
+	return m.GetAllLocationsFunc(ctx)
+}
+
+func TestLocationIndex(t *testing.T) {
+	floor := domain.Location{ID: 1, LocationType: &domain.LocationType{DisplayName: "Floor"}}
+	room := domain.Location{ID: 2, ParentLocation: &domain.ParentLocation{ID: 1}, LocationType: &domain.LocationType{DisplayName: "Room"}}
+	area := domain.Location{ID: 3, ParentLocation: &domain.ParentLocation{ID: 1}, LocationType: &domain.LocationType{DisplayName: "Area"}}
+
+	newIndex := func(apiCalls *int, loadErr error) *LocationIndex {
+		api := optiiAPIMock{
+			JobAPIMock: &tasksMock.JobAPIMock{
+				GetFloorRoomsFunc: func(ctx context.Context, floorID int) ([]domain.Location, error) {
+					*apiCalls++
+					return []domain.Location{{ID: 10}}, nil
+				},
+				GetFloorLocationsFunc: func(ctx context.Context, floorID int) ([]domain.Location, error) {
+					*apiCalls++
+					return []domain.Location{{ID: 10}}, nil
+				},
+			},
+		}
+		loader := &locationsLoaderMock{
+			GetAllLocationsFunc: func(ctx context.Context) ([]domain.Location, error) {
+				return []domain.Location{floor, room, area}, loadErr
+			},
+		}
+
+		return NewLocationIndex(api, loader)
+	}
+
+	t.Run("should answer the floor lookups from the tree", func(t *testing.T) {
+		apiCalls := 0
+		index := newIndex(&apiCalls, nil)
+		assert.NoError(t, index.Refresh(context.Background()))
+
+		rooms, err := index.GetFloorRooms(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Location{room}, rooms)
+
+		locations, err := index.GetFloorLocations(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Location{room, area}, locations)
+
+		assert.Equal(t, 0, apiCalls)
+	})
+
+	t.Run("should send the floor lookups to the API until the tree is loaded", func(t *testing.T) {
+		apiCalls := 0
+		index := newIndex(&apiCalls, errors.New("unavailable"))
+		assert.EqualError(t, index.Refresh(context.Background()), "error loading locations: unavailable")
+
+		rooms, err := index.GetFloorRooms(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Location{{ID: 10}}, rooms)
+		assert.Equal(t, 1, apiCalls)
+	})
+
+	t.Run("should send the lookups of unknown floors to the API", func(t *testing.T) {
+		apiCalls := 0
+		index := newIndex(&apiCalls, nil)
+		assert.NoError(t, index.Refresh(context.Background()))
+
+		locations, err := index.GetFloorLocations(context.Background(), 99)
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Location{{ID: 10}}, locations)
+		assert.Equal(t, 1, apiCalls)
+	})
+
+	t.Run("should return the loaded tree", func(t *testing.T) {
+		apiCalls := 0
+		index := newIndex(&apiCalls, nil)
+		assert.NoError(t, index.Refresh(context.Background()))
+
+		tree, err := index.GetLocationTree(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Location{room}, tree.ChildrenOfType(1, "Room"))
+	})
+
+	t.Run("should stop refreshing when closed", func(t *testing.T) {
+		apiCalls := 0
+		index := newIndex(&apiCalls, nil)
+		index.Start(time.Millisecond)
+
+		for tree, _ := index.Tree(); tree == nil; tree, _ = index.Tree() {
+			time.Sleep(time.Millisecond)
+		}
+		index.Close()
+	})
+}
@@ -0,0 +1,329 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/services"
+	"github.com/Twsouza/job-rule-engine/domain/tasks"
+	"golang.org/x/sync/singleflight"
+)
+
+// Kinds of entities in the cache.
+const (
+	KindDepartments    = "departments"
+	KindJobItems       = "jobItems"
+	KindLocations      = "locations"
+	KindFloorRooms     = "floorRooms"
+	KindFloorLocations = "floorLocations"
+	KindLocationTree   = "locationTree"
+)
+
+// OptiiAPI is the Optii API used to load the job requests and by the rules.
+type OptiiAPI interface {
+	services.OptiiApiInterface
+	tasks.JobAPI
+	tasks.JobLifecycleAPI
+}
+
+// Config sets how long each kind of entity is cached, a kind with a zero TTL is not cached.
+type Config struct {
+	DepartmentTTL time.Duration
+	JobItemTTL    time.Duration
+	LocationTTL   time.Duration
+	// FloorTTL is how long the rooms and locations of a floor, and the tree of every location, are cached.
+	FloorTTL time.Duration
+	// MaxEntries is the maximum number of entries of each kind, zero means no limit.
+	// The least recently used entry is evicted first.
+	MaxEntries int
+	// FetchTimeout bounds the call to the API of a miss, which isn't cancelled with its callers.
+	// Zero means defaultFetchTimeout.
+	FetchTimeout time.Duration
+}
+
+// defaultFetchTimeout is the FetchTimeout of a Config without one.
+const defaultFetchTimeout = 30 * time.Second
+
+// OptiiCache is an OptiiAPI caching the reference data of the API it decorates.
+// Concurrent misses of the same entity are collapsed in a single call to the API, which keeps the values
+// of the context of the first caller but isn't cancelled with it, and each caller stops waiting for it
+// when its own context is done. Jobs are always created in the API, and errors are not cached.
+type OptiiCache struct {
+	API OptiiAPI
+
+	departments    *store[int64, domain.Department]
+	jobItems       *store[int64, domain.JobItem]
+	locations      *store[int64, domain.Location]
+	floorRooms     *store[int, []domain.Location]
+	floorLocations *store[int, []domain.Location]
+	locationTree   *store[int, *domain.LocationTree]
+	group          singleflight.Group
+	fetchTimeout   time.Duration
+}
+
+func NewOptiiCache(api OptiiAPI, config Config) *OptiiCache {
+	return &OptiiCache{
+		API:            api,
+		departments:    newStore[int64, domain.Department](KindDepartments, config.DepartmentTTL, config.MaxEntries),
+		jobItems:       newStore[int64, domain.JobItem](KindJobItems, config.JobItemTTL, config.MaxEntries),
+		locations:      newStore[int64, domain.Location](KindLocations, config.LocationTTL, config.MaxEntries),
+		floorRooms:     newStore[int, []domain.Location](KindFloorRooms, config.FloorTTL, config.MaxEntries),
+		floorLocations: newStore[int, []domain.Location](KindFloorLocations, config.FloorTTL, config.MaxEntries),
+		locationTree:   newStore[int, *domain.LocationTree](KindLocationTree, config.FloorTTL, 1),
+		fetchTimeout:   config.FetchTimeout,
+	}
+}
+
+// load returns the cached value of the key, or fetches and caches it.
+func load[K comparable, V any](ctx context.Context, c *OptiiCache, s *store[K, V], key K, fetch func(ctx context.Context) (V, error)) (V, error) {
+	if !s.enabled() {
+		return fetch(ctx)
+	}
+
+	if value, ok := s.get(key); ok {
+		return value, nil
+	}
+
+	result := c.group.DoChan(fmt.Sprintf("%s/%v", s.kind, key), func() (interface{}, error) {
+		// The value may have been cached by a call that finished after the miss
+		if value, ok := s.cached(key); ok {
+			return value, nil
+		}
+
+		// The call is shared with the other callers, so it doesn't end with the first one
+		timeout := c.fetchTimeout
+		if timeout <= 0 {
+			timeout = defaultFetchTimeout
+		}
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), timeout)
+		defer cancel()
+
+		generation := s.currentGeneration()
+		value, err := fetch(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		s.set(key, value, generation)
+		return value, nil
+	})
+
+	var zero V
+	select {
+	case r := <-result:
+		if r.Err != nil {
+			return zero, r.Err
+		}
+		return r.Val.(V), nil
+	case <-ctx.Done():
+		return zero, ctx.Err()
+	}
+}
+
+// GetDepartmentByID returns the cached department, or retrieves it from the API.
+func (c *OptiiCache) GetDepartmentByID(ctx context.Context, id int64) (*domain.Department, error) {
+	department, err := load(ctx, c, c.departments, id, func(ctx context.Context) (domain.Department, error) {
+		department, err := c.API.GetDepartmentByID(ctx, id)
+		if err != nil {
+			return domain.Department{}, err
+		}
+		return *department, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &department, nil
+}
+
+// GetJobItemByID returns the cached job item, or retrieves it from the API.
+func (c *OptiiCache) GetJobItemByID(ctx context.Context, id int64) (*domain.JobItem, error) {
+	jobItem, err := load(ctx, c, c.jobItems, id, func(ctx context.Context) (domain.JobItem, error) {
+		jobItem, err := c.API.GetJobItemByID(ctx, id)
+		if err != nil {
+			return domain.JobItem{}, err
+		}
+		return *jobItem, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &jobItem, nil
+}
+
+// GetLocationsByIds returns the cached locations, retrieving the missing ones from the API.
+// The locations are returned in the order of the IDs. When some of them can't be retrieved,
+// the others are returned along with a services.LocationErrors keyed by the ID of the failed ones.
+func (c *OptiiCache) GetLocationsByIds(ctx context.Context, ids []int64) ([]domain.Location, error) {
+	if !c.locations.enabled() {
+		return c.API.GetLocationsByIds(ctx, ids)
+	}
+
+	locations := make([]domain.Location, len(ids))
+	errs := make([]error, len(ids))
+
+	wg := sync.WaitGroup{}
+	for i, id := range ids {
+		wg.Add(1)
+		go func(i int, id int64) {
+			defer wg.Done()
+			locations[i], errs[i] = load(ctx, c, c.locations, id, func(ctx context.Context) (domain.Location, error) {
+				locations, err := c.API.GetLocationsByIds(ctx, []int64{id})
+				var failed services.LocationErrors
+				if errors.As(err, &failed) && failed[id] != nil {
+					return domain.Location{}, failed[id]
+				}
+				if err != nil {
+					return domain.Location{}, err
+				}
+				if len(locations) == 0 {
+					return domain.Location{}, fmt.Errorf("location %d %w", id, services.ErrNotFound)
+				}
+				return locations[0], nil
+			})
+		}(i, id)
+	}
+	wg.Wait()
+
+	loaded := make([]domain.Location, 0, len(ids))
+	failed := services.LocationErrors{}
+	for i, id := range ids {
+		if errs[i] != nil {
+			failed[id] = errs[i]
+			continue
+		}
+		loaded = append(loaded, locations[i])
+	}
+
+	if len(failed) > 0 {
+		return loaded, failed
+	}
+
+	return loaded, nil
+}
+
+// CreateJob creates the job in the API, it's never cached.
+func (c *OptiiCache) CreateJob(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
+	return c.API.CreateJob(ctx, job)
+}
+
+// GetJob retrieves the job from the API, the jobs are never cached.
+func (c *OptiiCache) GetJob(ctx context.Context, id int) (*domain.JobCreated, error) {
+	return c.API.GetJob(ctx, id)
+}
+
+// ListJobs retrieves the page of jobs from the API.
+func (c *OptiiCache) ListJobs(ctx context.Context, filter domain.JobFilter, first int32, next int32) (*domain.JobPage, error) {
+	return c.API.ListJobs(ctx, filter, first, next)
+}
+
+// UpdateJob changes the job in the API.
+func (c *OptiiCache) UpdateJob(ctx context.Context, id int, update domain.JobUpdate) (*domain.JobCreated, error) {
+	return c.API.UpdateJob(ctx, id, update)
+}
+
+// CancelJob cancels the job in the API.
+func (c *OptiiCache) CancelJob(ctx context.Context, id int) (*domain.JobCreated, error) {
+	return c.API.CancelJob(ctx, id)
+}
+
+// CompleteJob completes the job in the API.
+func (c *OptiiCache) CompleteJob(ctx context.Context, id int) (*domain.JobCreated, error) {
+	return c.API.CompleteJob(ctx, id)
+}
+
+// GetFloorRooms returns the cached rooms of the floor, or retrieves them from the API.
+func (c *OptiiCache) GetFloorRooms(ctx context.Context, floorID int) ([]domain.Location, error) {
+	rooms, err := load(ctx, c, c.floorRooms, floorID, func(ctx context.Context) ([]domain.Location, error) {
+		return c.API.GetFloorRooms(ctx, floorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A copy, so the cached rooms are not changed by the caller
+	return append([]domain.Location(nil), rooms...), nil
+}
+
+// GetFloorLocations returns the cached locations of the floor, or retrieves them from the API.
+func (c *OptiiCache) GetFloorLocations(ctx context.Context, floorID int) ([]domain.Location, error) {
+	locations, err := load(ctx, c, c.floorLocations, floorID, func(ctx context.Context) ([]domain.Location, error) {
+		return c.API.GetFloorLocations(ctx, floorID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// A copy, so the cached locations are not changed by the caller
+	return append([]domain.Location(nil), locations...), nil
+}
+
+// GetLocationTree returns the cached tree of every location, or retrieves it from the API.
+// The tree is shared by the callers, it's never modified.
+func (c *OptiiCache) GetLocationTree(ctx context.Context) (*domain.LocationTree, error) {
+	return load(ctx, c, c.locationTree, 0, func(ctx context.Context) (*domain.LocationTree, error) {
+		return c.API.GetLocationTree(ctx)
+	})
+}
+
+// Stats returns the stats of each kind of entity.
+func (c *OptiiCache) Stats() []domain.CacheStats {
+	return []domain.CacheStats{
+		c.departments.stats(),
+		c.jobItems.stats(),
+		c.locations.stats(),
+		c.floorRooms.stats(),
+		c.floorLocations.stats(),
+		c.locationTree.stats(),
+	}
+}
+
+// Invalidate removes the entries with the given IDs of a kind, or all of them when no ID is given.
+// An empty kind removes every entry of the cache. The IDs of the floor kinds are floor IDs.
+func (c *OptiiCache) Invalidate(kind string, ids ...int64) error {
+	switch kind {
+	case "":
+		if len(ids) > 0 {
+			return fmt.Errorf("a kind is required to invalidate IDs")
+		}
+		c.departments.clear()
+		c.jobItems.clear()
+		c.locations.clear()
+		c.floorRooms.clear()
+		c.floorLocations.clear()
+		c.locationTree.clear()
+	case KindDepartments:
+		invalidate(c.departments, ids, func(id int64) int64 { return id })
+	case KindJobItems:
+		invalidate(c.jobItems, ids, func(id int64) int64 { return id })
+	case KindLocations:
+		invalidate(c.locations, ids, func(id int64) int64 { return id })
+	case KindFloorRooms:
+		invalidate(c.floorRooms, ids, func(id int64) int { return int(id) })
+	case KindFloorLocations:
+		invalidate(c.floorLocations, ids, func(id int64) int { return int(id) })
+	case KindLocationTree:
+		// There is a single tree, the IDs are ignored
+		c.locationTree.clear()
+	default:
+		return fmt.Errorf("%w %q", services.ErrUnknownCacheKind, kind)
+	}
+
+	return nil
+}
+
+func invalidate[K comparable, V any](s *store[K, V], ids []int64, key func(id int64) K) {
+	if len(ids) == 0 {
+		s.clear()
+		return
+	}
+
+	for _, id := range ids {
+		s.delete(key(id))
+	}
+}
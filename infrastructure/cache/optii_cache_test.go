@@ -0,0 +1,307 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/services"
+	servicesMock "github.com/Twsouza/job-rule-engine/domain/services/mock"
+	tasksMock "github.com/Twsouza/job-rule-engine/domain/tasks/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+type optiiAPIMock struct {
+	*servicesMock.OptiiApiMock
+	*tasksMock.JobAPIMock
+	*tasksMock.JobLifecycleAPIMock
+}
+
+func newOptiiAPIMock(calls *int32) optiiAPIMock {
+	return optiiAPIMock{
+		OptiiApiMock: &servicesMock.OptiiApiMock{
+			GetDepartmentByIDFunc: func(ctx context.Context, id int64) (*domain.Department, error) {
+				atomic.AddInt32(calls, 1)
+				if id == 99 {
+					return nil, errors.New("not found")
+				}
+				return &domain.Department{ID: int(id), Name: "Engineering"}, nil
+			},
+			GetJobItemByIDFunc: func(ctx context.Context, id int64) (*domain.JobItem, error) {
+				atomic.AddInt32(calls, 1)
+				return &domain.JobItem{ID: int(id), DisplayName: "Item"}, nil
+			},
+			GetLocationsByIdsFunc: func(ctx context.Context, ids []int64) ([]domain.Location, error) {
+				atomic.AddInt32(calls, 1)
+				if ids[0] == 99 {
+					return nil, errors.New("not found")
+				}
+				return []domain.Location{{ID: int(ids[0])}}, nil
+			},
+		},
+		JobAPIMock: &tasksMock.JobAPIMock{
+			CreateJobFunc: func(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
+				atomic.AddInt32(calls, 1)
+				return &domain.JobCreated{}, nil
+			},
+			GetFloorRoomsFunc: func(ctx context.Context, floorID int) ([]domain.Location, error) {
+				atomic.AddInt32(calls, 1)
+				return []domain.Location{{ID: 10}, {ID: 11}}, nil
+			},
+			GetLocationTreeFunc: func(ctx context.Context) (*domain.LocationTree, error) {
+				atomic.AddInt32(calls, 1)
+				return domain.NewLocationTree([]domain.Location{{ID: 1}}), nil
+			},
+		},
+		JobLifecycleAPIMock: &tasksMock.JobLifecycleAPIMock{
+			GetJobFunc: func(ctx context.Context, id int) (*domain.JobCreated, error) {
+				atomic.AddInt32(calls, 1)
+				return &domain.JobCreated{ID: id, Status: domain.JobPending}, nil
+			},
+		},
+	}
+}
+
+func TestOptiiCache(t *testing.T) {
+	config := Config{
+		DepartmentTTL: time.Minute,
+		JobItemTTL:    time.Minute,
+		LocationTTL:   time.Minute,
+		FloorTTL:      time.Minute,
+	}
+
+	t.Run("should call the API once for each entity", func(t *testing.T) {
+		calls := int32(0)
+		c := NewOptiiCache(newOptiiAPIMock(&calls), config)
+
+		for i := 0; i < 3; i++ {
+			department, err := c.GetDepartmentByID(context.Background(), 1)
+			assert.NoError(t, err)
+			assert.Equal(t, &domain.Department{ID: 1, Name: "Engineering"}, department)
+
+			jobItem, err := c.GetJobItemByID(context.Background(), 2)
+			assert.NoError(t, err)
+			assert.Equal(t, &domain.JobItem{ID: 2, DisplayName: "Item"}, jobItem)
+
+			rooms, err := c.GetFloorRooms(context.Background(), 5)
+			assert.NoError(t, err)
+			assert.Len(t, rooms, 2)
+		}
+
+		assert.Equal(t, int32(3), calls)
+		stats := c.Stats()
+		assert.Equal(t, domain.CacheStats{Kind: KindDepartments, TTL: "1m0s", Entries: 1, Hits: 2, Misses: 1}, stats[0])
+	})
+
+	t.Run("should cache the location tree with the floors TTL", func(t *testing.T) {
+		calls := int32(0)
+		c := NewOptiiCache(newOptiiAPIMock(&calls), config)
+
+		tree, err := c.GetLocationTree(context.Background())
+		assert.NoError(t, err)
+		cached, err := c.GetLocationTree(context.Background())
+		assert.NoError(t, err)
+
+		assert.Same(t, tree, cached)
+		assert.Equal(t, int32(1), calls)
+
+		assert.NoError(t, c.Invalidate(KindLocationTree))
+		c.GetLocationTree(context.Background())
+		assert.Equal(t, int32(2), calls)
+	})
+
+	t.Run("should return the locations in the order of the IDs, loading only the missing ones", func(t *testing.T) {
+		calls := int32(0)
+		c := NewOptiiCache(newOptiiAPIMock(&calls), config)
+
+		_, err := c.GetLocationsByIds(context.Background(), []int64{1})
+		assert.NoError(t, err)
+
+		locations, err := c.GetLocationsByIds(context.Background(), []int64{3, 1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Location{{ID: 3}, {ID: 1}, {ID: 2}}, locations)
+		assert.Equal(t, int32(3), calls)
+	})
+
+	t.Run("should never cache the jobs", func(t *testing.T) {
+		calls := int32(0)
+		c := NewOptiiCache(newOptiiAPIMock(&calls), config)
+
+		for i := 0; i < 2; i++ {
+			job, err := c.GetJob(context.Background(), 7)
+			assert.NoError(t, err)
+			assert.Equal(t, 7, job.ID)
+		}
+		assert.Equal(t, int32(2), calls)
+	})
+
+	t.Run("should not cache errors", func(t *testing.T) {
+		calls := int32(0)
+		c := NewOptiiCache(newOptiiAPIMock(&calls), config)
+
+		locations, err := c.GetLocationsByIds(context.Background(), []int64{1, 99})
+		assert.EqualError(t, err, "99: not found")
+		assert.Equal(t, []domain.Location{{ID: 1}}, locations)
+
+		_, err = c.GetDepartmentByID(context.Background(), 99)
+		assert.EqualError(t, err, "not found")
+		_, err = c.GetDepartmentByID(context.Background(), 99)
+		assert.EqualError(t, err, "not found")
+
+		assert.Equal(t, int32(4), calls)
+	})
+
+	t.Run("should collapse concurrent misses in a single call", func(t *testing.T) {
+		calls := int32(0)
+		release := make(chan struct{})
+		api := newOptiiAPIMock(&calls)
+		api.GetDepartmentByIDFunc = func(ctx context.Context, id int64) (*domain.Department, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+			return &domain.Department{ID: int(id)}, nil
+		}
+		c := NewOptiiCache(api, config)
+
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				department, err := c.GetDepartmentByID(context.Background(), 1)
+				assert.NoError(t, err)
+				assert.Equal(t, 1, department.ID)
+			}()
+		}
+
+		// Wait for the first call, the other ones are either waiting for it or will hit the cache
+		for atomic.LoadInt32(&calls) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		close(release)
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls)
+	})
+
+	t.Run("should not cancel the shared call when its first caller gives up", func(t *testing.T) {
+		calls := int32(0)
+		started, release := make(chan struct{}), make(chan struct{})
+		api := newOptiiAPIMock(&calls)
+		api.GetDepartmentByIDFunc = func(ctx context.Context, id int64) (*domain.Department, error) {
+			atomic.AddInt32(&calls, 1)
+			close(started)
+			select {
+			case <-release:
+				return &domain.Department{ID: int(id)}, nil
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		c := NewOptiiCache(api, config)
+
+		ctx, cancel := context.WithCancel(context.Background())
+		first := make(chan error)
+		go func() {
+			_, err := c.GetDepartmentByID(ctx, 1)
+			first <- err
+		}()
+		<-started
+
+		second := make(chan error)
+		go func() {
+			department, err := c.GetDepartmentByID(context.Background(), 1)
+			if err == nil {
+				assert.Equal(t, 1, department.ID)
+			}
+			second <- err
+		}()
+
+		cancel()
+		assert.ErrorIs(t, <-first, context.Canceled)
+		close(release)
+		assert.NoError(t, <-second)
+		assert.Equal(t, int32(1), calls)
+	})
+
+	t.Run("should bound the shared call with the fetch timeout", func(t *testing.T) {
+		calls := int32(0)
+		api := newOptiiAPIMock(&calls)
+		api.GetDepartmentByIDFunc = func(ctx context.Context, id int64) (*domain.Department, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+		c := NewOptiiCache(api, Config{DepartmentTTL: time.Minute, FetchTimeout: 10 * time.Millisecond})
+
+		_, err := c.GetDepartmentByID(context.Background(), 1)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("should expire the entries after the TTL", func(t *testing.T) {
+		calls := int32(0)
+		c := NewOptiiCache(newOptiiAPIMock(&calls), config)
+		now := time.Now()
+		c.jobItems.now = func() time.Time { return now }
+
+		c.GetJobItemByID(context.Background(), 1)
+		now = now.Add(time.Minute)
+		c.GetJobItemByID(context.Background(), 1)
+
+		assert.Equal(t, int32(2), calls)
+	})
+
+	t.Run("should evict the least recently used entry", func(t *testing.T) {
+		calls := int32(0)
+		c := NewOptiiCache(newOptiiAPIMock(&calls), Config{JobItemTTL: time.Minute, MaxEntries: 2})
+
+		c.GetJobItemByID(context.Background(), 1)
+		c.GetJobItemByID(context.Background(), 2)
+		c.GetJobItemByID(context.Background(), 1)
+		c.GetJobItemByID(context.Background(), 3)
+		assert.Equal(t, int32(3), calls)
+
+		// 2 was evicted, 1 is still cached
+		c.GetJobItemByID(context.Background(), 1)
+		assert.Equal(t, int32(3), calls)
+		c.GetJobItemByID(context.Background(), 2)
+		assert.Equal(t, int32(4), calls)
+
+		stats := c.Stats()
+		assert.Equal(t, 2, stats[1].Entries)
+		assert.Equal(t, uint64(2), stats[1].Evictions)
+	})
+
+	t.Run("should not cache the kinds without TTL nor the jobs", func(t *testing.T) {
+		calls := int32(0)
+		c := NewOptiiCache(newOptiiAPIMock(&calls), Config{})
+
+		c.GetDepartmentByID(context.Background(), 1)
+		c.GetDepartmentByID(context.Background(), 1)
+		c.CreateJob(context.Background(), &domain.Job{})
+		c.CreateJob(context.Background(), &domain.Job{})
+
+		assert.Equal(t, int32(4), calls)
+		assert.Equal(t, 0, c.Stats()[0].Entries)
+	})
+
+	t.Run("should invalidate the entries", func(t *testing.T) {
+		calls := int32(0)
+		c := NewOptiiCache(newOptiiAPIMock(&calls), config)
+
+		c.GetLocationsByIds(context.Background(), []int64{1, 2})
+		c.GetDepartmentByID(context.Background(), 1)
+
+		assert.NoError(t, c.Invalidate(KindLocations, 1))
+		assert.Equal(t, 1, c.Stats()[2].Entries)
+
+		assert.NoError(t, c.Invalidate(""))
+		for _, stats := range c.Stats() {
+			assert.Equal(t, 0, stats.Entries)
+		}
+
+		assert.ErrorIs(t, c.Invalidate("rooms"), services.ErrUnknownCacheKind)
+	})
+}
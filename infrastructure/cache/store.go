@@ -0,0 +1,169 @@
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+type entry[K comparable, V any] struct {
+	key     K
+	value   V
+	expires time.Time
+}
+
+// store keeps the values of one kind for ttl, evicting the least recently used entry
+// when it has max entries. A store with ttl zero keeps nothing.
+type store[K comparable, V any] struct {
+	kind string
+	ttl  time.Duration
+	max  int
+	now  func() time.Time
+
+	mu sync.Mutex
+	// order has the most recently used entry at the front
+	order     *list.List
+	items     map[K]*list.Element
+	hits      uint64
+	misses    uint64
+	evictions uint64
+	// generation changes when entries are invalidated, so a value fetched before is not stored
+	generation uint64
+}
+
+func newStore[K comparable, V any](kind string, ttl time.Duration, max int) *store[K, V] {
+	return &store[K, V]{
+		kind:  kind,
+		ttl:   ttl,
+		max:   max,
+		now:   time.Now,
+		order: list.New(),
+		items: map[K]*list.Element{},
+	}
+}
+
+func (s *store[K, V]) enabled() bool {
+	return s.ttl > 0
+}
+
+func (s *store[K, V]) get(key K) (V, bool) {
+	if !s.enabled() {
+		var zero V
+		return zero, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.lookup(key)
+	if ok {
+		s.hits++
+	} else {
+		s.misses++
+	}
+
+	return value, ok
+}
+
+// cached returns the value of the key like get, without counting a hit or a miss.
+func (s *store[K, V]) cached(key K) (V, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lookup(key)
+}
+
+// lookup returns the value of the key unless it expired, the caller must hold the lock.
+func (s *store[K, V]) lookup(key K) (V, bool) {
+	var zero V
+	el, ok := s.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	e := el.Value.(*entry[K, V])
+	if !s.now().Before(e.expires) {
+		s.order.Remove(el)
+		delete(s.items, key)
+		return zero, false
+	}
+
+	s.order.MoveToFront(el)
+	return e.value, true
+}
+
+func (s *store[K, V]) currentGeneration() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.generation
+}
+
+// set stores the value unless the entries were invalidated since the given generation.
+func (s *store[K, V]) set(key K, value V, generation uint64) {
+	if !s.enabled() {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if generation != s.generation {
+		return
+	}
+
+	expires := s.now().Add(s.ttl)
+	if el, ok := s.items[key]; ok {
+		e := el.Value.(*entry[K, V])
+		e.value = value
+		e.expires = expires
+		s.order.MoveToFront(el)
+		return
+	}
+
+	s.items[key] = s.order.PushFront(&entry[K, V]{key: key, value: value, expires: expires})
+
+	for s.max > 0 && s.order.Len() > s.max {
+		oldest := s.order.Back()
+		s.order.Remove(oldest)
+		delete(s.items, oldest.Value.(*entry[K, V]).key)
+		s.evictions++
+	}
+}
+
+func (s *store[K, V]) delete(key K) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generation++
+	if el, ok := s.items[key]; ok {
+		s.order.Remove(el)
+		delete(s.items, key)
+	}
+}
+
+func (s *store[K, V]) clear() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.generation++
+	s.order.Init()
+	s.items = map[K]*list.Element{}
+}
+
+func (s *store[K, V]) stats() domain.CacheStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return domain.CacheStats{
+		Kind:       s.kind,
+		TTL:        s.ttl.String(),
+		Entries:    s.order.Len(),
+		MaxEntries: s.max,
+		Hits:       s.hits,
+		Misses:     s.misses,
+		Evictions:  s.evictions,
+	}
+}
@@ -0,0 +1,353 @@
+// Package cassette records the calls sent to Optii in a file, and replays them without reaching Optii.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// Mode is what the Recorder does with the calls.
+type Mode string
+
+const (
+	// ModeRecord sends the calls and stores them in the cassette.
+	ModeRecord Mode = "record"
+	// ModeReplay answers the calls with the responses stored in the cassette, without sending them.
+	ModeReplay Mode = "replay"
+	// ModePassthrough sends the calls without storing them.
+	ModePassthrough Mode = "passthrough"
+)
+
+// ErrNoInteraction is returned in replay mode for a call which isn't in the cassette.
+var ErrNoInteraction = errors.New("no interaction in the cassette")
+
+// redacted replaces the secrets in the cassette.
+const redacted = "REDACTED"
+
+// secretFields are the query params, form fields and JSON fields scrubbed from the cassette.
+// The request headers, e.g. the bearer token, are never stored.
+var secretFields = []string{"client_secret", "access_token", "refresh_token", "password"}
+
+// ParseMode parses the name of a mode.
+func ParseMode(name string) (Mode, error) {
+	switch mode := Mode(name); mode {
+	case ModeRecord, ModeReplay, ModePassthrough:
+		return mode, nil
+	}
+
+	return "", fmt.Errorf("unknown cassette mode %q", name)
+}
+
+// Interaction is a call stored in the cassette.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+// Request is the part of a call matched by the replay, with the secrets scrubbed.
+type Request struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+	Query  string `json:"query,omitempty"`
+	Body   string `json:"body,omitempty"`
+}
+
+// Response is the response of a call, with the secrets of its body scrubbed.
+type Response struct {
+	Status      int    `json:"status"`
+	ContentType string `json:"contentType,omitempty"`
+	Body        string `json:"body,omitempty"`
+}
+
+// Recorder is an http.RoundTripper recording the calls in the cassette file, replaying them or just sending them.
+// The calls are matched by method, path, query and body, the host is ignored so the cassette can be replayed
+// against any base URL. When a call was recorded several times, its responses are replayed in order,
+// and the last one is repeated once they are all replayed.
+type Recorder struct {
+	// Path is the cassette file. In record mode it's written after each call.
+	Path string
+	Mode Mode
+	// Next sends the calls in record and passthrough modes, http.DefaultTransport when nil.
+	Next http.RoundTripper
+
+	mu           sync.Mutex
+	interactions []Interaction
+	replayed     []bool
+}
+
+// New creates a recorder of the cassette file, which is loaded in replay mode.
+func New(path string, mode Mode) (*Recorder, error) {
+	if _, err := ParseMode(string(mode)); err != nil {
+		return nil, err
+	}
+
+	r := &Recorder{Path: path, Mode: mode}
+	if mode != ModeReplay {
+		return r, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &r.interactions); err != nil {
+		return nil, fmt.Errorf("invalid cassette %s: %w", path, err)
+	}
+	r.replayed = make([]bool, len(r.interactions))
+
+	return r, nil
+}
+
+// Interactions returns the calls in the cassette.
+func (r *Recorder) Interactions() []Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]Interaction(nil), r.interactions...)
+}
+
+func (r *Recorder) RoundTrip(request *http.Request) (*http.Response, error) {
+	switch r.Mode {
+	case ModeReplay:
+		return r.replay(request)
+	case ModeRecord:
+		return r.record(request)
+	default:
+		return r.next().RoundTrip(request)
+	}
+}
+
+func (r *Recorder) next() http.RoundTripper {
+	if r.Next == nil {
+		return http.DefaultTransport
+	}
+
+	return r.Next
+}
+
+func (r *Recorder) replay(request *http.Request) (*http.Response, error) {
+	recorded, _, err := newRequest(request)
+	if request.Body != nil {
+		// The request isn't sent, its body is closed as a transport would
+		request.Body.Close()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, interaction := range r.interactions {
+		if interaction.Request != recorded {
+			continue
+		}
+		if !r.replayed[i] {
+			r.replayed[i] = true
+			return interaction.Response.toHTTP(request), nil
+		}
+		last = i
+	}
+	if last >= 0 {
+		return r.interactions[last].Response.toHTTP(request), nil
+	}
+
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, request.Method, request.URL.RequestURI())
+}
+
+func (r *Recorder) record(request *http.Request) (*http.Response, error) {
+	recorded, request, err := newRequest(request)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := r.next().RoundTrip(request)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(response.Body)
+	response.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	// The caller reads the actual body, only the stored one is scrubbed
+	response.Body = io.NopCloser(bytes.NewReader(body))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.interactions = append(r.interactions, Interaction{
+		Request: recorded,
+		Response: Response{
+			Status:      response.StatusCode,
+			ContentType: response.Header.Get("Content-Type"),
+			Body:        scrubJSON(string(body)),
+		},
+	})
+	if err := r.save(); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// save writes the cassette, the caller holds mu.
+func (r *Recorder) save() error {
+	data, err := json.MarshalIndent(r.interactions, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(r.Path, append(data, '\n'), 0o644)
+}
+
+// newRequest returns the scrubbed request to store or match, and the request to send.
+// The request of the caller isn't modified, see http.RoundTripper: its body is read from GetBody when
+// it's set, otherwise the body is read once and sent with a clone of the request.
+func newRequest(request *http.Request) (Request, *http.Request, error) {
+	recorded := Request{
+		Method: request.Method,
+		Path:   request.URL.Path,
+		Query:  scrubValues(request.URL.Query()),
+	}
+
+	body, send, err := readBody(request)
+	if err != nil || body == nil {
+		return recorded, send, err
+	}
+
+	if strings.HasPrefix(request.Header.Get("Content-Type"), "application/x-www-form-urlencoded") {
+		form, err := url.ParseQuery(string(body))
+		if err != nil {
+			return Request{}, nil, err
+		}
+		recorded.Body = scrubValues(form)
+	} else {
+		recorded.Body = scrubJSON(string(body))
+	}
+
+	return recorded, send, nil
+}
+
+// readBody returns the body of the request, nil when it has none, and the request to send.
+func readBody(request *http.Request) ([]byte, *http.Request, error) {
+	if request.Body == nil || request.Body == http.NoBody {
+		return nil, request, nil
+	}
+
+	if request.GetBody != nil {
+		reader, err := request.GetBody()
+		if err != nil {
+			return nil, nil, err
+		}
+		defer reader.Close()
+		body, err := io.ReadAll(reader)
+		return body, request, err
+	}
+
+	body, err := io.ReadAll(request.Body)
+	request.Body.Close()
+	if err != nil {
+		return nil, nil, err
+	}
+	send := request.Clone(request.Context())
+	send.Body = io.NopCloser(bytes.NewReader(body))
+	send.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	return body, send, nil
+}
+
+// scrubValues returns the encoded values, sorted by key, with the secrets redacted.
+func scrubValues(values url.Values) string {
+	for _, field := range secretFields {
+		if values.Has(field) {
+			values.Set(field, redacted)
+		}
+	}
+
+	return values.Encode()
+}
+
+// scrubJSON returns the JSON with the secrets redacted, at any depth. Other bodies are returned as they are.
+func scrubJSON(body string) string {
+	decoder := json.NewDecoder(strings.NewReader(body))
+	// The numbers are kept as they are written
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return body
+	}
+
+	if !scrubValue(value) {
+		return body
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return body
+	}
+
+	return string(data)
+}
+
+// scrubValue redacts the secret fields of the decoded JSON objects in value, returning true if any was found.
+func scrubValue(value any) bool {
+	scrubbed := false
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if isSecret(key) {
+				v[key] = redacted
+				scrubbed = true
+				continue
+			}
+			scrubbed = scrubValue(field) || scrubbed
+		}
+	case []any:
+		for _, item := range v {
+			scrubbed = scrubValue(item) || scrubbed
+		}
+	}
+
+	return scrubbed
+}
+
+func isSecret(field string) bool {
+	for _, secret := range secretFields {
+		if field == secret {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (r Response) toHTTP(request *http.Request) *http.Response {
+	header := http.Header{}
+	if r.ContentType != "" {
+		header.Set("Content-Type", r.ContentType)
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", r.Status, http.StatusText(r.Status)),
+		StatusCode:    r.Status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(strings.NewReader(r.Body)),
+		ContentLength: int64(len(r.Body)),
+		Request:       request,
+	}
+}
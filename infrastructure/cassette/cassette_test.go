@@ -0,0 +1,202 @@
+package cassette
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/infrastructure/fakeoptii"
+	"github.com/Twsouza/job-rule-engine/infrastructure/pkg"
+	"github.com/Twsouza/job-rule-engine/infrastructure/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSdk returns an SDK whose calls, authentication included, go through the recorder.
+func newSdk(t *testing.T, baseURL string, recorder *Recorder) *sdk.OptiiSdk {
+	optii, err := sdk.NewOptiiSdk(baseURL, "v1", 1, nil)
+	assert.NoError(t, err)
+	tokens := pkg.NewTokenSource("client", "s3cr3t", baseURL+fakeoptii.AuthPath)
+	tokens.Client = &http.Client{Transport: recorder}
+	optii.Tokens = tokens
+	optii.Transport = recorder
+
+	return optii
+}
+
+func TestRecorder(t *testing.T) {
+	ctx := context.Background()
+	path := filepath.Join(t.TempDir(), "optii.json")
+	job := &domain.Job{Action: "clean", Department: domain.JDepartment{ID: 13}, Locations: []domain.JLocation{{ID: 101}}}
+
+	t.Run("should record the calls without their secrets", func(t *testing.T) {
+		fake := fakeoptii.New(nil)
+		fake.ClientID = "client"
+		fake.ClientSecret = "s3cr3t"
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		recorder, err := New(path, ModeRecord)
+		assert.NoError(t, err)
+		optii := newSdk(t, server.URL, recorder)
+
+		department, err := optii.GetDepartmentByID(ctx, 13)
+		assert.NoError(t, err)
+		assert.Equal(t, "Housekeeping", department.Name)
+		rooms, err := optii.GetFloorRooms(ctx, 2)
+		assert.NoError(t, err)
+		assert.Len(t, rooms, 4)
+		_, err = optii.CreateJob(ctx, job)
+		assert.NoError(t, err)
+
+		interactions := recorder.Interactions()
+		assert.Len(t, interactions, 4)
+		assert.Equal(t, Request{
+			Method: http.MethodPost,
+			Path:   fakeoptii.AuthPath,
+			Body:   "client_id=client&client_secret=REDACTED&grant_type=client_credentials&scope=openapi",
+		}, interactions[0].Request)
+		assert.Contains(t, interactions[0].Response.Body, `"access_token":"REDACTED"`)
+		assert.Equal(t, Request{Method: http.MethodGet, Path: "/api/v1/locations", Query: "first=0&locationType=Room&next=100"}, interactions[2].Request)
+
+		data, err := os.ReadFile(path)
+		assert.NoError(t, err)
+		assert.NotContains(t, string(data), "s3cr3t")
+		assert.NotContains(t, string(data), "Authorization")
+	})
+
+	t.Run("should replay the calls without reaching Optii", func(t *testing.T) {
+		recorder, err := New(path, ModeReplay)
+		assert.NoError(t, err)
+		// Nothing listens on this URL, the host is not matched
+		optii := newSdk(t, "http://127.0.0.1:1", recorder)
+
+		department, err := optii.GetDepartmentByID(ctx, 13)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.Department{ID: 13, Name: "Housekeeping"}, department)
+		rooms, err := optii.GetFloorRooms(ctx, 2)
+		assert.NoError(t, err)
+		assert.Len(t, rooms, 4)
+		result, err := optii.CreateJob(ctx, job)
+		assert.NoError(t, err)
+		assert.Equal(t, 1, result.ID)
+	})
+
+	t.Run("should fail the calls which were not recorded", func(t *testing.T) {
+		recorder, err := New(path, ModeReplay)
+		assert.NoError(t, err)
+		optii := &sdk.OptiiSdk{BaseUrl: "http://127.0.0.1:1", ApiVersion: "v1", Client: &http.Client{Transport: recorder}}
+
+		_, err = optii.GetDepartmentByID(ctx, 14)
+		assert.ErrorIs(t, err, ErrNoInteraction)
+
+		// The body is matched
+		_, err = optii.CreateJob(ctx, &domain.Job{Action: "repair", Department: domain.JDepartment{ID: 13}})
+		assert.ErrorIs(t, err, ErrNoInteraction)
+	})
+}
+
+func TestRecorder_Replay(t *testing.T) {
+	t.Run("should replay the responses of a call in order, then repeat the last one", func(t *testing.T) {
+		fake := fakeoptii.New(nil)
+		fake.AddFault(fakeoptii.Fault{Path: "/api/v1/departments/13", Status: http.StatusServiceUnavailable, Times: 1})
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		path := filepath.Join(t.TempDir(), "optii.json")
+		recorder, err := New(path, ModeRecord)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{503, 200}, statuses(t, &http.Client{Transport: recorder}, server.URL+"/api/v1/departments/13", 2))
+
+		recorder, err = New(path, ModeReplay)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{503, 200, 200}, statuses(t, &http.Client{Transport: recorder}, "http://optii.test/api/v1/departments/13", 3))
+	})
+}
+
+func TestRecorder_Passthrough(t *testing.T) {
+	t.Run("should send the calls without recording them", func(t *testing.T) {
+		server := httptest.NewServer(fakeoptii.New(nil))
+		defer server.Close()
+
+		path := filepath.Join(t.TempDir(), "optii.json")
+		recorder, err := New(path, ModePassthrough)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{200}, statuses(t, &http.Client{Transport: recorder}, server.URL+"/api/v1/departments/13", 1))
+
+		assert.Empty(t, recorder.Interactions())
+		assert.NoFileExists(t, path)
+	})
+}
+
+func TestRecorder_Request(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	t.Run("should send the body without modifying the request of the caller", func(t *testing.T) {
+		recorder, err := New(filepath.Join(t.TempDir(), "optii.json"), ModeRecord)
+		assert.NoError(t, err)
+
+		// A reader http.NewRequest doesn't know, so the request has no GetBody
+		body := io.NopCloser(io.MultiReader(strings.NewReader(`{"action":"clean"}`)))
+		request, err := http.NewRequest(http.MethodPost, server.URL+"/api/v1/jobs", body)
+		assert.NoError(t, err)
+		assert.Nil(t, request.GetBody)
+
+		response, err := recorder.RoundTrip(request)
+		assert.NoError(t, err)
+		sent, _ := io.ReadAll(response.Body)
+		assert.Equal(t, `{"action":"clean"}`, string(sent))
+		assert.Equal(t, body, request.Body)
+		assert.Equal(t, `{"action":"clean"}`, recorder.Interactions()[0].Request.Body)
+	})
+}
+
+func TestScrubJSON(t *testing.T) {
+	t.Run("should redact the secrets at any depth", func(t *testing.T) {
+		scrubbed := scrubJSON(`{"data":{"access_token":"t0k3n","expires_in":3600},"items":[{"password":"p4ss"}],"id":12345678901234567890}`)
+		assert.JSONEq(t, `{"data":{"access_token":"REDACTED","expires_in":3600},"items":[{"password":"REDACTED"}],"id":12345678901234567890}`, scrubbed)
+		assert.Contains(t, scrubbed, "12345678901234567890")
+	})
+
+	t.Run("should return the body without secrets as it is", func(t *testing.T) {
+		assert.Equal(t, `{"b": 1, "a": [2]}`, scrubJSON(`{"b": 1, "a": [2]}`))
+		assert.Equal(t, "not json", scrubJSON("not json"))
+	})
+}
+
+func TestNew(t *testing.T) {
+	t.Run("should reject an unknown mode", func(t *testing.T) {
+		_, err := New("optii.json", Mode("rewind"))
+		assert.EqualError(t, err, `unknown cassette mode "rewind"`)
+	})
+
+	t.Run("should fail to replay a missing cassette", func(t *testing.T) {
+		_, err := New(filepath.Join(t.TempDir(), "missing.json"), ModeReplay)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+// statuses sends n GET requests to the URL, returning their statuses.
+func statuses(t *testing.T, client *http.Client, url string, n int) []int {
+	statuses := []int{}
+	for i := 0; i < n; i++ {
+		res, err := client.Get(url)
+		if !assert.NoError(t, err) {
+			return statuses
+		}
+		io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		statuses = append(statuses, res.StatusCode)
+	}
+
+	return statuses
+}
@@ -0,0 +1,95 @@
+package fakeoptii
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+//go:embed fixture.json
+var defaultFixture []byte
+
+// Fixture is the data served by the fake Optii.
+type Fixture struct {
+	Departments []domain.Department `json:"departments"`
+	JobItems    []domain.JobItem    `json:"jobItems"`
+	// Locations refer to their parent with ParentLocation, and to their type with LocationType.
+	Locations []domain.Location `json:"locations"`
+	// Faults are injected in the requests from the start, see Server.AddFault.
+	Faults []Fault `json:"faults,omitempty"`
+}
+
+// Fault makes the matching requests slow or fail.
+type Fault struct {
+	// Method matches the method of the request, any method when empty.
+	Method string `json:"method,omitempty"`
+	// Path matches the path of the request with path.Match, e.g. "/api/v1/locations/*".
+	Path string `json:"path"`
+	// Status is the status of the error response, the request is only delayed when zero.
+	Status int `json:"status,omitempty"`
+	// Latency delays the response.
+	Latency Duration `json:"latency,omitempty"`
+	// Times is the number of requests affected, every request when zero.
+	Times int `json:"times,omitempty"`
+}
+
+// Duration is a time.Duration written in JSON as a string, e.g. "150ms".
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+
+	return nil
+}
+
+// DefaultFixture returns a small property with two floors of rooms, and the departments
+// and job items matched by the rules of the engine.
+func DefaultFixture() *Fixture {
+	fixture, err := parseFixture(defaultFixture)
+	if err != nil {
+		panic(err)
+	}
+
+	return fixture
+}
+
+// LoadFixture reads the fixture in the JSON file.
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	fixture, err := parseFixture(data)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+
+	return fixture, nil
+}
+
+func parseFixture(data []byte) (*Fixture, error) {
+	fixture := &Fixture{}
+	if err := json.Unmarshal(data, fixture); err != nil {
+		return nil, err
+	}
+
+	return fixture, nil
+}
@@ -0,0 +1,536 @@
+// Package fakeoptii is an in-memory Optii API serving the departments, job items and locations
+// of a fixture, for the tests and to run the engine offline.
+package fakeoptii
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/gin-gonic/gin"
+)
+
+// AuthPath is the path of the OAuth token endpoint, the OPTII_AUTH_URL of the engine.
+const AuthPath = "/oauth/authorize"
+
+// defaultTokenTTL is the lifetime of the access tokens when TokenTTL is not set.
+const defaultTokenTTL = time.Hour
+
+// defaultPageSize is the number of locations of a page when the request doesn't set next.
+const defaultPageSize = 100
+
+// Server is the fake Optii. It's an http.Handler, to be served by an httptest.Server or an http.Server.
+type Server struct {
+	// ClientID and ClientSecret are the credentials accepted by the token endpoint.
+	// When ClientID is empty any credentials are accepted, and the API doesn't require a token.
+	ClientID     string
+	ClientSecret string
+	// TokenTTL is the lifetime of the access tokens, one hour when zero.
+	TokenTTL time.Duration
+	// Latency delays every response.
+	Latency time.Duration
+
+	mu          sync.Mutex
+	departments map[int]domain.Department
+	jobItems    map[int]domain.JobItem
+	// locations are sorted by ID, which is the order of the pages
+	locations []domain.Location
+	faults    []*Fault
+	tokens    map[string]time.Time
+	jobs      []jobRecord
+	now       func() time.Time
+
+	router *gin.Engine
+}
+
+// jobRecord is a job created, with the request which created it.
+type jobRecord struct {
+	request domain.Job
+	job     domain.JobCreated
+}
+
+// New creates a fake Optii serving the fixture, or the DefaultFixture when nil.
+func New(fixture *Fixture) *Server {
+	if fixture == nil {
+		fixture = DefaultFixture()
+	}
+
+	s := &Server{
+		departments: map[int]domain.Department{},
+		jobItems:    map[int]domain.JobItem{},
+		tokens:      map[string]time.Time{},
+		now:         time.Now,
+	}
+	for _, department := range fixture.Departments {
+		s.departments[department.ID] = department
+	}
+	for _, jobItem := range fixture.JobItems {
+		s.jobItems[jobItem.ID] = jobItem
+	}
+	s.locations = append(s.locations, fixture.Locations...)
+	sort.Slice(s.locations, func(i, j int) bool { return s.locations[i].ID < s.locations[j].ID })
+	for _, fault := range fixture.Faults {
+		s.AddFault(fault)
+	}
+
+	r := gin.New()
+	r.Use(gin.Recovery(), s.injectFaults)
+	r.POST(AuthPath, s.token)
+
+	api := r.Group("/api/:version", s.authenticate)
+	api.GET("/departments/:id", s.getDepartment)
+	api.GET("/jobitems/:id", s.getJobItem)
+	api.GET("/locations", s.getLocations)
+	api.GET("/locations/:id", s.getLocation)
+	api.POST("/jobs", s.createJob)
+	api.GET("/jobs", s.listJobs)
+	api.GET("/jobs/:id", s.getJob)
+	api.PATCH("/jobs/:id", s.updateJob)
+	api.POST("/jobs/:id/cancel", s.closeJob(domain.JobCancelled))
+	api.POST("/jobs/:id/complete", s.closeJob(domain.JobCompleted))
+	s.router = r
+
+	return s
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// AddFault injects the fault in the matching requests, the first matching fault is applied.
+func (s *Server) AddFault(fault Fault) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = append(s.faults, &fault)
+}
+
+// ClearFaults removes the faults.
+func (s *Server) ClearFaults() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.faults = nil
+}
+
+// Jobs returns the jobs created, in the order they were received.
+func (s *Server) Jobs() []domain.Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	jobs := make([]domain.Job, 0, len(s.jobs))
+	for _, record := range s.jobs {
+		jobs = append(jobs, record.request)
+	}
+
+	return jobs
+}
+
+// injectFaults delays the request by the Latency of the server and of the first matching fault,
+// and fails it with the status of the fault.
+func (s *Server) injectFaults(c *gin.Context) {
+	fault := s.matchFault(c.Request)
+
+	latency := s.Latency
+	if fault != nil {
+		latency += time.Duration(fault.Latency)
+	}
+	if !sleep(c.Request.Context(), latency) {
+		c.Abort()
+		return
+	}
+
+	if fault != nil && fault.Status != 0 {
+		abortWithProblem(c, fault.Status, fmt.Sprintf("fault injected in %s", c.Request.URL.Path))
+	}
+}
+
+// matchFault returns a copy of the first fault matching the request, counting it in its Times.
+func (s *Server) matchFault(r *http.Request) *Fault {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, fault := range s.faults {
+		if fault.Method != "" && !strings.EqualFold(fault.Method, r.Method) {
+			continue
+		}
+		if matched, _ := path.Match(fault.Path, r.URL.Path); !matched {
+			continue
+		}
+
+		matched := *fault
+		if fault.Times > 0 {
+			fault.Times--
+			if fault.Times == 0 {
+				s.faults = append(s.faults[:i:i], s.faults[i+1:]...)
+			}
+		}
+		return &matched
+	}
+
+	return nil
+}
+
+// token implements the client credentials flow.
+func (s *Server) token(c *gin.Context) {
+	if c.PostForm("grant_type") != "client_credentials" {
+		abortWithProblem(c, http.StatusBadRequest, "unsupported grant_type")
+		return
+	}
+	if s.ClientID != "" && (c.PostForm("client_id") != s.ClientID || c.PostForm("client_secret") != s.ClientSecret) {
+		abortWithProblem(c, http.StatusUnauthorized, "invalid client credentials")
+		return
+	}
+
+	ttl := s.TokenTTL
+	if ttl <= 0 {
+		ttl = defaultTokenTTL
+	}
+	token := newToken()
+	issuedAt := s.now()
+
+	s.mu.Lock()
+	s.tokens[token] = issuedAt.Add(ttl)
+	s.mu.Unlock()
+
+	c.JSON(http.StatusOK, gin.H{
+		"access_token": token,
+		"expires_in":   int(ttl.Seconds()),
+		"token_type":   "Bearer",
+		"issued_at":    strconv.FormatInt(issuedAt.UnixMilli(), 10),
+	})
+}
+
+// authenticate rejects the requests without a valid token, when the server has credentials.
+func (s *Server) authenticate(c *gin.Context) {
+	if s.ClientID == "" {
+		return
+	}
+
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	s.mu.Lock()
+	expiresAt, issued := s.tokens[token]
+	s.mu.Unlock()
+
+	if !ok || !issued || !s.now().Before(expiresAt) {
+		abortWithProblem(c, http.StatusUnauthorized, "invalid or expired access token")
+	}
+}
+
+func (s *Server) getDepartment(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	department, found := s.departments[id]
+	s.mu.Unlock()
+
+	if !found {
+		abortWithProblem(c, http.StatusNotFound, fmt.Sprintf("department %d not found", id))
+		return
+	}
+	c.JSON(http.StatusOK, department)
+}
+
+func (s *Server) getJobItem(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	jobItem, found := s.jobItems[id]
+	s.mu.Unlock()
+
+	if !found {
+		abortWithProblem(c, http.StatusNotFound, fmt.Sprintf("job item %d not found", id))
+		return
+	}
+	c.JSON(http.StatusOK, jobItem)
+}
+
+func (s *Server) getLocation(c *gin.Context) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	location, found := s.location(id)
+	if !found {
+		abortWithProblem(c, http.StatusNotFound, fmt.Sprintf("location %d not found", id))
+		return
+	}
+	c.JSON(http.StatusOK, location)
+}
+
+// getLocations returns a page of the locations of the locationType, or of every type when empty.
+// The first query parameter is the cursor, the index of the first location of the page,
+// and next is the number of locations of the page.
+func (s *Server) getLocations(c *gin.Context) {
+	first, size, ok := pageParams(c)
+	if !ok {
+		return
+	}
+	locationType := c.Query("locationType")
+
+	s.mu.Lock()
+	locations := []domain.Location{}
+	for _, location := range s.locations {
+		if locationType == "" || (location.LocationType != nil && location.LocationType.DisplayName == locationType) {
+			locations = append(locations, location)
+		}
+	}
+	s.mu.Unlock()
+
+	start := min(first, len(locations))
+	end := min(start+size, len(locations))
+	c.JSON(http.StatusOK, gin.H{
+		"pageInfo": gin.H{
+			"endCursor":   end,
+			"hasNextPage": end < len(locations),
+			"totalCount":  len(locations),
+		},
+		"items": locations[start:end],
+	})
+}
+
+// createJob records the job, its department and locations must exist.
+func (s *Server) createJob(c *gin.Context) {
+	job := domain.Job{}
+	if err := c.ShouldBindJSON(&job); err != nil {
+		abortWithProblem(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.mu.Lock()
+	department, found := s.departments[job.Department.ID]
+	s.mu.Unlock()
+	if !found {
+		abortWithProblem(c, http.StatusBadRequest, fmt.Sprintf("department %d not found", job.Department.ID))
+		return
+	}
+
+	created := domain.JobCreated{Type: "Internal", Priority: "medium", Action: job.Action, Status: domain.JobPending}
+	created.Item.Displayname = job.Item.Name
+	created.Departments = append(created.Departments, struct {
+		ID   int    `json:"id"`
+		Name string `json:"name"`
+	}{ID: department.ID, Name: department.Name})
+	for _, jobLocation := range job.Locations {
+		location, found := s.location(jobLocation.ID)
+		if !found {
+			abortWithProblem(c, http.StatusBadRequest, fmt.Sprintf("location %d not found", jobLocation.ID))
+			return
+		}
+		created.Locations = append(created.Locations, struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+		}{ID: location.ID, Name: location.Name})
+	}
+
+	s.mu.Lock()
+	created.ID = len(s.jobs) + 1
+	s.jobs = append(s.jobs, jobRecord{request: job, job: created})
+	s.mu.Unlock()
+
+	c.JSON(http.StatusOK, created)
+}
+
+func (s *Server) getJob(c *gin.Context) {
+	s.withJob(c, func(record *jobRecord) {
+		c.JSON(http.StatusOK, record.job)
+	})
+}
+
+// listJobs returns a page of the jobs, paginated like the locations and filtered by
+// the status, departmentId, locationId and action query parameters.
+func (s *Server) listJobs(c *gin.Context) {
+	first, size, ok := pageParams(c)
+	if !ok {
+		return
+	}
+	departmentID, _ := strconv.Atoi(c.Query("departmentId"))
+	locationID, _ := strconv.Atoi(c.Query("locationId"))
+	status := domain.JobStatus(c.Query("status"))
+	action := c.Query("action")
+
+	s.mu.Lock()
+	jobs := []domain.JobCreated{}
+	for _, record := range s.jobs {
+		if status != "" && record.job.Status != status {
+			continue
+		}
+		if action != "" && record.job.Action != action {
+			continue
+		}
+		if departmentID != 0 && record.request.Department.ID != departmentID {
+			continue
+		}
+		if locationID != 0 && !hasLocation(record.request, locationID) {
+			continue
+		}
+		jobs = append(jobs, record.job)
+	}
+	s.mu.Unlock()
+
+	start := min(first, len(jobs))
+	end := min(start+size, len(jobs))
+	c.JSON(http.StatusOK, domain.JobPage{
+		PageInfo: &domain.PageInfo{EndCursor: end, HasNextPage: end < len(jobs), TotalCount: len(jobs)},
+		Jobs:     jobs[start:end],
+	})
+}
+
+// updateJob changes the priority, assignee or notes of a pending job.
+func (s *Server) updateJob(c *gin.Context) {
+	update := domain.JobUpdate{}
+	if err := c.ShouldBindJSON(&update); err != nil {
+		abortWithProblem(c, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	s.withJob(c, func(record *jobRecord) {
+		if record.job.Status != domain.JobPending {
+			abortWithProblem(c, http.StatusConflict, fmt.Sprintf("job %d is %s", record.job.ID, record.job.Status))
+			return
+		}
+
+		if update.Priority != nil {
+			record.job.Priority = *update.Priority
+		}
+		if update.Assignee != nil {
+			record.job.Assignee = *update.Assignee
+		}
+		if update.Notes != nil {
+			record.job.Notes = *update.Notes
+		}
+		c.JSON(http.StatusOK, record.job)
+	})
+}
+
+// closeJob returns the handler setting the status of a pending job.
+func (s *Server) closeJob(status domain.JobStatus) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		s.withJob(c, func(record *jobRecord) {
+			if record.job.Status != domain.JobPending {
+				abortWithProblem(c, http.StatusConflict, fmt.Sprintf("job %d is %s", record.job.ID, record.job.Status))
+				return
+			}
+
+			record.job.Status = status
+			c.JSON(http.StatusOK, record.job)
+		})
+	}
+}
+
+// withJob calls fn with the job of the id path parameter while holding mu, or responds with a 404.
+func (s *Server) withJob(c *gin.Context, fn func(record *jobRecord)) {
+	id, ok := idParam(c)
+	if !ok {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id < 1 || id > len(s.jobs) {
+		abortWithProblem(c, http.StatusNotFound, fmt.Sprintf("job %d not found", id))
+		return
+	}
+	fn(&s.jobs[id-1])
+}
+
+func hasLocation(job domain.Job, locationID int) bool {
+	for _, location := range job.Locations {
+		if location.ID == locationID {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Server) location(id int) (domain.Location, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	i := sort.Search(len(s.locations), func(i int) bool { return s.locations[i].ID >= id })
+	if i == len(s.locations) || s.locations[i].ID != id {
+		return domain.Location{}, false
+	}
+
+	return s.locations[i], true
+}
+
+// pageParams returns the first and next query parameters, aborting with a 400 when they are invalid.
+func pageParams(c *gin.Context) (first int, size int, ok bool) {
+	first, err := strconv.Atoi(c.DefaultQuery("first", "0"))
+	if err != nil || first < 0 {
+		abortWithProblem(c, http.StatusBadRequest, "invalid first")
+		return 0, 0, false
+	}
+	size, err = strconv.Atoi(c.DefaultQuery("next", strconv.Itoa(defaultPageSize)))
+	if err != nil || size < 1 {
+		abortWithProblem(c, http.StatusBadRequest, "invalid next")
+		return 0, 0, false
+	}
+
+	return first, size, true
+}
+
+// idParam returns the id path parameter, aborting with a 400 when it's not a number.
+func idParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		abortWithProblem(c, http.StatusBadRequest, fmt.Sprintf("invalid id %q", c.Param("id")))
+		return 0, false
+	}
+
+	return id, true
+}
+
+// abortWithProblem responds with an error in the format of Optii.
+func abortWithProblem(c *gin.Context, status int, detail string) {
+	c.AbortWithStatusJSON(status, gin.H{
+		"type":   "about:blank",
+		"title":  http.StatusText(status),
+		"status": status,
+		"detail": detail,
+	})
+}
+
+// sleep waits for d, it returns false when the context is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return true
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+func newToken() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,187 @@
+package fakeoptii
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/services"
+	"github.com/Twsouza/job-rule-engine/infrastructure/pkg"
+	"github.com/Twsouza/job-rule-engine/infrastructure/sdk"
+	"github.com/stretchr/testify/assert"
+)
+
+// newSdk returns an SDK authenticated against the fake, as created by the factory of the engine.
+func newSdk(t *testing.T, server *httptest.Server) *sdk.OptiiSdk {
+	optii, err := sdk.NewOptiiSdk(server.URL, "v1", 1, nil)
+	assert.NoError(t, err)
+	optii.Tokens = pkg.NewTokenSource("client", "secret", server.URL+AuthPath)
+
+	return optii
+}
+
+func TestServer(t *testing.T) {
+	fake := New(nil)
+	fake.ClientID = "client"
+	fake.ClientSecret = "secret"
+	server := httptest.NewServer(fake)
+	defer server.Close()
+
+	optii := newSdk(t, server)
+	ctx := context.Background()
+
+	t.Run("should serve the departments, job items and locations of the fixture", func(t *testing.T) {
+		department, err := optii.GetDepartmentByID(ctx, 13)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.Department{ID: 13, Name: "Housekeeping"}, department)
+
+		jobItem, err := optii.GetJobItemByID(ctx, 184)
+		assert.NoError(t, err)
+		assert.Equal(t, &domain.JobItem{ID: 184, DisplayName: "Sheets"}, jobItem)
+
+		locations, err := optii.GetLocationsByIds(ctx, []int64{2, 101})
+		assert.NoError(t, err)
+		assert.Equal(t, "Floor", locations[0].LocationType.DisplayName)
+		assert.Equal(t, 2, locations[1].ParentLocation.ID)
+	})
+
+	t.Run("should return a 404 for an unknown entity", func(t *testing.T) {
+		_, err := optii.GetDepartmentByID(ctx, 99)
+		assert.ErrorIs(t, err, services.ErrNotFound)
+		assert.EqualError(t, err, "Not Found: department 99 not found")
+	})
+
+	t.Run("should filter the locations by type", func(t *testing.T) {
+		rooms, err := optii.GetFloorRooms(ctx, 3)
+		assert.NoError(t, err)
+
+		ids := []int{}
+		for _, room := range rooms {
+			ids = append(ids, room.ID)
+		}
+		assert.Equal(t, []int{201, 202, 203, 204}, ids)
+	})
+
+	t.Run("should record the jobs created", func(t *testing.T) {
+		job := &domain.Job{
+			Action:     "clean",
+			Item:       domain.JItem{Name: "Sheets"},
+			Department: domain.JDepartment{ID: 13},
+			Locations:  []domain.JLocation{{ID: 101}},
+		}
+		created, err := optii.CreateJob(ctx, job)
+		assert.NoError(t, err)
+
+		assert.Equal(t, 1, created.ID)
+		assert.Equal(t, "101", created.Locations[0].Name)
+		assert.Equal(t, []domain.Job{*job}, fake.Jobs())
+
+		_, err = optii.CreateJob(ctx, &domain.Job{Department: domain.JDepartment{ID: 13}, Locations: []domain.JLocation{{ID: 999}}})
+		assert.EqualError(t, err, "Bad Request: location 999 not found")
+		assert.Len(t, fake.Jobs(), 1)
+	})
+
+	t.Run("should reject the requests without a valid token", func(t *testing.T) {
+		res, err := http.Get(server.URL + "/api/v1/departments/13")
+		assert.NoError(t, err)
+		res.Body.Close()
+		assert.Equal(t, http.StatusUnauthorized, res.StatusCode)
+
+		_, err = pkg.NewTokenSource("client", "wrong", server.URL+AuthPath).Token(ctx)
+		assert.EqualError(t, err, "authentication failed with status 401")
+	})
+
+	t.Run("should reject the expired tokens", func(t *testing.T) {
+		fake := New(nil)
+		fake.ClientID = "client"
+		fake.ClientSecret = "secret"
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		optii := newSdk(t, server)
+		_, err := optii.GetDepartmentByID(ctx, 13)
+		assert.NoError(t, err)
+
+		// The SDK authenticates again when its token is rejected
+		fake.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+		_, err = optii.GetDepartmentByID(ctx, 13)
+		assert.NoError(t, err)
+	})
+}
+
+func TestServer_Pagination(t *testing.T) {
+	fixture := &Fixture{}
+	for i := 250; i >= 1; i-- {
+		fixture.Locations = append(fixture.Locations, domain.Location{ID: i, Name: fmt.Sprint(i)})
+	}
+	server := httptest.NewServer(New(fixture))
+	defer server.Close()
+
+	optii := &sdk.OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient}
+
+	t.Run("should return the pages in the order of the IDs", func(t *testing.T) {
+		page, err := optii.GetLocations(context.Background(), 200, 100, "")
+		assert.NoError(t, err)
+		assert.Len(t, page.Locations, 50)
+		assert.Equal(t, 201, page.Locations[0].ID)
+		assert.Equal(t, &sdk.PageInfo{EndCursor: 250, HasNextPage: false, TotalCount: 250}, page.PageInfo)
+	})
+
+	t.Run("should go through every page", func(t *testing.T) {
+		locations, err := optii.GetAllLocations(context.Background())
+		assert.NoError(t, err)
+		assert.Len(t, locations, 250)
+		assert.Equal(t, 250, locations[249].ID)
+	})
+}
+
+func TestServer_Faults(t *testing.T) {
+	t.Run("should fail the matching requests the given number of times", func(t *testing.T) {
+		fake := New(nil)
+		fake.AddFault(Fault{Method: http.MethodGet, Path: "/api/v1/locations/*", Status: http.StatusServiceUnavailable, Times: 1})
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		optii := &sdk.OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient}
+
+		_, err := optii.GetDepartmentByID(context.Background(), 13)
+		assert.NoError(t, err)
+
+		_, err = optii.GetLocationByID(context.Background(), 2)
+		assert.ErrorIs(t, err, services.ErrUpstream)
+		assert.True(t, sdk.IsRetryable(err))
+
+		_, err = optii.GetLocationByID(context.Background(), 2)
+		assert.NoError(t, err)
+	})
+
+	t.Run("should delay the responses", func(t *testing.T) {
+		fake := New(nil)
+		fake.AddFault(Fault{Path: "/api/v1/departments/*", Latency: Duration(200 * time.Millisecond)})
+		server := httptest.NewServer(fake)
+		defer server.Close()
+
+		optii := &sdk.OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient, Timeout: 50 * time.Millisecond}
+
+		_, err := optii.GetDepartmentByID(context.Background(), 13)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("should load the faults of the fixture", func(t *testing.T) {
+		fixture, err := LoadFixture(filepath.Join("testdata", "faults.json"))
+		assert.NoError(t, err)
+		assert.Equal(t, []Fault{{Path: "/api/v1/jobs", Status: 500, Latency: Duration(10 * time.Millisecond)}}, fixture.Faults)
+
+		server := httptest.NewServer(New(fixture))
+		defer server.Close()
+
+		optii := &sdk.OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient}
+		_, err = optii.CreateJob(context.Background(), &domain.Job{Department: domain.JDepartment{ID: 13}})
+		assert.ErrorIs(t, err, services.ErrUpstream)
+	})
+}
@@ -0,0 +1,151 @@
+package metrics
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "job_rule_engine"
+
+// Metrics are the Prometheus metrics of the server. It records the HTTP requests received,
+// the loading and the execution of the job requests (services.MetricsInterface)
+// and the calls sent to Optii (sdk.Observer).
+type Metrics struct {
+	Registry *prometheus.Registry
+
+	httpRequests  *prometheus.CounterVec
+	loadJob       *prometheus.HistogramVec
+	ruleMatches   *prometheus.CounterVec
+	ruleErrors    *prometheus.CounterVec
+	ruleExecute   *prometheus.HistogramVec
+	optiiRequests *prometheus.CounterVec
+	optiiDuration *prometheus.HistogramVec
+	optiiRetries  *prometheus.CounterVec
+	optiiPages    *prometheus.HistogramVec
+}
+
+// New creates the metrics in a new registry, along with the Go runtime and process metrics.
+func New() *Metrics {
+	m := &Metrics{
+		Registry: prometheus.NewRegistry(),
+		httpRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "HTTP requests received, by method, route and status.",
+		}, []string{"method", "route", "status"}),
+		loadJob: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "load_job_duration_seconds",
+			Help:      "Duration of loading a job request from Optii.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"result"}),
+		ruleMatches: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rule_matches_total",
+			Help:      "Rules selected to be executed for a job request.",
+		}, []string{"rule"}),
+		ruleErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "rule_errors_total",
+			Help:      "Rule executions which failed.",
+		}, []string{"rule"}),
+		ruleExecute: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "rule_execute_duration_seconds",
+			Help:      "Duration of the execution of a rule.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"rule"}),
+		optiiRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "optii_requests_total",
+			Help:      "HTTP calls sent to Optii, retries included, by endpoint and status (0 without response).",
+		}, []string{"endpoint", "status"}),
+		optiiDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "optii_request_duration_seconds",
+			Help:      "Duration of the HTTP calls sent to Optii, by endpoint.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"endpoint"}),
+		optiiRetries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "optii_retries_total",
+			Help:      "HTTP calls to Optii retried, by endpoint.",
+		}, []string{"endpoint"}),
+		optiiPages: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "optii_pages",
+			Help:      "Number of pages retrieved from Optii by a paginated method, e.g. GetFloorRooms.",
+			Buckets:   []float64{1, 2, 5, 10, 20, 50, 100},
+		}, []string{"method"}),
+	}
+
+	m.Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.httpRequests,
+		m.loadJob,
+		m.ruleMatches,
+		m.ruleErrors,
+		m.ruleExecute,
+		m.optiiRequests,
+		m.optiiDuration,
+		m.optiiRetries,
+		m.optiiPages,
+	)
+
+	return m
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.Registry, promhttp.HandlerOpts{})
+}
+
+// ObserveHTTPRequest records an HTTP request received by the server.
+func (m *Metrics) ObserveHTTPRequest(method, route string, status int) {
+	m.httpRequests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+}
+
+// ObserveLoadJob records the duration of loading a job request, and whether it failed.
+func (m *Metrics) ObserveLoadJob(duration time.Duration, failed bool) {
+	result := "success"
+	if failed {
+		result = "error"
+	}
+
+	m.loadJob.WithLabelValues(result).Observe(duration.Seconds())
+}
+
+// ObserveRuleMatch records a rule selected to be executed.
+func (m *Metrics) ObserveRuleMatch(rule string) {
+	m.ruleMatches.WithLabelValues(rule).Inc()
+}
+
+// ObserveExecute records the duration of the execution of a rule, and whether it failed.
+func (m *Metrics) ObserveExecute(rule string, duration time.Duration, failed bool) {
+	m.ruleExecute.WithLabelValues(rule).Observe(duration.Seconds())
+	if failed {
+		m.ruleErrors.WithLabelValues(rule).Inc()
+	}
+}
+
+// ObserveRequest records an HTTP call sent to Optii.
+func (m *Metrics) ObserveRequest(endpoint string, status int, duration time.Duration) {
+	m.optiiRequests.WithLabelValues(endpoint, strconv.Itoa(status)).Inc()
+	m.optiiDuration.WithLabelValues(endpoint).Observe(duration.Seconds())
+}
+
+// ObserveRetry records a retried HTTP call to Optii.
+func (m *Metrics) ObserveRetry(endpoint string) {
+	m.optiiRetries.WithLabelValues(endpoint).Inc()
+}
+
+// ObservePages records the number of pages retrieved by a paginated method of the SDK.
+func (m *Metrics) ObservePages(method string, pages int) {
+	m.optiiPages.WithLabelValues(method).Observe(float64(pages))
+}
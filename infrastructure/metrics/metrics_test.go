@@ -0,0 +1,53 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetrics(t *testing.T) {
+	t.Run("should record the rules and the calls to Optii", func(t *testing.T) {
+		m := New()
+
+		m.ObserveRuleMatch("CleanBedsFloor")
+		m.ObserveRuleMatch("CleanBedsFloor")
+		m.ObserveExecute("CleanBedsFloor", time.Second, true)
+		m.ObserveExecute("CleanBedsFloor", time.Second, false)
+		m.ObserveRequest("/departments/{id}", 503, time.Second)
+		m.ObserveRequest("/departments/{id}", 200, time.Second)
+		m.ObserveRetry("/departments/{id}")
+
+		assert.Equal(t, float64(2), testutil.ToFloat64(m.ruleMatches.WithLabelValues("CleanBedsFloor")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.ruleErrors.WithLabelValues("CleanBedsFloor")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.optiiRequests.WithLabelValues("/departments/{id}", "503")))
+		assert.Equal(t, float64(1), testutil.ToFloat64(m.optiiRetries.WithLabelValues("/departments/{id}")))
+	})
+
+	t.Run("should serve the metrics", func(t *testing.T) {
+		m := New()
+		m.ObserveHTTPRequest("POST", "/v1/jobs", 200)
+		m.ObserveLoadJob(time.Second, false)
+		m.ObservePages("GetFloorRooms", 3)
+
+		res := httptest.NewRecorder()
+		m.Handler().ServeHTTP(res, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+		body, err := io.ReadAll(res.Body)
+		assert.NoError(t, err)
+		for _, metric := range []string{
+			`job_rule_engine_http_requests_total{method="POST",route="/v1/jobs",status="200"} 1`,
+			`job_rule_engine_load_job_duration_seconds_count{result="success"} 1`,
+			`job_rule_engine_optii_pages_sum{method="GetFloorRooms"} 3`,
+			`go_goroutines`,
+		} {
+			assert.True(t, strings.Contains(string(body), metric), "missing %s", metric)
+		}
+	})
+}
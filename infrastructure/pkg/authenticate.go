@@ -1,13 +1,19 @@
 package pkg
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
+
+	"golang.org/x/sync/singleflight"
 )
 
 type Auth struct {
@@ -17,74 +23,224 @@ type Auth struct {
 	IssuedAt    string `json:"issued_at"`
 }
 
-var auth *Auth
+// Defaults of the TokenSource.
+const (
+	defaultRefreshBefore = time.Minute
+	defaultAuthRetryMax  = 3
+	defaultAuthRetryWait = 200 * time.Millisecond
+	// authTimeout bounds an authentication, retries included, since it doesn't end with the callers waiting for it
+	authTimeout = 30 * time.Second
+)
+
+// TokenSource obtains the access tokens of Optii with the client credentials flow.
+// The token is reused until RefreshBefore its expiration, when the next call to Token refreshes it in the background.
+// It's safe for concurrent use, concurrent calls share a single authentication request.
+type TokenSource struct {
+	ClientID     string
+	ClientSecret string
+	AuthURL      string
+	Client       *http.Client
+	// RefreshBefore is how long before its expiration the token is refreshed, one minute when zero.
+	RefreshBefore time.Duration
+	// RetryMax is the number of retries of an authentication request which failed
+	// without a response or with a 5xx or 429 status, 3 when zero and none when negative.
+	RetryMax int
+	// RetryWait is the wait before the first retry, doubled for each retry.
+	RetryWait time.Duration
+
+	now       func() time.Time
+	group     singleflight.Group
+	mu        sync.Mutex
+	auth      *Auth
+	expiresAt time.Time
+}
+
+// NewTokenSource creates a token source for the client credentials of Optii.
+func NewTokenSource(clientID, clientSecret, authURL string) *TokenSource {
+	return &TokenSource{
+		ClientID:      clientID,
+		ClientSecret:  clientSecret,
+		AuthURL:       authURL,
+		Client:        &http.Client{},
+		RefreshBefore: defaultRefreshBefore,
+		RetryMax:      defaultAuthRetryMax,
+		RetryWait:     defaultAuthRetryWait,
+		now:           time.Now,
+	}
+}
+
+// NewTokenSourceFromEnv creates a token source with the OPTII_CLIENT_ID, OPTII_CLIENT_SECRET and OPTII_AUTH_URL
+// environment variables.
+func NewTokenSourceFromEnv() *TokenSource {
+	return NewTokenSource(os.Getenv("OPTII_CLIENT_ID"), os.Getenv("OPTII_CLIENT_SECRET"), os.Getenv("OPTII_AUTH_URL"))
+}
+
+// Token returns a valid access token, authenticating when there is none or it has expired.
+// A token about to expire is returned while it's refreshed in the background, and kept when the refresh fails.
+// The callers waiting for an authentication stop waiting when their context is done.
+func (ts *TokenSource) Token(ctx context.Context) (string, error) {
+	ts.mu.Lock()
+	auth, expiresAt := ts.auth, ts.expiresAt
+	ts.mu.Unlock()
+
+	now := ts.clock()
+	if auth != nil && now.Before(expiresAt) {
+		if !now.Before(expiresAt.Add(-ts.refreshBefore())) {
+			ts.refresh(ctx)
+		}
+		return auth.AccessToken, nil
+	}
+
+	select {
+	case result := <-ts.refresh(ctx):
+		if result.Err != nil {
+			return "", result.Err
+		}
+		return result.Val.(string), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// refresh authenticates once for the concurrent callers, and replaces the current token when it succeeds.
+// The authentication keeps the values of the context but isn't cancelled with it, it's bounded by authTimeout.
+func (ts *TokenSource) refresh(ctx context.Context) <-chan singleflight.Result {
+	return ts.group.DoChan("token", func() (interface{}, error) {
+		ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), authTimeout)
+		defer cancel()
 
-// Authenticate is a function that authenticates the user.
-// It checks if the authentication is valid and if not, makes an authentication request.
-// It returns the authenticated user and any error that occurred during the authentication process.
-func Authenticate() (*Auth, error) {
-	var err error
-	if !auth.isValid() {
-		auth, err = makeAuthRequest()
+		auth, err := ts.authenticate(ctx)
 		if err != nil {
 			return nil, err
 		}
+
+		ts.mu.Lock()
+		defer ts.mu.Unlock()
+		ts.auth = auth
+		ts.expiresAt = auth.expiresAt(ts.clock())
+
+		return auth.AccessToken, nil
+	})
+}
+
+// Invalidate discards the token when it's the current one, e.g. after Optii rejected it,
+// so the next call to Token authenticates again.
+func (ts *TokenSource) Invalidate(token string) {
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if ts.auth != nil && ts.auth.AccessToken == token {
+		ts.auth = nil
 	}
+}
 
-	return auth, nil
+func (ts *TokenSource) clock() time.Time {
+	if ts.now == nil {
+		return time.Now()
+	}
+
+	return ts.now()
 }
 
-// makeAuthRequest sends an authentication request to the OPTII_AUTH_URL endpoint using the client credentials flow.
-// It retrieves the client ID, client secret, and authentication URL from environment variables.
-// The function returns an Auth struct containing the authentication response or an error if the request fails.
-func makeAuthRequest() (*Auth, error) {
-	clientID := os.Getenv("OPTII_CLIENT_ID")
-	clientSecret := os.Getenv("OPTII_CLIENT_SECRET")
-	authURL := os.Getenv("OPTII_AUTH_URL")
+func (ts *TokenSource) refreshBefore() time.Duration {
+	if ts.RefreshBefore <= 0 {
+		return defaultRefreshBefore
+	}
+
+	return ts.RefreshBefore
+}
 
+// authenticate sends the authentication request, retrying the failures which may be temporary.
+func (ts *TokenSource) authenticate(ctx context.Context) (*Auth, error) {
+	retryMax := ts.RetryMax
+	if retryMax == 0 {
+		retryMax = defaultAuthRetryMax
+	}
+	wait := ts.RetryWait
+
+	for attempt := 0; ; attempt++ {
+		auth, err := ts.makeAuthRequest(ctx)
+		var temporary *temporaryError
+		if err == nil || !errors.As(err, &temporary) || attempt >= retryMax {
+			return auth, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+}
+
+// temporaryError is an authentication failure which may succeed when retried.
+type temporaryError struct {
+	err error
+}
+
+func (e *temporaryError) Error() string {
+	return e.err.Error()
+}
+
+func (e *temporaryError) Unwrap() error {
+	return e.err
+}
+
+// makeAuthRequest sends an authentication request to the AuthURL endpoint using the client credentials flow.
+// The function returns an Auth struct containing the authentication response or an error if the request fails.
+func (ts *TokenSource) makeAuthRequest(ctx context.Context) (*Auth, error) {
 	data := url.Values{}
-	data.Set("client_id", clientID)
-	data.Set("client_secret", clientSecret)
+	data.Set("client_id", ts.ClientID)
+	data.Set("client_secret", ts.ClientSecret)
 	data.Set("grant_type", "client_credentials")
 	data.Set("scope", "openapi")
 
-	request, err := http.NewRequest("POST", authURL, strings.NewReader(data.Encode()))
+	request, err := http.NewRequestWithContext(ctx, "POST", ts.AuthURL, strings.NewReader(data.Encode()))
 	if err != nil {
 		return nil, err
 	}
 	request.Header.Add("Content-Type", "application/x-www-form-urlencoded")
 
-	client := &http.Client{}
+	client := ts.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
 	resp, err := client.Do(request)
 	if err != nil {
-		return nil, err
+		if ctx.Err() != nil {
+			return nil, err
+		}
+		return nil, &temporaryError{err: err}
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		err := fmt.Errorf("authentication failed with status %d", resp.StatusCode)
+		if resp.StatusCode >= http.StatusInternalServerError || resp.StatusCode == http.StatusTooManyRequests {
+			return nil, &temporaryError{err: err}
+		}
+		return nil, err
+	}
+
 	auth := &Auth{}
 	if err := json.NewDecoder(resp.Body).Decode(auth); err != nil {
 		return nil, err
 	}
+	if auth.AccessToken == "" {
+		return nil, errors.New("authentication response without access token")
+	}
 
 	return auth, nil
 }
 
-func (a *Auth) isValid() bool {
-	if a == nil {
-		return false
+// expiresAt returns when the token expires, from its issued_at in milliseconds
+// or from the time it was received when issued_at is missing.
+func (a *Auth) expiresAt(received time.Time) time.Time {
+	issuedAt := received
+	if issuedAtMillis, err := strconv.ParseInt(a.IssuedAt, 10, 64); err == nil {
+		issuedAt = time.UnixMilli(issuedAtMillis)
 	}
 
-	issuedAtMillis, err := strconv.ParseInt(a.IssuedAt, 10, 64)
-	if err != nil {
-		return false
-	}
-
-	// Convert issuedAt from milliseconds to time.Time
-	issuedAtTime := time.UnixMilli(issuedAtMillis)
-
-	// Calculate the expiration time
-	expirationTime := issuedAtTime.Add(time.Duration(a.ExpireIn) * time.Second)
-
-	// Compare the expiration time with the current time
-	return time.Now().Before(expirationTime)
+	return issuedAt.Add(time.Duration(a.ExpireIn) * time.Second)
 }
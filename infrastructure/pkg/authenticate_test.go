@@ -1,11 +1,13 @@
 package pkg
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
 	"net/http/httptest"
-	"os"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -13,16 +15,11 @@ import (
 )
 
 func TestMakeAuthRequest(t *testing.T) {
-	// Set up test environment variables
-	os.Setenv("OPTII_CLIENT_ID", "test_client_id")
-	os.Setenv("OPTII_CLIENT_SECRET", "test_client_secret")
-	os.Setenv("OPTII_AUTH_URL", "http://test-auth-url.com")
 	issuedAt := time.Now().UnixMilli()
 
 	// Create a test server to mock the HTTP endpoint
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Verify the request method and URL
-		fmt.Printf("Request: %+v\n", r)
 		if r.Method != "POST" {
 			t.Errorf("Unexpected request: %s %s", r.Method, r.URL.String())
 		}
@@ -41,11 +38,9 @@ func TestMakeAuthRequest(t *testing.T) {
 	}))
 	defer server.Close()
 
-	// Override the authURL with the test server URL
-	os.Setenv("OPTII_AUTH_URL", server.URL)
-
 	// Call the function under test
-	auth, err := makeAuthRequest()
+	ts := NewTokenSource("test_client_id", "test_client_secret", server.URL)
+	auth, err := ts.makeAuthRequest(context.Background())
 	if err != nil {
 		t.Errorf("Unexpected error: %v", err)
 	}
@@ -59,3 +54,212 @@ func TestMakeAuthRequest(t *testing.T) {
 	}
 	assert.Equal(t, expectedAuth, auth)
 }
+
+// newAuthServer returns a server answering the authentication requests with the status of respond,
+// and a new token "token-<n>" valid for an hour when the status is 200.
+func newAuthServer(calls *int32, respond func(call int32) int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		call := atomic.AddInt32(calls, 1)
+		status := respond(call)
+		w.WriteHeader(status)
+		if status == http.StatusOK {
+			fmt.Fprintf(w, `{"access_token": "token-%d", "expires_in": 3600}`, call)
+		}
+	}))
+}
+
+func TestTokenSource(t *testing.T) {
+	ok := func(call int32) int { return http.StatusOK }
+
+	t.Run("should reuse the token until it's about to expire", func(t *testing.T) {
+		calls := int32(0)
+		server := newAuthServer(&calls, ok)
+		defer server.Close()
+
+		clock := newClock()
+		ts := NewTokenSource("id", "secret", server.URL)
+		ts.now = clock.Now
+
+		token, err := ts.Token(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "token-1", token)
+
+		clock.Add(58 * time.Minute)
+		token, _ = ts.Token(context.Background())
+		assert.Equal(t, "token-1", token)
+
+		// Within RefreshBefore of the expiration the token is refreshed in the background
+		clock.Add(time.Minute + time.Second)
+		token, _ = ts.Token(context.Background())
+		assert.Equal(t, "token-1", token)
+		assert.Eventually(t, func() bool {
+			token, _ := ts.Token(context.Background())
+			return token == "token-2"
+		}, time.Second, 5*time.Millisecond)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+
+	t.Run("should return the valid token without waiting for its refresh", func(t *testing.T) {
+		calls := int32(0)
+		release := make(chan struct{})
+		server := newAuthServer(&calls, func(call int32) int {
+			if call > 1 {
+				<-release
+			}
+			return http.StatusOK
+		})
+		defer server.Close()
+		defer close(release)
+
+		clock := newClock()
+		ts := NewTokenSource("id", "secret", server.URL)
+		ts.now = clock.Now
+		ts.Token(context.Background())
+
+		clock.Add(59*time.Minute + 30*time.Second)
+		for i := 0; i < 3; i++ {
+			token, err := ts.Token(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "token-1", token)
+		}
+	})
+
+	t.Run("should stop waiting for the authentication when the context is done", func(t *testing.T) {
+		calls := int32(0)
+		release := make(chan struct{})
+		server := newAuthServer(&calls, func(call int32) int {
+			<-release
+			return http.StatusOK
+		})
+		defer server.Close()
+		defer close(release)
+
+		ts := NewTokenSource("id", "secret", server.URL)
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		_, err := ts.Token(ctx)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+
+	t.Run("should share a single authentication between concurrent calls", func(t *testing.T) {
+		calls := int32(0)
+		server := newAuthServer(&calls, ok)
+		defer server.Close()
+
+		ts := NewTokenSource("id", "secret", server.URL)
+		wg := sync.WaitGroup{}
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				token, err := ts.Token(context.Background())
+				assert.NoError(t, err)
+				assert.Equal(t, "token-1", token)
+			}()
+		}
+		wg.Wait()
+
+		assert.Equal(t, int32(1), calls)
+	})
+
+	t.Run("should retry the temporary failures only", func(t *testing.T) {
+		calls := int32(0)
+		server := newAuthServer(&calls, func(call int32) int {
+			if call < 3 {
+				return http.StatusServiceUnavailable
+			}
+			return http.StatusOK
+		})
+		defer server.Close()
+
+		ts := NewTokenSource("id", "secret", server.URL)
+		ts.RetryWait = time.Millisecond
+		token, err := ts.Token(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "token-3", token)
+
+		calls = 0
+		unauthorized := newAuthServer(&calls, func(call int32) int { return http.StatusUnauthorized })
+		defer unauthorized.Close()
+
+		ts = NewTokenSource("id", "wrong", unauthorized.URL)
+		ts.RetryWait = time.Millisecond
+		_, err = ts.Token(context.Background())
+		assert.EqualError(t, err, "authentication failed with status 401")
+		assert.Equal(t, int32(1), calls)
+	})
+
+	t.Run("should keep the current token when its refresh fails", func(t *testing.T) {
+		calls := int32(0)
+		server := newAuthServer(&calls, func(call int32) int {
+			if call == 1 {
+				return http.StatusOK
+			}
+			return http.StatusBadRequest
+		})
+		defer server.Close()
+
+		clock := newClock()
+		ts := NewTokenSource("id", "secret", server.URL)
+		ts.now = clock.Now
+		ts.Token(context.Background())
+
+		clock.Add(59*time.Minute + 30*time.Second)
+		token, err := ts.Token(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "token-1", token)
+		assert.Eventually(t, func() bool {
+			return atomic.LoadInt32(&calls) == 2
+		}, time.Second, 5*time.Millisecond)
+		token, err = ts.Token(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, "token-1", token)
+
+		clock.Add(time.Minute)
+		_, err = ts.Token(context.Background())
+		assert.Error(t, err)
+	})
+
+	t.Run("should authenticate again after the current token is invalidated", func(t *testing.T) {
+		calls := int32(0)
+		server := newAuthServer(&calls, ok)
+		defer server.Close()
+
+		ts := NewTokenSource("id", "secret", server.URL)
+		ts.Token(context.Background())
+
+		// A stale token doesn't discard the current one
+		ts.Invalidate("token-0")
+		token, _ := ts.Token(context.Background())
+		assert.Equal(t, "token-1", token)
+
+		ts.Invalidate("token-1")
+		token, _ = ts.Token(context.Background())
+		assert.Equal(t, "token-2", token)
+	})
+}
+
+// clock is a time which can be advanced while the tokens are refreshed in the background.
+type clock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func newClock() *clock {
+	return &clock{now: time.Now()}
+}
+
+func (c *clock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+func (c *clock) Add(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+}
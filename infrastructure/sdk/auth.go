@@ -0,0 +1,74 @@
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/Twsouza/job-rule-engine/domain/logging"
+)
+
+// TokenSource provides the access tokens sent to Optii, see pkg.TokenSource.
+type TokenSource interface {
+	// Token returns a valid access token.
+	Token(ctx context.Context) (string, error)
+	// Invalidate discards the token, when it's the current one, after Optii rejected it.
+	Invalidate(token string)
+}
+
+// authTransport sets the token of the TokenSource of the SDK, when set, on each HTTP call.
+// A call rejected with 401 is sent once more with a new token, in case the token was revoked
+// or expired earlier than announced.
+type authTransport struct {
+	sdk  *OptiiSdk
+	next http.RoundTripper
+}
+
+func (t *authTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	tokens := t.sdk.Tokens
+	if tokens == nil {
+		return t.next.RoundTrip(request)
+	}
+
+	ctx := request.Context()
+	token, err := tokens.Token(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error authenticating to Optii: %w", err)
+	}
+
+	response, err := t.next.RoundTrip(withToken(request, token))
+	if err != nil || response.StatusCode != http.StatusUnauthorized {
+		return response, err
+	}
+
+	// The body can only be sent again when it can be rewound
+	if request.Body != nil && request.Body != http.NoBody && request.GetBody == nil {
+		return response, nil
+	}
+
+	tokens.Invalidate(token)
+	token, err = tokens.Token(ctx)
+	if err != nil {
+		return response, nil
+	}
+
+	retry := withToken(request, token)
+	if request.GetBody != nil {
+		if retry.Body, err = request.GetBody(); err != nil {
+			return response, nil
+		}
+	}
+
+	t.sdk.logger().InfoContext(ctx, "optii rejected the token, authenticating again", logging.EndpointKey, endpointOf(request.URL))
+	response.Body.Close()
+
+	return t.next.RoundTrip(retry)
+}
+
+// withToken returns a copy of the request with the bearer token, a RoundTripper must not modify the request.
+func withToken(request *http.Request, token string) *http.Request {
+	clone := request.Clone(request.Context())
+	clone.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+
+	return clone
+}
@@ -0,0 +1,97 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/stretchr/testify/assert"
+)
+
+// tokenSourceMock returns "token-<n>", n being incremented by Invalidate.
+type tokenSourceMock struct {
+	current     int
+	invalidated []string
+	err         error
+}
+
+func (m *tokenSourceMock) Token(ctx context.Context) (string, error) {
+	if m.err != nil {
+		return "", m.err
+	}
+	return fmt.Sprintf("token-%d", m.current), nil
+}
+
+func (m *tokenSourceMock) Invalidate(token string) {
+	m.invalidated = append(m.invalidated, token)
+	m.current++
+}
+
+func TestOptiiSdk_Tokens(t *testing.T) {
+	t.Run("should authenticate again once when the token is rejected", func(t *testing.T) {
+		requests := []string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			requests = append(requests, r.Header.Get("Authorization")+" "+string(body))
+			if r.Header.Get("Authorization") != "Bearer token-1" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&domain.JobCreated{ID: 1})
+		}))
+		defer server.Close()
+
+		tokens := &tokenSourceMock{}
+		optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Tokens: tokens}
+		optiiSdk.Client = retryableHttpClient(0, optiiSdk)
+
+		_, err := optiiSdk.CreateJob(context.Background(), &domain.Job{Action: "clean"})
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"token-0"}, tokens.invalidated)
+		assert.Len(t, requests, 2)
+		assert.Equal(t, requests[0][len("Bearer token-0"):], requests[1][len("Bearer token-1"):])
+		assert.Contains(t, requests[1], `"action":"clean"`)
+	})
+
+	t.Run("should not authenticate again more than once", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		tokens := &tokenSourceMock{}
+		optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Tokens: tokens}
+		optiiSdk.Client = retryableHttpClient(0, optiiSdk)
+
+		_, err := optiiSdk.GetDepartmentByID(context.Background(), 1)
+		assert.Error(t, err)
+		assert.Equal(t, 2, calls)
+		assert.Equal(t, []string{"token-0"}, tokens.invalidated)
+	})
+
+	t.Run("should not call Optii when the authentication fails", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+		}))
+		defer server.Close()
+
+		optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Tokens: &tokenSourceMock{err: errors.New("invalid client")}}
+		optiiSdk.Client = retryableHttpClient(0, optiiSdk)
+
+		_, err := optiiSdk.GetDepartmentByID(context.Background(), 1)
+		assert.ErrorContains(t, err, "error authenticating to Optii: invalid client")
+		assert.Equal(t, 0, calls)
+	})
+}
@@ -0,0 +1,70 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/Twsouza/job-rule-engine/domain/services"
+)
+
+// APIError is an error response of Optii.
+// It matches services.ErrNotFound for a 404, and services.ErrUpstream for the failures of Optii
+// or when it rejects the credentials of the server, see errors.Is.
+type APIError struct {
+	// Status is the HTTP status of the response.
+	Status int
+	Type   string
+	Title  string
+	Detail string
+	// Endpoint is the endpoint called, e.g. "/departments/{id}".
+	Endpoint string
+}
+
+func (e *APIError) Error() string {
+	if e.Title == "" {
+		return fmt.Sprintf("%s returned status %d", e.Endpoint, e.Status)
+	}
+
+	return fmt.Sprintf("%s: %s", e.Title, e.Detail)
+}
+
+// Is reports whether the error matches services.ErrNotFound or services.ErrUpstream.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case services.ErrNotFound:
+		return e.Status == http.StatusNotFound
+	case services.ErrUpstream:
+		return e.Retryable() || e.Status == http.StatusUnauthorized || e.Status == http.StatusForbidden
+	}
+
+	return false
+}
+
+// Retryable reports whether the same request may succeed later: a timeout, a rate limit or a failure of Optii.
+func (e *APIError) Retryable() bool {
+	return e.Status == http.StatusRequestTimeout || e.Status == http.StatusTooManyRequests || e.Status >= http.StatusInternalServerError
+}
+
+// IsNotFound reports whether the error is an APIError for an entity which doesn't exist.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Status == http.StatusNotFound
+}
+
+// IsRetryable reports whether the error is an APIError for a request which may succeed later.
+func IsRetryable(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Retryable()
+}
+
+// upstreamError marks the error of a call which didn't receive a response as services.ErrUpstream,
+// unless the context of the call was cancelled or its deadline exceeded.
+func upstreamError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: %w", services.ErrUpstream, err)
+}
@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIError(t *testing.T) {
+	t.Run("should return the error response with its status and endpoint", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNotFound)
+			// The trailing comma is sent by Optii
+			w.Write([]byte(`{"type": "about:blank", "title": "Not Found", "status": 404, "detail": "Department 12 not found",}`))
+		}))
+		defer server.Close()
+
+		optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient}
+		_, err := optiiSdk.GetDepartmentByID(context.Background(), 12)
+
+		assert.EqualError(t, err, "Not Found: Department 12 not found")
+		assert.Equal(t, &APIError{
+			Status:   http.StatusNotFound,
+			Type:     "about:blank",
+			Title:    "Not Found",
+			Detail:   "Department 12 not found",
+			Endpoint: "/departments/{id}",
+		}, err)
+		assert.True(t, IsNotFound(err))
+		assert.False(t, IsRetryable(err))
+		assert.ErrorIs(t, fmt.Errorf("department %w", err), services.ErrNotFound)
+		assert.NotErrorIs(t, err, services.ErrUpstream)
+	})
+
+	t.Run("should return the status when the body is not an error response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte(`<html>Bad Gateway</html>`))
+		}))
+		defer server.Close()
+
+		optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient}
+		_, err := optiiSdk.GetJobItemByID(context.Background(), 3)
+
+		assert.EqualError(t, err, "/jobitems/{id} returned status 502")
+		assert.True(t, IsRetryable(err))
+		assert.ErrorIs(t, err, services.ErrUpstream)
+	})
+
+	t.Run("should classify the statuses", func(t *testing.T) {
+		for status, upstream := range map[int]bool{
+			http.StatusBadRequest:          false,
+			http.StatusUnauthorized:        true,
+			http.StatusTooManyRequests:     true,
+			http.StatusServiceUnavailable:  true,
+			http.StatusUnprocessableEntity: false,
+		} {
+			assert.Equal(t, upstream, errors.Is(&APIError{Status: status}, services.ErrUpstream), status)
+		}
+	})
+
+	t.Run("should mark the calls without response as upstream failures", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		server.Close()
+
+		optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient}
+		_, err := optiiSdk.GetDepartmentByID(context.Background(), 1)
+
+		assert.ErrorIs(t, err, services.ErrUpstream)
+	})
+}
@@ -0,0 +1,159 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+// Defaults of the iterators of the SDK.
+const (
+	defaultPageSize = 100
+	defaultMaxPages = 1000
+)
+
+var (
+	// ErrTooManyPages is returned by an Iterator going through more pages than its maximum.
+	ErrTooManyPages = errors.New("too many pages")
+	// ErrCursorNotAdvancing is returned by an Iterator when a page has a next page but its end cursor
+	// isn't after its start, which would retrieve the same page forever.
+	ErrCursorNotAdvancing = errors.New("page cursor not advancing")
+)
+
+// PageFunc retrieves the page of at most next items starting at the cursor first.
+type PageFunc[T any] func(ctx context.Context, first int32, next int32) ([]T, *PageInfo, error)
+
+// Iterator goes through the items of a paginated list of Optii, retrieving the pages as they are needed.
+// Each page starts at the end cursor of the previous one.
+//
+//	it := optii.Locations(ctx, "Room")
+//	for it.Next() {
+//		location := it.Item()
+//	}
+//	if err := it.Err(); err != nil {
+//	}
+type Iterator[T any] struct {
+	ctx      context.Context
+	fetch    PageFunc[T]
+	pageSize int32
+	maxPages int
+
+	cursor int32
+	page   []T
+	index  int
+	item   T
+	pages  int
+	last   bool
+	err    error
+}
+
+// NewIterator creates an iterator retrieving pages of pageSize items with fetch, up to maxPages pages.
+// A pageSize or maxPages lower than one is replaced by the default, 100 items and 1000 pages.
+// The iteration stops with the error of the context once it's done.
+func NewIterator[T any](ctx context.Context, fetch PageFunc[T], pageSize int32, maxPages int) *Iterator[T] {
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if maxPages < 1 {
+		maxPages = defaultMaxPages
+	}
+
+	return &Iterator[T]{ctx: ctx, fetch: fetch, pageSize: pageSize, maxPages: maxPages}
+}
+
+// Next advances to the next item, retrieving the next page when needed.
+// It returns false when there are no more items or the iteration failed, see Err.
+func (it *Iterator[T]) Next() bool {
+	for it.index >= len(it.page) {
+		if it.last || it.err != nil {
+			return false
+		}
+		it.fetchPage()
+	}
+
+	it.item = it.page[it.index]
+	it.index++
+
+	return true
+}
+
+// Item returns the current item.
+func (it *Iterator[T]) Item() T {
+	return it.item
+}
+
+// Err returns the error which stopped the iteration, if any.
+func (it *Iterator[T]) Err() error {
+	return it.err
+}
+
+// Pages returns the number of pages retrieved.
+func (it *Iterator[T]) Pages() int {
+	return it.pages
+}
+
+// All returns the remaining items.
+func (it *Iterator[T]) All() ([]T, error) {
+	var items []T
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+
+	return items, it.Err()
+}
+
+func (it *Iterator[T]) fetchPage() {
+	if err := it.ctx.Err(); err != nil {
+		it.err = err
+		return
+	}
+	if it.pages >= it.maxPages {
+		it.err = fmt.Errorf("%w: more than %d pages", ErrTooManyPages, it.maxPages)
+		return
+	}
+
+	page, pageInfo, err := it.fetch(it.ctx, it.cursor, it.pageSize)
+	if err != nil {
+		it.err = err
+		return
+	}
+	it.pages++
+	it.page, it.index = page, 0
+
+	switch {
+	case pageInfo == nil || !pageInfo.HasNextPage:
+		it.last = true
+	case int32(pageInfo.EndCursor) <= it.cursor:
+		// The items of the page are still returned
+		it.last = true
+		it.err = fmt.Errorf("%w: end cursor %d after cursor %d", ErrCursorNotAdvancing, pageInfo.EndCursor, it.cursor)
+	default:
+		it.cursor = int32(pageInfo.EndCursor)
+	}
+}
+
+// Locations returns an iterator over the locations of the locationType, or every location when empty.
+// The pages have PageSize locations, and at most MaxPages pages are retrieved.
+func (o *OptiiSdk) Locations(ctx context.Context, locationType string) *Iterator[domain.Location] {
+	return NewIterator(ctx, func(ctx context.Context, first int32, next int32) ([]domain.Location, *PageInfo, error) {
+		locationsQuery, err := o.GetLocations(ctx, first, next, locationType)
+		if err != nil {
+			return nil, nil, err
+		}
+		return locationsQuery.Locations, locationsQuery.PageInfo, nil
+	}, o.PageSize, o.MaxPages)
+}
+
+// Jobs returns an iterator over the jobs selected by the filter.
+// The pages have PageSize jobs, and at most MaxPages pages are retrieved.
+func (o *OptiiSdk) Jobs(ctx context.Context, filter domain.JobFilter) *Iterator[domain.JobCreated] {
+	return NewIterator(ctx, func(ctx context.Context, first int32, next int32) ([]domain.JobCreated, *PageInfo, error) {
+		page, err := o.ListJobs(ctx, filter, first, next)
+		if err != nil {
+			return nil, nil, err
+		}
+		return page.Jobs, page.PageInfo, nil
+	}, o.PageSize, o.MaxPages)
+}
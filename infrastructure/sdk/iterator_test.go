@@ -0,0 +1,163 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/infrastructure/fakeoptii"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIterator(t *testing.T) {
+	// pages returns a PageFunc over the items 0 to n-1, recording the cursors and sizes requested
+	pages := func(n int, requests *[][2]int32) PageFunc[int] {
+		return func(ctx context.Context, first int32, next int32) ([]int, *PageInfo, error) {
+			*requests = append(*requests, [2]int32{first, next})
+			end := min(int(first+next), n)
+			items := []int{}
+			for i := int(first); i < end; i++ {
+				items = append(items, i)
+			}
+			return items, &PageInfo{EndCursor: end, HasNextPage: end < n, TotalCount: n}, nil
+		}
+	}
+
+	t.Run("should go through every page from the end cursor of the previous one", func(t *testing.T) {
+		requests := [][2]int32{}
+		it := NewIterator(context.Background(), pages(25, &requests), 10, 0)
+
+		items, err := it.All()
+		assert.NoError(t, err)
+		assert.Len(t, items, 25)
+		assert.Equal(t, 24, items[24])
+		assert.Equal(t, [][2]int32{{0, 10}, {10, 10}, {20, 10}}, requests)
+		assert.Equal(t, 3, it.Pages())
+	})
+
+	t.Run("should retrieve the pages as they are needed", func(t *testing.T) {
+		requests := [][2]int32{}
+		it := NewIterator(context.Background(), pages(25, &requests), 10, 0)
+
+		for i := 0; i < 11; i++ {
+			assert.True(t, it.Next())
+		}
+		assert.Equal(t, 10, it.Item())
+		assert.Equal(t, 2, it.Pages())
+	})
+
+	t.Run("should stop after the maximum number of pages", func(t *testing.T) {
+		requests := [][2]int32{}
+		it := NewIterator(context.Background(), pages(25, &requests), 10, 2)
+
+		items, err := it.All()
+		assert.ErrorIs(t, err, ErrTooManyPages)
+		assert.Len(t, items, 20)
+		assert.Len(t, requests, 2)
+	})
+
+	t.Run("should stop when the cursor doesn't advance", func(t *testing.T) {
+		calls := 0
+		it := NewIterator(context.Background(), func(ctx context.Context, first int32, next int32) ([]int, *PageInfo, error) {
+			calls++
+			return []int{1, 2}, &PageInfo{EndCursor: 0, HasNextPage: true}, nil
+		}, 0, 0)
+
+		items, err := it.All()
+		assert.ErrorIs(t, err, ErrCursorNotAdvancing)
+		assert.Equal(t, []int{1, 2}, items)
+		assert.Equal(t, 1, calls)
+	})
+
+	t.Run("should stop when the context is done", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		requests := [][2]int32{}
+		it := NewIterator(ctx, pages(25, &requests), 10, 0)
+
+		assert.True(t, it.Next())
+		cancel()
+		items, err := it.All()
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Len(t, items, 9)
+		assert.Len(t, requests, 1)
+	})
+
+	t.Run("should stop with the error of a page", func(t *testing.T) {
+		it := NewIterator(context.Background(), func(ctx context.Context, first int32, next int32) ([]int, *PageInfo, error) {
+			return nil, nil, errors.New("page failed")
+		}, 0, 0)
+
+		assert.False(t, it.Next())
+		assert.EqualError(t, it.Err(), "page failed")
+		assert.False(t, it.Next())
+	})
+}
+
+func TestOptiiSdk_Locations(t *testing.T) {
+	// 250 locations, the even ones on floor 1 and the odd ones on floor 2
+	fixture := &fakeoptii.Fixture{}
+	for i := 1; i <= 250; i++ {
+		fixture.Locations = append(fixture.Locations, domain.Location{
+			ID:             i,
+			ParentLocation: &domain.ParentLocation{ID: 1 + i%2},
+			LocationType:   &domain.LocationType{DisplayName: "Room"},
+		})
+	}
+	server := httptest.NewServer(fakeoptii.New(fixture))
+	defer server.Close()
+
+	t.Run("should go through the pages of the given size", func(t *testing.T) {
+		optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient, PageSize: 60}
+
+		it := optiiSdk.Locations(context.Background(), "Room")
+		locations, err := it.All()
+		assert.NoError(t, err)
+		assert.Len(t, locations, 250)
+		assert.Equal(t, 5, it.Pages())
+	})
+
+	t.Run("should return the locations of a floor spread over several pages", func(t *testing.T) {
+		optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient}
+
+		locations, err := optiiSdk.GetFloorLocations(context.Background(), 1)
+		assert.NoError(t, err)
+		assert.Len(t, locations, 125)
+		assert.Equal(t, 250, locations[124].ID)
+
+		rooms, err := optiiSdk.GetFloorRooms(context.Background(), 2)
+		assert.NoError(t, err)
+		assert.Len(t, rooms, 125)
+	})
+
+	t.Run("should stop a listing going through too many pages", func(t *testing.T) {
+		optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient, PageSize: 10, MaxPages: 3}
+
+		_, err := optiiSdk.GetAllLocations(context.Background())
+		assert.ErrorIs(t, err, ErrTooManyPages)
+	})
+}
+
+func TestOptiiSdk_Jobs(t *testing.T) {
+	t.Run("should go through the pages of the jobs", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "clean", r.URL.Query().Get("action"))
+			page := domain.JobPage{Jobs: []domain.JobCreated{{ID: 1}, {ID: 2}}, PageInfo: &PageInfo{EndCursor: 2, HasNextPage: true}}
+			if r.URL.Query().Get("first") == "2" {
+				page = domain.JobPage{Jobs: []domain.JobCreated{{ID: 3}}, PageInfo: &PageInfo{EndCursor: 3}}
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(page)
+		}))
+		defer server.Close()
+
+		optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient}
+
+		jobs, err := optiiSdk.Jobs(context.Background(), domain.JobFilter{Action: "clean"}).All()
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.JobCreated{{ID: 1}, {ID: 2}, {ID: 3}}, jobs)
+	})
+}
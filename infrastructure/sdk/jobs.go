@@ -0,0 +1,114 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+)
+
+// GetJob retrieves a job by its ID.
+func (o *OptiiSdk) GetJob(ctx context.Context, id int) (*domain.JobCreated, error) {
+	job := &domain.JobCreated{}
+	if err := o.sendJSON(ctx, http.MethodGet, fmt.Sprintf("jobs/%d", id), nil, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// ListJobs retrieves a page of the jobs selected by the filter.
+// Like GetLocations, first is the cursor of the page and next the number of jobs of the page.
+func (o *OptiiSdk) ListJobs(ctx context.Context, filter domain.JobFilter, first int32, next int32) (*domain.JobPage, error) {
+	query := url.Values{}
+	query.Set("first", strconv.Itoa(int(first)))
+	query.Set("next", strconv.Itoa(int(next)))
+	if filter.Status != "" {
+		query.Set("status", string(filter.Status))
+	}
+	if filter.DepartmentID != 0 {
+		query.Set("departmentId", strconv.Itoa(filter.DepartmentID))
+	}
+	if filter.LocationID != 0 {
+		query.Set("locationId", strconv.Itoa(filter.LocationID))
+	}
+	if filter.Action != "" {
+		query.Set("action", filter.Action)
+	}
+
+	page := &domain.JobPage{}
+	if err := o.sendJSON(ctx, http.MethodGet, "jobs?"+query.Encode(), nil, page); err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// UpdateJob changes the priority, assignee or notes of a job, returning the changed job.
+func (o *OptiiSdk) UpdateJob(ctx context.Context, id int, update domain.JobUpdate) (*domain.JobCreated, error) {
+	job := &domain.JobCreated{}
+	if err := o.sendJSON(ctx, http.MethodPatch, fmt.Sprintf("jobs/%d", id), update, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// CancelJob cancels a job, returning the cancelled job.
+func (o *OptiiSdk) CancelJob(ctx context.Context, id int) (*domain.JobCreated, error) {
+	job := &domain.JobCreated{}
+	if err := o.sendJSON(ctx, http.MethodPost, fmt.Sprintf("jobs/%d/cancel", id), nil, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// CompleteJob marks a job as done, returning the completed job.
+func (o *OptiiSdk) CompleteJob(ctx context.Context, id int) (*domain.JobCreated, error) {
+	job := &domain.JobCreated{}
+	if err := o.sendJSON(ctx, http.MethodPost, fmt.Sprintf("jobs/%d/complete", id), nil, job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// sendJSON sends a request to the path of the API, with the body encoded in JSON when not nil,
+// and decodes the response in result.
+func (o *OptiiSdk) sendJSON(ctx context.Context, method, path string, body any, result any) error {
+	endpoint := fmt.Sprintf("%s/api/%s/%s", o.BaseUrl, o.ApiVersion, path)
+	ctx, cancel := o.withTimeout(ctx)
+	defer cancel()
+
+	var requestBody io.Reader
+	if body != nil {
+		jsonBody, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("error marshalling request: %w", err)
+		}
+		requestBody = bytes.NewReader(jsonBody)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, method, endpoint, requestBody)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", "application/json")
+	}
+
+	response, err := o.Client.Do(request)
+	if err != nil {
+		return upstreamError(ctx, err)
+	}
+	defer response.Body.Close()
+
+	return ParseResponse(response, result)
+}
@@ -0,0 +1,115 @@
+package sdk
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/services"
+	"github.com/Twsouza/job-rule-engine/domain/tasks"
+	"github.com/Twsouza/job-rule-engine/infrastructure/fakeoptii"
+	"github.com/stretchr/testify/assert"
+)
+
+// The SDK is the job lifecycle API of the rules
+var _ tasks.JobLifecycleAPI = &OptiiSdk{}
+
+func TestOptiiSdk_JobLifecycle(t *testing.T) {
+	server := httptest.NewServer(fakeoptii.New(nil))
+	defer server.Close()
+
+	optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient}
+	ctx := context.Background()
+
+	jobs := []*domain.Job{
+		{Action: "clean", Department: domain.JDepartment{ID: 13}, Locations: []domain.JLocation{{ID: 101}}},
+		{Action: "repair", Department: domain.JDepartment{ID: 14}, Locations: []domain.JLocation{{ID: 102}}},
+		{Action: "clean", Department: domain.JDepartment{ID: 13}, Locations: []domain.JLocation{{ID: 102}, {ID: 103}}},
+	}
+	for _, job := range jobs {
+		_, err := optiiSdk.CreateJob(ctx, job)
+		assert.NoError(t, err)
+	}
+
+	t.Run("should get a job by its ID", func(t *testing.T) {
+		job, err := optiiSdk.GetJob(ctx, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, 2, job.ID)
+		assert.Equal(t, "repair", job.Action)
+		assert.Equal(t, domain.JobPending, job.Status)
+
+		_, err = optiiSdk.GetJob(ctx, 99)
+		assert.ErrorIs(t, err, services.ErrNotFound)
+	})
+
+	t.Run("should list the jobs matching the filter", func(t *testing.T) {
+		page, err := optiiSdk.ListJobs(ctx, domain.JobFilter{DepartmentID: 13}, 0, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 3}, jobIDs(page))
+
+		page, err = optiiSdk.ListJobs(ctx, domain.JobFilter{LocationID: 102, Action: "clean"}, 0, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{3}, jobIDs(page))
+	})
+
+	t.Run("should list the jobs by page", func(t *testing.T) {
+		page, err := optiiSdk.ListJobs(ctx, domain.JobFilter{}, 0, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1, 2}, jobIDs(page))
+		assert.Equal(t, &domain.PageInfo{EndCursor: 2, HasNextPage: true, TotalCount: 3}, page.PageInfo)
+
+		page, err = optiiSdk.ListJobs(ctx, domain.JobFilter{}, int32(page.PageInfo.EndCursor), 2)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{3}, jobIDs(page))
+		assert.False(t, page.PageInfo.HasNextPage)
+	})
+
+	t.Run("should update only the given fields", func(t *testing.T) {
+		priority := "high"
+		job, err := optiiSdk.UpdateJob(ctx, 1, domain.JobUpdate{Priority: &priority, Assignee: &domain.JAssignee{ID: 5}})
+		assert.NoError(t, err)
+		assert.Equal(t, "high", job.Priority)
+		assert.Equal(t, map[string]any{"id": float64(5)}, job.Assignee)
+		assert.Nil(t, job.Notes)
+
+		notes := "Room 101 first"
+		job, err = optiiSdk.UpdateJob(ctx, 1, domain.JobUpdate{Notes: &notes})
+		assert.NoError(t, err)
+		assert.Equal(t, "high", job.Priority)
+		assert.Equal(t, "Room 101 first", job.Notes)
+	})
+
+	t.Run("should cancel and complete the jobs", func(t *testing.T) {
+		job, err := optiiSdk.CancelJob(ctx, 2)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.JobCancelled, job.Status)
+
+		job, err = optiiSdk.CompleteJob(ctx, 3)
+		assert.NoError(t, err)
+		assert.Equal(t, domain.JobCompleted, job.Status)
+
+		page, err := optiiSdk.ListJobs(ctx, domain.JobFilter{Status: domain.JobPending}, 0, 100)
+		assert.NoError(t, err)
+		assert.Equal(t, []int{1}, jobIDs(page))
+	})
+
+	t.Run("should fail to change a closed job", func(t *testing.T) {
+		_, err := optiiSdk.CompleteJob(ctx, 2)
+		var apiErr *APIError
+		assert.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusConflict, apiErr.Status)
+		assert.EqualError(t, err, "Conflict: job 2 is cancelled")
+		assert.NotErrorIs(t, err, services.ErrUpstream)
+	})
+}
+
+func jobIDs(page *domain.JobPage) []int {
+	ids := []int{}
+	for _, job := range page.Jobs {
+		ids = append(ids, job.ID)
+	}
+
+	return ids
+}
@@ -0,0 +1,89 @@
+package sdk
+
+import (
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Twsouza/job-rule-engine/domain/logging"
+)
+
+// Observer is notified of the calls sent to Optii, e.g. to export metrics.
+type Observer interface {
+	// ObserveRequest is called for each HTTP call, retries included. The status is zero when no response was received.
+	ObserveRequest(endpoint string, status int, duration time.Duration)
+	// ObserveRetry is called before each retry of a call.
+	ObserveRetry(endpoint string)
+	// ObservePages is called with the number of pages retrieved by a paginated method, e.g. "GetFloorRooms".
+	ObservePages(method string, pages int)
+}
+
+// observingTransport reports each HTTP call to the Observer of the SDK, when set, and logs it.
+type observingTransport struct {
+	sdk  *OptiiSdk
+	next http.RoundTripper
+}
+
+func (t *observingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	start := time.Now()
+	response, err := t.next.RoundTrip(request)
+	duration := time.Since(start)
+	endpoint := endpointOf(request.URL)
+
+	status := 0
+	if err == nil {
+		status = response.StatusCode
+	}
+	if t.sdk.Observer != nil {
+		t.sdk.Observer.ObserveRequest(endpoint, status, duration)
+	}
+
+	ctx := request.Context()
+	switch {
+	case err != nil:
+		t.sdk.logger().WarnContext(ctx, "optii request failed", logging.EndpointKey, endpoint, "method", request.Method, "error", err, "duration", duration)
+	case status >= http.StatusBadRequest:
+		t.sdk.logger().WarnContext(ctx, "optii request failed", logging.EndpointKey, endpoint, "method", request.Method, "status", status, "duration", duration)
+	default:
+		t.sdk.logger().DebugContext(ctx, "optii request", logging.EndpointKey, endpoint, "method", request.Method, "status", status, "duration", duration)
+	}
+
+	return response, err
+}
+
+// endpointOf returns the path of the URL after the API version, with the IDs replaced by {id},
+// e.g. "/departments/{id}" for "/api/v1/departments/12", so it can be used as a metric label.
+func endpointOf(u *url.URL) string {
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	// Skips the "api" and version segments
+	if len(segments) > 2 && segments[0] == "api" {
+		segments = segments[2:]
+	}
+
+	for i, segment := range segments {
+		if segment != "" && strings.IndexFunc(segment, func(r rune) bool { return !unicode.IsDigit(r) }) == -1 {
+			segments[i] = "{id}"
+		}
+	}
+
+	return "/" + strings.Join(segments, "/")
+}
+
+// logger returns the Logger of the SDK, or the default logger.
+func (o *OptiiSdk) logger() *slog.Logger {
+	if o.Logger == nil {
+		return slog.Default()
+	}
+
+	return o.Logger
+}
+
+// observePages reports the number of pages retrieved by a paginated method to the Observer, when set.
+func (o *OptiiSdk) observePages(method string, pages int) {
+	if o.Observer != nil {
+		o.Observer.ObservePages(method, pages)
+	}
+}
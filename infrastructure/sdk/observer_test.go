@@ -0,0 +1,77 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type observerMock struct {
+	requests []string
+	retries  []string
+	pages    map[string]int
+}
+
+func (m *observerMock) ObserveRequest(endpoint string, status int, duration time.Duration) {
+	m.requests = append(m.requests, endpoint+" "+http.StatusText(status))
+}
+
+func (m *observerMock) ObserveRetry(endpoint string) {
+	m.retries = append(m.retries, endpoint)
+}
+
+func (m *observerMock) ObservePages(method string, pages int) {
+	m.pages[method] = pages
+}
+
+func TestEndpointOf(t *testing.T) {
+	for path, expected := range map[string]string{
+		"/api/v1/departments/12": "/departments/{id}",
+		"/api/v1/locations":      "/locations",
+		"/api/v2/jobs/":          "/jobs",
+		"/other/3":               "/other/{id}",
+	} {
+		u, err := url.Parse("https://optii.io" + path + "?first=0")
+		assert.NoError(t, err)
+		assert.Equal(t, expected, endpointOf(u))
+	}
+}
+
+func TestOptiiSdk_Observer(t *testing.T) {
+	t.Run("should report the calls, the retries and the pages", func(t *testing.T) {
+		calls := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			calls++
+			if calls == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&LocationsQuery{PageInfo: &PageInfo{HasNextPage: false}})
+		}))
+		defer server.Close()
+
+		observer := &observerMock{pages: map[string]int{}}
+		optiiSdk := &OptiiSdk{
+			BaseUrl:    server.URL,
+			ApiVersion: "v1",
+			Observer:   observer,
+		}
+
+		optiiSdk.Client = retryableHttpClient(1, optiiSdk)
+
+		_, err := optiiSdk.GetFloorRooms(context.Background(), 1)
+		assert.NoError(t, err)
+
+		assert.Equal(t, []string{"/locations Service Unavailable", "/locations OK"}, observer.requests)
+		assert.Equal(t, []string{"/locations"}, observer.retries)
+		assert.Equal(t, map[string]int{"GetFloorRooms": 1}, observer.pages)
+	})
+}
@@ -2,14 +2,19 @@ package sdk
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"net/http"
 	"sync"
+	"time"
 
 	"github.com/Twsouza/job-rule-engine/domain"
-	"github.com/Twsouza/job-rule-engine/infrastructure/pkg"
+	"github.com/Twsouza/job-rule-engine/domain/logging"
+	"github.com/Twsouza/job-rule-engine/domain/services"
 	"github.com/hashicorp/go-retryablehttp"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
 )
 
 // HTTPClientInterface defines the interface for HTTP client operations
@@ -21,9 +26,30 @@ type OptiiSdk struct {
 	BaseUrl    string
 	ApiVersion string
 	RetryMax   int
-	Client     HTTPClientInterface
+	// Timeout is the deadline of each request sent to Optii, retries included. Zero means no deadline
+	// other than the one of the context given to each method.
+	Timeout time.Duration
+	Client  HTTPClientInterface
+	// Observer is notified of the calls sent to Optii by the client created by NewOptiiSdk, when set.
+	Observer Observer
+	// Logger logs the calls sent to Optii by the client created by NewOptiiSdk, the default logger is used when nil.
+	Logger *slog.Logger
+	// Tokens authenticates the calls sent to Optii by the client created by NewOptiiSdk, when set.
+	Tokens TokenSource
+	// Concurrency is the maximum number of concurrent requests sent by GetLocationsByIds, 8 when not set.
+	Concurrency int
+	// PageSize is the number of items of the pages retrieved by the iterators, 100 when not set.
+	PageSize int32
+	// MaxPages is the maximum number of pages retrieved by an iterator, 1000 when not set.
+	MaxPages int
+	// Transport sends the calls of the client created by NewOptiiSdk, after they are authenticated,
+	// e.g. a cassette.Recorder. A pooled transport is used when nil.
+	Transport http.RoundTripper
 }
 
+// defaultConcurrency is the number of concurrent requests sent by GetLocationsByIds when Concurrency is not set.
+const defaultConcurrency = 8
+
 func NewOptiiSdk(baseUrl, apiVersion string, retryMax int, httpClientInterface *HTTPClientInterface) (*OptiiSdk, error) {
 	if baseUrl == "" {
 		return nil, fmt.Errorf("baseUrl is required")
@@ -44,42 +70,75 @@ func NewOptiiSdk(baseUrl, apiVersion string, retryMax int, httpClientInterface *
 	}
 
 	if httpClientInterface == nil {
-		client, err := RetryableHttpClient(retryMax)
-		if err != nil {
-			return nil, err
-		}
-		optii.Client = client
+		optii.Client = retryableHttpClient(retryMax, optii)
 	}
 
 	return optii, nil
 }
 
-func RetryableHttpClient(retryMax int) (*http.Client, error) {
+// RetryableHttpClient creates a retryable HTTP client authenticating its calls with the tokens, when not nil.
+func RetryableHttpClient(retryMax int, tokens TokenSource) *http.Client {
+	return retryableHttpClient(retryMax, &OptiiSdk{Tokens: tokens})
+}
+
+// retryableHttpClient creates the retryable HTTP client of the SDK. Each call is authenticated
+// with the Tokens of the SDK and reported to its Observer, when they are set.
+func retryableHttpClient(retryMax int, optii *OptiiSdk) *http.Client {
 	// Create a new retryable HTTP client
 	client := retryablehttp.NewClient()
 	client.RetryMax = retryMax
 
-	auth, err := pkg.Authenticate()
-	if err != nil {
-		return nil, err
-	}
-
 	// RequestLogHook allows a user-supplied function to be called before each retry.
-	// It will be used to set the Authorization header
 	client.RequestLogHook = func(logger retryablehttp.Logger, request *http.Request, retryNumber int) {
-		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", auth.AccessToken))
 		request.Header.Set("Content-Type", "application/json")
 		request.Header.Set("Accept", "application/json")
+
+		if retryNumber > 0 {
+			optii.logger().WarnContext(request.Context(), "retrying optii request",
+				logging.EndpointKey, endpointOf(request.URL), "retry", retryNumber)
+			if optii.Observer != nil {
+				optii.Observer.ObserveRetry(endpointOf(request.URL))
+			}
+		}
+	}
+
+	client.HTTPClient.Transport = &baseTransport{sdk: optii, next: client.HTTPClient.Transport}
+	// Every attempt is traced in its own span, and carries the traceparent header to Optii
+	client.HTTPClient.Transport = otelhttp.NewTransport(client.HTTPClient.Transport,
+		otelhttp.WithSpanNameFormatter(func(operation string, r *http.Request) string {
+			return fmt.Sprintf("%s %s", r.Method, endpointOf(r.URL))
+		}),
+	)
+	client.HTTPClient.Transport = &observingTransport{sdk: optii, next: client.HTTPClient.Transport}
+	client.HTTPClient.Transport = &authTransport{sdk: optii, next: client.HTTPClient.Transport}
+	// The calls are logged by the transport, with the context of each request
+	client.Logger = nil
+
+	return client.StandardClient()
+}
+
+// baseTransport sends the calls with the Transport of the SDK, or with next when it's not set.
+type baseTransport struct {
+	sdk  *OptiiSdk
+	next http.RoundTripper
+}
+
+func (t *baseTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	if t.sdk.Transport != nil {
+		return t.sdk.Transport.RoundTrip(request)
 	}
 
-	return client.StandardClient(), nil
+	return t.next.RoundTrip(request)
 }
 
 // GetDepartmentByID retrieves a department by its ID.
-func (o *OptiiSdk) GetDepartmentByID(id int64) (*domain.Department, error) {
+func (o *OptiiSdk) GetDepartmentByID(ctx context.Context, id int64) (*domain.Department, error) {
 	// Create a new GET request
 	endpoint := fmt.Sprintf("%s/api/%s/departments/%d", o.BaseUrl, o.ApiVersion, id)
-	request, err := retryablehttp.NewRequest(http.MethodGet, endpoint, nil)
+	ctx, cancel := o.withTimeout(ctx)
+	defer cancel()
+
+	request, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -87,7 +146,7 @@ func (o *OptiiSdk) GetDepartmentByID(id int64) (*domain.Department, error) {
 	// Send the request
 	response, err := o.Client.Do(request.Request)
 	if err != nil {
-		return nil, err
+		return nil, upstreamError(ctx, err)
 	}
 	defer response.Body.Close()
 
@@ -102,10 +161,13 @@ func (o *OptiiSdk) GetDepartmentByID(id int64) (*domain.Department, error) {
 }
 
 // GetJobItemByID retrieves a job item by its ID from the Optii SDK.
-func (o *OptiiSdk) GetJobItemByID(id int64) (*domain.JobItem, error) {
+func (o *OptiiSdk) GetJobItemByID(ctx context.Context, id int64) (*domain.JobItem, error) {
 	// Create a new GET request
 	endpoint := fmt.Sprintf("%s/api/%s/jobitems/%d", o.BaseUrl, o.ApiVersion, id)
-	request, err := retryablehttp.NewRequest(http.MethodGet, endpoint, nil)
+	ctx, cancel := o.withTimeout(ctx)
+	defer cancel()
+
+	request, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -113,7 +175,7 @@ func (o *OptiiSdk) GetJobItemByID(id int64) (*domain.JobItem, error) {
 	// Send the request
 	response, err := o.Client.Do(request.Request)
 	if err != nil {
-		return nil, err
+		return nil, upstreamError(ctx, err)
 	}
 	defer response.Body.Close()
 
@@ -127,48 +189,57 @@ func (o *OptiiSdk) GetJobItemByID(id int64) (*domain.JobItem, error) {
 	return &jobItem, nil
 }
 
-// GetLocationsByIds retrieves locations by their IDs.
-func (o *OptiiSdk) GetLocationsByIds(ids []int64) ([]domain.Location, error) {
+// GetLocationsByIds retrieves locations by their IDs, with at most Concurrency concurrent requests.
+// The locations are returned in the order of the IDs. When some of them can't be retrieved,
+// the others are returned along with a services.LocationErrors keyed by the ID of the failed ones.
+func (o *OptiiSdk) GetLocationsByIds(ctx context.Context, ids []int64) ([]domain.Location, error) {
+	concurrency := o.Concurrency
+	if concurrency < 1 {
+		concurrency = defaultConcurrency
+	}
+
+	loaded := make([]*domain.Location, len(ids))
+	errs := make([]error, len(ids))
+	sem := make(chan struct{}, concurrency)
 	wg := sync.WaitGroup{}
-	locationChan := make(chan domain.Location, len(ids))
-	errChan := make(chan error)
 
-	for _, id := range ids {
+	for i, id := range ids {
 		wg.Add(1)
-		go func(id int64) {
+		go func(i int, id int64) {
 			defer wg.Done()
-			location, err := o.GetLocationByID(id)
-			if err != nil {
-				errChan <- err
-				return
-			}
-			locationChan <- *location
-		}(id)
-	}
-
-	go func() {
-		wg.Wait()
-		close(locationChan)
-		close(errChan)
-	}()
+			sem <- struct{}{}
+			defer func() { <-sem }()
 
-	var locations []domain.Location
-	for location := range locationChan {
-		locations = append(locations, location)
+			loaded[i], errs[i] = o.GetLocationByID(ctx, id)
+		}(i, id)
+	}
+	wg.Wait()
+
+	locations := make([]domain.Location, 0, len(ids))
+	failed := services.LocationErrors{}
+	for i, id := range ids {
+		if errs[i] != nil {
+			failed[id] = errs[i]
+			continue
+		}
+		locations = append(locations, *loaded[i])
 	}
 
-	for err := range errChan {
-		return nil, err
+	if len(failed) > 0 {
+		return locations, failed
 	}
 
 	return locations, nil
 }
 
 // GetLocationByID retrieves a location by its ID.
-func (o *OptiiSdk) GetLocationByID(id int64) (*domain.Location, error) {
+func (o *OptiiSdk) GetLocationByID(ctx context.Context, id int64) (*domain.Location, error) {
 	// Create a new GET request
 	endpoint := fmt.Sprintf("%s/api/%s/locations/%d", o.BaseUrl, o.ApiVersion, id)
-	request, err := retryablehttp.NewRequest(http.MethodGet, endpoint, nil)
+	ctx, cancel := o.withTimeout(ctx)
+	defer cancel()
+
+	request, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -176,7 +247,7 @@ func (o *OptiiSdk) GetLocationByID(id int64) (*domain.Location, error) {
 	// Send the request
 	response, err := o.Client.Do(request.Request)
 	if err != nil {
-		return nil, err
+		return nil, upstreamError(ctx, err)
 	}
 	defer response.Body.Close()
 
@@ -191,7 +262,7 @@ func (o *OptiiSdk) GetLocationByID(id int64) (*domain.Location, error) {
 }
 
 // CreateJob creates a new job using the Optii SDK.
-func (o *OptiiSdk) CreateJob(job *domain.Job) (interface{}, error) {
+func (o *OptiiSdk) CreateJob(ctx context.Context, job *domain.Job) (*domain.JobCreated, error) {
 	jsonBody, err := json.Marshal(job)
 	if err != nil {
 		return nil, fmt.Errorf("error marshalling job: %w", err)
@@ -200,7 +271,10 @@ func (o *OptiiSdk) CreateJob(job *domain.Job) (interface{}, error) {
 
 	// Create a new POST request
 	endpoint := fmt.Sprintf("%s/api/%s/jobs", o.BaseUrl, o.ApiVersion)
-	request, err := http.NewRequest(http.MethodPost, endpoint, requestBody)
+	ctx, cancel := o.withTimeout(ctx)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, requestBody)
 	if err != nil {
 		return nil, fmt.Errorf("error creating request: %w", err)
 	}
@@ -208,7 +282,7 @@ func (o *OptiiSdk) CreateJob(job *domain.Job) (interface{}, error) {
 	// Send the request
 	response, err := o.Client.Do(request)
 	if err != nil {
-		return nil, fmt.Errorf("client error making request: %w", err)
+		return nil, upstreamError(ctx, fmt.Errorf("client error making request: %w", err))
 	}
 	defer response.Body.Close()
 
@@ -225,31 +299,33 @@ func (o *OptiiSdk) CreateJob(job *domain.Job) (interface{}, error) {
 // GetFloorRooms retrieves the list of rooms on a specific floor.
 // It takes the floorID as input and returns a slice of domain.Location representing the rooms on the floor.
 // If an error occurs during the retrieval process, it returns nil and the error.
-func (o *OptiiSdk) GetFloorRooms(floorID int) ([]domain.Location, error) {
-	var locations []domain.Location
+func (o *OptiiSdk) GetFloorRooms(ctx context.Context, floorID int) ([]domain.Location, error) {
+	it := o.Locations(ctx, "Room")
+	defer func() {
+		o.observePages("GetFloorRooms", it.Pages())
+	}()
 
-	first := int32(0)
-	next := int32(100)
-	for {
-		locationsQuery, err := o.GetLocations(first, next, "Room")
-		if err != nil {
-			return nil, err
-		}
+	return childrenOf(it, floorID)
+}
 
-		for _, location := range locationsQuery.Locations {
-			if location.ParentLocation != nil && location.ParentLocation.ID == floorID {
-				locations = append(locations, location)
-			}
-		}
+// GetAllLocations retrieves every location of the property, going through all the pages of GetLocations.
+func (o *OptiiSdk) GetAllLocations(ctx context.Context) ([]domain.Location, error) {
+	it := o.Locations(ctx, "")
+	defer func() {
+		o.observePages("GetAllLocations", it.Pages())
+	}()
 
-		if !locationsQuery.PageInfo.HasNextPage {
-			break
-		}
+	return it.All()
+}
 
-		first = int32(locationsQuery.PageInfo.EndCursor)
+// GetLocationTree retrieves every location of the property and builds their tree.
+func (o *OptiiSdk) GetLocationTree(ctx context.Context) (*domain.LocationTree, error) {
+	locations, err := o.GetAllLocations(ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return locations, nil
+	return domain.NewLocationTree(locations), nil
 }
 
 // GetLocations retrieves a list of locations from the Optii SDK.
@@ -259,7 +335,7 @@ func (o *OptiiSdk) GetFloorRooms(floorID int) ([]domain.Location, error) {
 //   - locationType: the type of location to filter by (optional)
 //
 // The function returns a LocationsQuery object containing the retrieved locations, or an error if the request fails.
-func (o *OptiiSdk) GetLocations(first int32, next int32, locationType string) (*LocationsQuery, error) {
+func (o *OptiiSdk) GetLocations(ctx context.Context, first int32, next int32, locationType string) (*LocationsQuery, error) {
 	// Create the endpoint
 	endpoint := fmt.Sprintf("%s/api/%s/locations?first=%d&next=%d", o.BaseUrl, o.ApiVersion, first, next)
 	if locationType != "" {
@@ -267,7 +343,10 @@ func (o *OptiiSdk) GetLocations(first int32, next int32, locationType string) (*
 	}
 
 	// Create a new GET request
-	request, err := retryablehttp.NewRequest(http.MethodGet, endpoint, nil)
+	ctx, cancel := o.withTimeout(ctx)
+	defer cancel()
+
+	request, err := retryablehttp.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -275,7 +354,7 @@ func (o *OptiiSdk) GetLocations(first int32, next int32, locationType string) (*
 	// Send the request
 	response, err := o.Client.Do(request.Request)
 	if err != nil {
-		return nil, err
+		return nil, upstreamError(ctx, err)
 	}
 	defer response.Body.Close()
 
@@ -289,33 +368,46 @@ func (o *OptiiSdk) GetLocations(first int32, next int32, locationType string) (*
 	return locationsQuery, nil
 }
 
+// Ping checks Optii can be reached with the credentials, retrieving a single location.
+func (o *OptiiSdk) Ping(ctx context.Context) error {
+	_, err := o.GetLocations(ctx, 0, 1, "")
+	return err
+}
+
 // GetFloorLocations retrieves all locations that belong to a specific floor.
 // It takes a floorID as input and returns a slice of domain.Location and an error.
 // The function iterates through paginated results of GetLocations and filters the locations
 // that have a parent location matching the given floorID.
-func (o *OptiiSdk) GetFloorLocations(floorID int) ([]domain.Location, error) {
-	var locations []domain.Location
+func (o *OptiiSdk) GetFloorLocations(ctx context.Context, floorID int) ([]domain.Location, error) {
+	it := o.Locations(ctx, "")
+	defer func() {
+		o.observePages("GetFloorLocations", it.Pages())
+	}()
 
-	first := int32(0)
-	next := int32(100)
-	for {
-		locationsQuery, err := o.GetLocations(first, next, "")
-		if err != nil {
-			return nil, err
-		}
+	return childrenOf(it, floorID)
+}
 
-		for _, location := range locationsQuery.Locations {
-			if location.ParentLocation != nil && location.ParentLocation.ID == floorID {
-				locations = append(locations, location)
-			}
+// childrenOf returns the locations of the iterator whose parent is the floor.
+func childrenOf(it *Iterator[domain.Location], floorID int) ([]domain.Location, error) {
+	var locations []domain.Location
+	for it.Next() {
+		location := it.Item()
+		if location.ParentLocation != nil && location.ParentLocation.ID == floorID {
+			locations = append(locations, location)
 		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
 
-		if !locationsQuery.PageInfo.HasNextPage {
-			break
-		}
+	return locations, nil
+}
 
-		next = int32(locationsQuery.PageInfo.EndCursor)
+// withTimeout returns a context with the Timeout of the SDK, when set.
+func (o *OptiiSdk) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if o.Timeout <= 0 {
+		return context.WithCancel(ctx)
 	}
 
-	return locations, nil
+	return context.WithTimeout(ctx, o.Timeout)
 }
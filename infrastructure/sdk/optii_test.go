@@ -1,13 +1,17 @@
 package sdk
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/services"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -41,7 +45,7 @@ func TestOptiiSdk_GetDepartmentByID(t *testing.T) {
 		}
 
 		// Call the GetDepartmentByID method
-		result, err := optiiSdk.GetDepartmentByID(1)
+		result, err := optiiSdk.GetDepartmentByID(context.Background(), 1)
 		assert.NoError(t, err)
 
 		// Verify the result
@@ -72,7 +76,7 @@ func TestOptiiSdk_GetDepartmentByID(t *testing.T) {
 		}
 
 		// Call the GetDepartmentByID method
-		_, err := optiiSdk.GetDepartmentByID(99999)
+		_, err := optiiSdk.GetDepartmentByID(context.Background(), 99999)
 		assert.EqualError(t, err, "Not Found: 99999 not found")
 	})
 }
@@ -105,7 +109,7 @@ func TestOptiiSdk_GetJobItemByID(t *testing.T) {
 		}
 
 		// Call the GetJobItemByID method
-		result, err := optiiSdk.GetJobItemByID(1)
+		result, err := optiiSdk.GetJobItemByID(context.Background(), 1)
 		assert.NoError(t, err)
 
 		// Verify the result
@@ -136,7 +140,7 @@ func TestOptiiSdk_GetJobItemByID(t *testing.T) {
 		}
 
 		// Call the GetJobItemByID method
-		_, err := optiiSdk.GetJobItemByID(99999)
+		_, err := optiiSdk.GetJobItemByID(context.Background(), 99999)
 		assert.EqualError(t, err, "Not Found: 99999 not found")
 	})
 }
@@ -174,7 +178,7 @@ func TestOptiiSdk_GetLocationByID(t *testing.T) {
 		}
 
 		// Call the GetLocationByID method
-		result, err := optiiSdk.GetLocationByID(1)
+		result, err := optiiSdk.GetLocationByID(context.Background(), 1)
 		assert.NoError(t, err)
 
 		// Verify the result
@@ -205,11 +209,86 @@ func TestOptiiSdk_GetLocationByID(t *testing.T) {
 		}
 
 		// Call the GetLocationByID method
-		_, err := optiiSdk.GetLocationByID(99999)
+		_, err := optiiSdk.GetLocationByID(context.Background(), 99999)
 		assert.EqualError(t, err, "Not Found: 99999 not found")
 	})
 }
 
+func TestOptiiSdk_GetLocationsByIds(t *testing.T) {
+	// newServer returns the location of the ID in the path, or a 404 for the IDs above 100,
+	// tracking the maximum number of concurrent requests.
+	newServer := func(inFlight, maxInFlight *int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			current := atomic.AddInt32(inFlight, 1)
+			defer atomic.AddInt32(inFlight, -1)
+			for {
+				max := atomic.LoadInt32(maxInFlight)
+				if current <= max || atomic.CompareAndSwapInt32(maxInFlight, max, current) {
+					break
+				}
+			}
+			time.Sleep(5 * time.Millisecond)
+
+			var id int
+			fmt.Sscanf(r.URL.Path, "/api/v1/locations/%d", &id)
+			w.Header().Set("Content-Type", "application/json")
+			if id > 100 {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprintf(w, `{"title": "Not Found", "status": 404, "detail": "%d not found"}`, id)
+				return
+			}
+			json.NewEncoder(w).Encode(domain.Location{ID: id})
+		}))
+	}
+
+	t.Run("should return the locations in the order of the IDs", func(t *testing.T) {
+		inFlight, maxInFlight := int32(0), int32(0)
+		server := newServer(&inFlight, &maxInFlight)
+		defer server.Close()
+
+		optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient}
+
+		locations, err := optiiSdk.GetLocationsByIds(context.Background(), []int64{3, 1, 2})
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Location{{ID: 3}, {ID: 1}, {ID: 2}}, locations)
+	})
+
+	t.Run("should return the loaded locations and the error of each failed ID", func(t *testing.T) {
+		inFlight, maxInFlight := int32(0), int32(0)
+		server := newServer(&inFlight, &maxInFlight)
+		defer server.Close()
+
+		optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient}
+
+		locations, err := optiiSdk.GetLocationsByIds(context.Background(), []int64{2, 102, 1, 101})
+		assert.Equal(t, []domain.Location{{ID: 2}, {ID: 1}}, locations)
+		assert.EqualError(t, err, "101: Not Found: 101 not found; 102: Not Found: 102 not found")
+		assert.ErrorIs(t, err, services.ErrNotFound)
+
+		var failed services.LocationErrors
+		assert.ErrorAs(t, err, &failed)
+		assert.Equal(t, []int64{101, 102}, failed.IDs())
+		assert.True(t, IsNotFound(failed[101]))
+	})
+
+	t.Run("should bound the number of concurrent requests", func(t *testing.T) {
+		inFlight, maxInFlight := int32(0), int32(0)
+		server := newServer(&inFlight, &maxInFlight)
+		defer server.Close()
+
+		optiiSdk := &OptiiSdk{BaseUrl: server.URL, ApiVersion: "v1", Client: http.DefaultClient, Concurrency: 3}
+
+		ids := make([]int64, 30)
+		for i := range ids {
+			ids[i] = int64(i + 1)
+		}
+		locations, err := optiiSdk.GetLocationsByIds(context.Background(), ids)
+		assert.NoError(t, err)
+		assert.Len(t, locations, 30)
+		assert.LessOrEqual(t, atomic.LoadInt32(&maxInFlight), int32(3))
+	})
+}
+
 func TestOptiiSdk_CreateJob(t *testing.T) {
 	t.Run("should return the job item when the request is successful", func(t *testing.T) {
 		job := &domain.Job{
@@ -256,14 +335,14 @@ func TestOptiiSdk_CreateJob(t *testing.T) {
 		}
 
 		// Call the CreateJob method
-		result, err := optiiSdk.CreateJob(job)
+		result, err := optiiSdk.CreateJob(context.Background(), job)
 		fmt.Printf("%+v", result)
 		fmt.Printf("%+v", err)
 		assert.NoError(t, err)
 
 		// Verify the result
-		assert.Equal(t, 4393, result.(*domain.JobCreated).ID)
-		assert.Equal(t, "deliver", result.(*domain.JobCreated).Action)
+		assert.Equal(t, 4393, result.ID)
+		assert.Equal(t, "deliver", result.Action)
 	})
 
 	t.Run("should return an error when the request fails with a 400", func(t *testing.T) {
@@ -290,7 +369,7 @@ func TestOptiiSdk_CreateJob(t *testing.T) {
 		}
 
 		// Call the CreateJob method
-		_, err := optiiSdk.CreateJob(&domain.Job{})
+		_, err := optiiSdk.CreateJob(context.Background(), &domain.Job{})
 		assert.EqualError(t, err, "Bad Request: Invalid request")
 	})
 }
@@ -365,7 +444,7 @@ func TestOptiiSdk_GetFloorRooms(t *testing.T) {
 		}
 
 		// Call the GetFloorRooms method
-		result, err := optiiSdk.GetFloorRooms(1)
+		result, err := optiiSdk.GetFloorRooms(context.Background(), 1)
 		assert.NoError(t, err)
 
 		// Verify the result
@@ -422,7 +501,7 @@ func TestOptiiSdk_GetFloorRooms(t *testing.T) {
 		}
 
 		// Call the GetFloorRooms method
-		_, err := optiiSdk.GetFloorRooms(1)
+		_, err := optiiSdk.GetFloorRooms(context.Background(), 1)
 		assert.Error(t, err)
 		assert.Equal(t, "An error has occured.: The input was not a valid value.", err.Error())
 	})
@@ -499,7 +578,7 @@ func TestOptiiSdk_GetFloorLocations(t *testing.T) {
 		}
 
 		// Call the GetFloorLocations method
-		result, err := optiiSdk.GetFloorLocations(1)
+		result, err := optiiSdk.GetFloorLocations(context.Background(), 1)
 		assert.NoError(t, err)
 
 		// Verify the result
@@ -556,8 +635,123 @@ func TestOptiiSdk_GetFloorLocations(t *testing.T) {
 		}
 
 		// Call the GetFloorLocations method
-		_, err := optiiSdk.GetFloorLocations(1)
+		_, err := optiiSdk.GetFloorLocations(context.Background(), 1)
 		assert.Error(t, err)
 		assert.Equal(t, "An error has occured.: The input was not a valid value.", err.Error())
 	})
 }
+
+func TestOptiiSdk_Context(t *testing.T) {
+	t.Run("should abort the request when the context is cancelled", func(t *testing.T) {
+		// The server blocks until the client gives up on the request or the test ends
+		done := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+		}))
+		defer server.Close()
+		defer close(done)
+
+		optiiSdk := &OptiiSdk{
+			BaseUrl:    server.URL,
+			ApiVersion: "v1",
+			Client:     http.DefaultClient,
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		time.AfterFunc(50*time.Millisecond, cancel)
+
+		_, err := optiiSdk.GetDepartmentByID(ctx, 1)
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+
+	t.Run("should abort the request when the timeout expires", func(t *testing.T) {
+		done := make(chan struct{})
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-done:
+			}
+		}))
+		defer server.Close()
+		defer close(done)
+
+		optiiSdk := &OptiiSdk{
+			BaseUrl:    server.URL,
+			ApiVersion: "v1",
+			Timeout:    50 * time.Millisecond,
+			Client:     http.DefaultClient,
+		}
+
+		_, err := optiiSdk.CreateJob(context.Background(), &domain.Job{})
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestOptiiSdk_GetAllLocations(t *testing.T) {
+	t.Run("should return the locations of every page", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			locationsQuery := &LocationsQuery{}
+			switch r.URL.RequestURI() {
+			case "/api/v1/locations?first=0&next=100":
+				locationsQuery.Locations = []domain.Location{{ID: 1}, {ID: 2}}
+				locationsQuery.PageInfo = &PageInfo{HasNextPage: true, EndCursor: 2}
+			case "/api/v1/locations?first=2&next=100":
+				locationsQuery.Locations = []domain.Location{{ID: 3}}
+				locationsQuery.PageInfo = &PageInfo{HasNextPage: false, EndCursor: 3}
+			default:
+				t.Errorf("unexpected request %s", r.URL.RequestURI())
+			}
+
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(locationsQuery)
+		}))
+		defer server.Close()
+
+		optiiSdk := &OptiiSdk{
+			BaseUrl:    server.URL,
+			ApiVersion: "v1",
+			Client:     http.DefaultClient,
+		}
+
+		result, err := optiiSdk.GetAllLocations(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, []domain.Location{{ID: 1}, {ID: 2}, {ID: 3}}, result)
+	})
+}
+
+func TestOptiiSdk_Ping(t *testing.T) {
+	t.Run("should retrieve a single location", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/v1/locations?first=0&next=1", r.URL.RequestURI())
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(&LocationsQuery{Locations: []domain.Location{{ID: 1}}})
+		}))
+		defer server.Close()
+
+		optiiSdk := &OptiiSdk{
+			BaseUrl:    server.URL,
+			ApiVersion: "v1",
+			Client:     http.DefaultClient,
+		}
+
+		assert.NoError(t, optiiSdk.Ping(context.Background()))
+	})
+
+	t.Run("should return an error when Optii fails", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+
+		optiiSdk := &OptiiSdk{
+			BaseUrl:    server.URL,
+			ApiVersion: "v1",
+			Client:     http.DefaultClient,
+		}
+
+		assert.Error(t, optiiSdk.Ping(context.Background()))
+	})
+}
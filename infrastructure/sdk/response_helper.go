@@ -2,14 +2,19 @@ package sdk
 
 import (
 	"encoding/json"
-	"fmt"
 	"io"
 	"net/http"
 	"regexp"
 )
 
+// ParseResponse decodes the body of a 2xx response into data.
+// Any other status is returned as an *APIError, with the details of the error response when it has any.
 func ParseResponse(resp *http.Response, data interface{}) error {
-	if resp.StatusCode == http.StatusOK || resp.StatusCode < http.StatusCreated {
+	if resp.StatusCode >= http.StatusOK && resp.StatusCode < http.StatusMultipleChoices {
+		if resp.StatusCode == http.StatusNoContent {
+			return nil
+		}
+
 		err := json.NewDecoder(resp.Body).Decode(data)
 		if err != nil {
 			return err
@@ -18,6 +23,11 @@ func ParseResponse(resp *http.Response, data interface{}) error {
 		return nil
 	}
 
+	apiErr := &APIError{Status: resp.StatusCode}
+	if resp.Request != nil {
+		apiErr.Endpoint = endpointOf(resp.Request.URL)
+	}
+
 	/*
 		The JSON in the response body is not valid, due to the presence of
 		a trailing comma after the last element in the array
@@ -29,14 +39,15 @@ func ParseResponse(resp *http.Response, data interface{}) error {
 	re := regexp.MustCompile(`,\s*}`)
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return err
+		return apiErr
 	}
 	cleanedBody := re.ReplaceAll(body, []byte("}"))
 
+	// The body of some errors, e.g. from a proxy, is not an error response
 	errorResponse := &ErrorResponse{}
 	err = json.Unmarshal(cleanedBody, errorResponse)
 	if err != nil {
-		return err
+		return apiErr
 	}
 
 	/* Delete previous code after fix and use this instead
@@ -47,5 +58,9 @@ func ParseResponse(resp *http.Response, data interface{}) error {
 	}
 	*/
 
-	return fmt.Errorf("%s: %s", errorResponse.Title, errorResponse.Detail)
+	apiErr.Type = errorResponse.Type
+	apiErr.Title = errorResponse.Title
+	apiErr.Detail = errorResponse.Detail
+
+	return apiErr
 }
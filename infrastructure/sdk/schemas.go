@@ -9,11 +9,7 @@ type ErrorResponse struct {
 	Detail string `json:"detail"`
 }
 
-type PageInfo struct {
-	EndCursor   int  `json:"endCursor"`
-	HasNextPage bool `json:"hasNextPage"`
-	TotalCount  int  `json:"totalCount"`
-}
+type PageInfo = domain.PageInfo
 
 type LocationsQuery struct {
 	PageInfo  *PageInfo         `json:"pageInfo"`
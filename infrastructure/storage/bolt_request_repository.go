@@ -0,0 +1,116 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/domain/repository"
+	bolt "go.etcd.io/bbolt"
+)
+
+var requestsBucket = []byte("requests")
+
+// BoltRequestRepository stores the job requests in an embedded BoltDB file.
+type BoltRequestRepository struct {
+	DB *bolt.DB
+}
+
+// NewBoltRequestRepository opens, or creates, the database file at the given path.
+// The file is locked until Close is called.
+func NewBoltRequestRepository(path string) (*BoltRequestRepository, error) {
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: 5 * time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening requests database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(requestsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error creating requests bucket: %w", err)
+	}
+
+	return &BoltRequestRepository{DB: db}, nil
+}
+
+// Close closes the database file.
+func (r *BoltRequestRepository) Close() error {
+	return r.DB.Close()
+}
+
+// Save stores the record as JSON, replacing any record with the same ID.
+func (r *BoltRequestRepository) Save(ctx context.Context, record *repository.RequestRecord) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error marshalling request: %w", err)
+	}
+
+	return r.DB.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(requestsBucket).Put([]byte(record.ID), data)
+	})
+}
+
+// Get returns the record with the given ID, or repository.ErrRequestNotFound.
+func (r *BoltRequestRepository) Get(ctx context.Context, id string) (*repository.RequestRecord, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	record := &repository.RequestRecord{}
+	err := r.DB.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(requestsBucket).Get([]byte(id))
+		if data == nil {
+			return repository.ErrRequestNotFound
+		}
+		return json.Unmarshal(data, record)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// List scans every record and returns the ones matching the filter, the most recent first.
+func (r *BoltRequestRepository) List(ctx context.Context, filter repository.RequestFilter) ([]repository.RequestRecord, error) {
+	records := []repository.RequestRecord{}
+
+	err := r.DB.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(requestsBucket).ForEach(func(k, v []byte) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+
+			record := repository.RequestRecord{}
+			if err := json.Unmarshal(v, &record); err != nil {
+				return fmt.Errorf("error decoding request %s: %w", k, err)
+			}
+			if filter.Match(&record) {
+				records = append(records, record)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].CreatedAt.After(records[j].CreatedAt)
+	})
+
+	if filter.Limit > 0 && len(records) > filter.Limit {
+		records = records[:filter.Limit]
+	}
+
+	return records, nil
+}
@@ -0,0 +1,117 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/Twsouza/job-rule-engine/application/dto"
+	"github.com/Twsouza/job-rule-engine/domain"
+	"github.com/Twsouza/job-rule-engine/domain/repository"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoltRequestRepository(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "requests.db")
+	repo, err := NewBoltRequestRepository(path)
+	assert.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	created := domain.JobResult{
+		Rule:       "RepairJobItemLocation",
+		Outcome:    domain.OutcomeCreated,
+		Action:     "repair",
+		Created:    &domain.JobCreated{ID: 7, Action: "repair"},
+		StartedAt:  now.Add(-2 * time.Hour),
+		FinishedAt: now.Add(-2 * time.Hour),
+	}
+	failedToCreate := domain.JobResult{
+		Outcome: domain.OutcomeFailed,
+		Error:   &domain.JobError{Code: domain.ErrorUpstream, Message: "failed to create job", Retryable: true},
+	}
+	records := []*repository.RequestRecord{
+		{
+			ID:           "1",
+			Input:        &dto.JobRequestDto{DepartmentID: 1, JobItemID: 10, LocationsID: []int64{100}},
+			Request:      &domain.JobRequest{Department: &domain.Department{ID: 1, Name: "Engineering"}},
+			MatchedRules: []string{"RepairJobItemLocation"},
+			Results:      []domain.JobResult{created},
+			CreatedAt:    now.Add(-2 * time.Hour),
+			FinishedAt:   now.Add(-2 * time.Hour),
+		},
+		{
+			ID:         "2",
+			Input:      &dto.JobRequestDto{DepartmentID: 1, JobItemID: 20, LocationsID: []int64{100}},
+			Results:    []domain.JobResult{failedToCreate},
+			CreatedAt:  now.Add(-time.Hour),
+			FinishedAt: now.Add(-time.Hour),
+		},
+		{
+			ID:         "3",
+			Input:      &dto.JobRequestDto{DepartmentID: 2, JobItemID: 10, LocationsID: []int64{100}},
+			Errors:     []string{"department not found"},
+			CreatedAt:  now,
+			FinishedAt: now,
+		},
+	}
+	for _, record := range records {
+		assert.NoError(t, repo.Save(context.Background(), record))
+	}
+
+	t.Run("should get a record by ID", func(t *testing.T) {
+		record, err := repo.Get(context.Background(), "1")
+		assert.NoError(t, err)
+		assert.Equal(t, records[0].Input, record.Input)
+		assert.Equal(t, records[0].Request, record.Request)
+		assert.Equal(t, records[0].MatchedRules, record.MatchedRules)
+		assert.Equal(t, records[0].Results, record.Results)
+		assert.True(t, records[0].CreatedAt.Equal(record.CreatedAt))
+
+		_, err = repo.Get(context.Background(), "unknown")
+		assert.ErrorIs(t, err, repository.ErrRequestNotFound)
+	})
+
+	ids := func(records []repository.RequestRecord) []string {
+		ids := []string{}
+		for _, r := range records {
+			ids = append(ids, r.ID)
+		}
+		return ids
+	}
+
+	t.Run("should list the records matching the filter, the most recent first", func(t *testing.T) {
+		list, err := repo.List(context.Background(), repository.RequestFilter{})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"3", "2", "1"}, ids(list))
+
+		list, err = repo.List(context.Background(), repository.RequestFilter{DepartmentID: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"2", "1"}, ids(list))
+
+		list, err = repo.List(context.Background(), repository.RequestFilter{JobItemID: 10, Limit: 1})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"3"}, ids(list))
+
+		failed := true
+		list, err = repo.List(context.Background(), repository.RequestFilter{Failed: &failed})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"3", "2"}, ids(list))
+
+		list, err = repo.List(context.Background(), repository.RequestFilter{From: now.Add(-90 * time.Minute), To: now})
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"2"}, ids(list))
+	})
+
+	t.Run("should keep the records when the database is reopened", func(t *testing.T) {
+		assert.NoError(t, repo.Close())
+
+		repo, err = NewBoltRequestRepository(path)
+		assert.NoError(t, err)
+		defer repo.Close()
+
+		list, err := repo.List(context.Background(), repository.RequestFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, list, 3)
+	})
+}
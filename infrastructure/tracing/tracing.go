@@ -0,0 +1,75 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+)
+
+// ServiceName is the service name of the spans, unless OTEL_SERVICE_NAME is set.
+const ServiceName = "job-rule-engine"
+
+// Exporters of the spans.
+const (
+	// ExporterNone disables the tracing.
+	ExporterNone = "none"
+	// ExporterOTLP sends the spans to the OTLP HTTP endpoint set in OTEL_EXPORTER_OTLP_ENDPOINT,
+	// http://localhost:4318 by default.
+	ExporterOTLP = "otlp"
+	// ExporterStdout prints the spans, for local testing.
+	ExporterStdout = "stdout"
+)
+
+// Setup registers the global tracer provider sending the spans to the given exporter,
+// and the W3C trace context propagator so the calls to Optii carry the traceparent header.
+// The returned function flushes the pending spans, it must be called before the server exits.
+func Setup(ctx context.Context, exporter string) (func(ctx context.Context) error, error) {
+	return setup(ctx, exporter, os.Stdout)
+}
+
+func setup(ctx context.Context, exporter string, stdout io.Writer) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var spanExporter sdktrace.SpanExporter
+	var err error
+	switch exporter {
+	case "", ExporterNone:
+		return func(ctx context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		spanExporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(stdout), stdouttrace.WithPrettyPrint())
+	default:
+		return nil, fmt.Errorf("unknown traces exporter %q", exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating the %s traces exporter: %w", exporter, err)
+	}
+
+	// The attributes of the OTEL_RESOURCE_ATTRIBUTES and OTEL_SERVICE_NAME variables override the service name
+	res, err := resource.New(ctx,
+		resource.WithTelemetrySDK(),
+		resource.WithAttributes(semconv.ServiceName(ServiceName)),
+		resource.WithFromEnv(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the traces resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+	)
+	otel.SetTracerProvider(provider)
+
+	return provider.Shutdown, nil
+}
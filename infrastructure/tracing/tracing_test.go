@@ -0,0 +1,41 @@
+package tracing
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel"
+)
+
+func TestSetup(t *testing.T) {
+	previous := otel.GetTracerProvider()
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+
+	t.Run("should print the spans with the stdout exporter", func(t *testing.T) {
+		out := &bytes.Buffer{}
+		shutdown, err := setup(context.Background(), ExporterStdout, out)
+		assert.NoError(t, err)
+
+		_, span := otel.Tracer("test").Start(context.Background(), "CreateJob")
+		span.End()
+		assert.NoError(t, shutdown(context.Background()))
+
+		assert.Contains(t, out.String(), `"Name": "CreateJob"`)
+		assert.Contains(t, out.String(), ServiceName)
+	})
+
+	t.Run("should not register a tracer provider without exporter", func(t *testing.T) {
+		for _, exporter := range []string{"", ExporterNone} {
+			shutdown, err := setup(context.Background(), exporter, &bytes.Buffer{})
+			assert.NoError(t, err)
+			assert.NoError(t, shutdown(context.Background()))
+		}
+	})
+
+	t.Run("should fail with an unknown exporter", func(t *testing.T) {
+		_, err := setup(context.Background(), "jaeger", &bytes.Buffer{})
+		assert.EqualError(t, err, `unknown traces exporter "jaeger"`)
+	})
+}